@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 
+	"app/internal/bootstrap"
 	"app/internal/commands"
 	"app/internal/config"
 	"app/internal/schedule"
@@ -20,6 +21,11 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Initialize database connection, commands that need it report the error themselves
+	if err := bootstrap.SetupDatabase(cfg); err != nil {
+		log.Printf("[WARN] Database unavailable: %v", err)
+	}
+
 	// Initialize Redis client
 	redisClient := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
@@ -36,16 +42,32 @@ func main() {
 
 	// Register built-in commands
 	manager.Register(commands.NewMakeCommand())
+	manager.Register(commands.NewMakeCrudCommand())
+	manager.Register(commands.NewGenTableCommand())
 	manager.Register(commands.NewHelloWorldCommand())
 	manager.Register(commands.NewMigrateCommand())
 	manager.Register(commands.NewSeedCommand())
+	manager.Register(commands.NewQueueFailedCommand())
+	manager.Register(commands.NewQueueRetryCommand())
+	manager.Register(commands.NewQueueForgetCommand())
+	manager.Register(commands.NewQueueFlushCommand())
 
 	// Create scheduler
 	scheduler := schedule.NewScheduler(manager, redisLocker)
+	scheduler.SetEnvironment(cfg.App.Env)
 	kernel := schedule.NewKernel(scheduler)
 
-	// Register scheduler command
+	// Record task runs and load the tasks managed from the admin panel,
+	// the tasks defined in the kernel still run without a database
+	if err := bootstrap.SetupScheduleDatabase(cfg, scheduler); err != nil {
+		log.Printf("[WARN] Schedule run history and database tasks disabled: %v", err)
+	}
+
+	// Register scheduler commands
 	manager.Register(commands.NewScheduleRunCommand(kernel))
+	manager.Register(commands.NewScheduleWorkCommand(kernel))
+	manager.Register(commands.NewScheduleListCommand(kernel))
+	manager.Register(commands.NewScheduleTestCommand(kernel))
 
 	// Run command from arguments
 	if err := manager.RunFromArgs(); err != nil {
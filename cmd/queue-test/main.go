@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"app/internal/config"
@@ -15,28 +17,102 @@ import (
 	"gorm.io/gorm"
 )
 
+var driver string
+
+func init() {
+	flag.StringVar(&driver, "driver", "memory", "要测试的驱动: memory, sync, redis, database, all")
+}
+
 func main() {
-	// 加载配置
+	flag.Parse()
+
+	drivers := strings.Split(driver, ",")
+	if driver == "all" {
+		drivers = []string{"memory", "sync", "redis", "database"}
+	}
+
+	for _, name := range drivers {
+		switch name {
+		case "memory":
+			// 内存驱动无需外部依赖
+			fmt.Println("=== 测试 Memory 驱动 ===")
+			testMemoryDriver()
+
+		case "sync":
+			fmt.Println("\n=== 测试 Sync 驱动 ===")
+			testSyncDriver()
+
+		case "redis":
+			fmt.Println("\n=== 测试 Redis 驱动 ===")
+			testRedisDriver(loadConfig())
+
+		case "database":
+			cfg := loadConfig()
+
+			// 初始化数据库连接 (用于database驱动)
+			dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+				cfg.Database.Username, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Database)
+			db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+			if err != nil {
+				log.Fatalf("Failed to connect to database: %v", err)
+			}
+
+			fmt.Println("\n=== 测试 Database 驱动 ===")
+			testDatabaseDriver(cfg, db)
+
+		default:
+			log.Fatalf("Unsupported driver: %s", name)
+		}
+	}
+}
+
+// loadConfig 加载配置，仅 Redis 和数据库驱动需要
+func loadConfig() *config.Config {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	return cfg
+}
+
+func testMemoryDriver() {
+	manager, err := queue.NewManager(queue.Config{
+		Driver: "memory",
+		Options: map[string]interface{}{
+			"queue": "test-memory",
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to create Memory queue manager: %v", err)
+		return
+	}
+	defer manager.Close()
+
+	testQueue(manager, "Memory")
+}
 
-	// 初始化数据库连接 (用于database驱动)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.Database.Username, cfg.Database.Password, cfg.Database.Host, cfg.Database.Port, cfg.Database.Database)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+func testSyncDriver() {
+	manager, err := queue.NewManager(queue.Config{
+		Driver: "sync",
+		Options: map[string]interface{}{
+			"queue": "test-sync",
+		},
+	})
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Printf("Failed to create Sync queue manager: %v", err)
+		return
 	}
+	defer manager.Close()
 
-	// 测试Redis驱动
-	fmt.Println("=== 测试 Redis 驱动 ===")
-	testRedisDriver(cfg)
+	// 同步驱动在推送时立即执行任务
+	job := jobs.NewExampleJob("Hello from Sync queue!")
+	if err := manager.Push(context.Background(), job); err != nil {
+		log.Printf("Failed to run job: %v", err)
+		return
+	}
+	fmt.Printf("✓ 任务已同步执行\n")
 
-	// 测试Database驱动
-	fmt.Println("\n=== 测试 Database 驱动 ===")
-	testDatabaseDriver(cfg, db)
+	fmt.Printf("\nSync 驱动测试完成! ✅\n")
 }
 
 func testRedisDriver(cfg *config.Config) {
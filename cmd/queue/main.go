@@ -9,6 +9,8 @@ import (
 	"os/signal"
 	"syscall"
 
+	"app/internal/bootstrap"
+	"app/internal/config"
 	"app/internal/core/services"
 
 	"github.com/spf13/viper"
@@ -23,6 +25,7 @@ var (
 	stopQueue   bool
 	startQueue  bool
 	statusQueue bool
+	showConfig  bool
 )
 
 func init() {
@@ -34,6 +37,7 @@ func init() {
 	flag.BoolVar(&stopQueue, "stop", false, "停止队列")
 	flag.BoolVar(&startQueue, "start", false, "启动队列")
 	flag.BoolVar(&statusQueue, "status", false, "查询队列状态")
+	flag.BoolVar(&showConfig, "settings", false, "查看各队列生效的配置")
 }
 
 func main() {
@@ -45,6 +49,17 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// 数据库驱动使用共享的数据库连接
+	if driver := viper.GetString("queue.driver"); driver == "database" || driver == "mysql" {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		if err := bootstrap.SetupDatabase(cfg); err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+	}
+
 	// 创建队列服务
 	queueService, err := services.NewQueueService()
 	if err != nil {
@@ -144,6 +159,19 @@ func main() {
 			fmt.Printf("Active workers: %d\n", workerCount)
 		}
 
+	case showConfig:
+		// 查看各队列生效的配置
+		settings, err := queueService.Settings()
+		if err != nil {
+			log.Fatalf("Failed to load queue settings: %v", err)
+		}
+
+		fmt.Printf("%-12s %-9s %-10s %-8s %-6s %-12s %s\n", "Queue", "Priority", "Processes", "Timeout", "Tries", "RetryAfter", "Backoff")
+		for _, q := range settings {
+			fmt.Printf("%-12s %-9d %-10d %-8s %-6d %-12s %v\n",
+				q.Name, q.Priority, q.Processes, fmt.Sprintf("%ds", q.Timeout), q.Tries, fmt.Sprintf("%ds", q.RetryAfter), q.Backoff)
+		}
+
 	default:
 		// 显示帮助信息
 		fmt.Println("Usage:")
@@ -61,8 +61,15 @@ func main() {
 
 	// Create and start scheduler with Redis locker
 	scheduler := schedule.NewScheduler(manager, redisLocker)
+	scheduler.SetEnvironment(cfg.App.Env)
 	kernel := schedule.NewKernel(scheduler)
 
+	// Record task runs and load the tasks managed from the admin panel,
+	// the tasks defined in the kernel still run without a database
+	if err := bootstrap.SetupScheduleDatabase(cfg, scheduler); err != nil {
+		log.Printf("[WARN] Schedule run history and database tasks disabled: %v", err)
+	}
+
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -75,7 +82,7 @@ func main() {
 	}()
 
 	// Initialize the HTTP server
-	app, err := setup.InitializeApp()
+	app, err := setup.InitializeApp(manager)
 	if err != nil {
 		log.Fatalf("Failed to initialize app: %v", err)
 	}
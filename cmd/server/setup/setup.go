@@ -5,6 +5,7 @@ import (
 	"app/internal/bootstrap"
 	"app/internal/config"
 	"app/internal/routes"
+	"app/pkg/console"
 	"app/pkg/i18n"
 	"app/pkg/logger"
 
@@ -21,7 +22,9 @@ func (a *App) Engine() *gin.Engine {
 	return a.engine
 }
 
-func InitializeApp() (*App, error) {
+// InitializeApp builds the HTTP application, commands are the console commands
+// the scheduled tasks can run
+func InitializeApp(commands *console.Manager) (*App, error) {
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -75,7 +78,7 @@ func InitializeApp() (*App, error) {
 	engine.Use(middleware.CORS(&cfg.CORS))
 
 	// Setup routes
-	routes.SetupRoutes(engine, cfg)
+	routes.SetupRoutes(engine, cfg, commands)
 
 	return &App{
 		engine: engine,
@@ -43,26 +43,13 @@ func (c *MakeMigrationCommand) Configure(config *console.CommandConfig) {
 			Description: "Modify an existing table",
 		},
 	}
+	c.BaseCommand.Configure(config)
 }
 
 func (c *MakeMigrationCommand) Handle(ctx context.Context) error {
-	args := ctx.Value("args").([]string)
-	if len(args) < 2 {
-		return fmt.Errorf("migration name required")
-	}
-
-	name := args[1]
-
-	// Parse options
-	var createTable, modifyTable string
-	for i := 2; i < len(args); i++ {
-		arg := args[i]
-		if strings.HasPrefix(arg, "--create=") {
-			createTable = strings.TrimPrefix(arg, "--create=")
-		} else if strings.HasPrefix(arg, "--table=") {
-			modifyTable = strings.TrimPrefix(arg, "--table=")
-		}
-	}
+	name := c.GetArgument("name")
+	createTable := c.GetOption("create")
+	modifyTable := c.GetOption("table")
 
 	// Generate timestamp in Laravel format: 2024_03_15_123456
 	timestamp := time.Now().Format("2006_01_02_150405")
@@ -3,20 +3,32 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"app/internal/database/migrations"
 	"app/pkg/console"
 	"app/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// The migration commands reach the database and the registered migrations
+// through these, the tests swap them for an in-memory database
+var (
+	migrationDB = database.GetDB
+	newMigrator = migrations.InitMigrations
 )
 
 type MigrateCommand struct {
 	*console.BaseCommand
+	env string
 }
 
-func NewMigrateCommand() *MigrateCommand {
+func NewMigrateCommand(env string) *MigrateCommand {
 	cmd := &MigrateCommand{
 		BaseCommand: console.NewCommand("migrate", "Database migration commands"),
+		env:         env,
 	}
 	return cmd
 }
@@ -24,7 +36,7 @@ func NewMigrateCommand() *MigrateCommand {
 func (c *MigrateCommand) Configure(config *console.CommandConfig) {
 	config.Name = "migrate"
 	config.Description = "Database migration commands"
-	config.Usage = "migrate [action]"
+	config.Usage = "migrate [action] [--force]"
 	config.Arguments = []console.Argument{
 		{
 			Name:        "action",
@@ -32,61 +44,66 @@ func (c *MigrateCommand) Configure(config *console.CommandConfig) {
 			Required:    true,
 		},
 	}
+	config.Options = []console.Option{forceOption}
+	c.BaseCommand.Configure(config)
 }
 
 func (c *MigrateCommand) Handle(ctx context.Context) error {
-	args := ctx.Value("args").([]string)
-	if len(args) < 2 {
-		return fmt.Errorf("action required: run, rollback, reset, refresh, or status")
-	}
-
-	db := database.GetDB()
-	migrator := migrations.InitMigrations(db)
-
-	action := args[1]
+	action := c.GetArgument("action")
 	switch action {
 	case "run":
-		return migrator.RunPending()
-	case "rollback":
-		return migrator.Rollback()
-	case "reset":
-		return migrator.Reset()
-	case "refresh":
-		return migrator.Refresh()
+		return newMigrator(migrationDB()).RunPending()
+	case "rollback", "reset", "refresh":
+		// Guarded in production like migrate:rollback and migrate:reset
+		if err := confirmProduction(c.env, c.HasOption("force")); err != nil {
+			return err
+		}
+		return migrations.Transaction(migrationDB(), func(tx *gorm.DB) error {
+			migrator := newMigrator(tx)
+			switch action {
+			case "rollback":
+				return migrator.Rollback()
+			case "reset":
+				return migrator.Reset()
+			default:
+				return migrator.Refresh()
+			}
+		})
 	case "status":
-		status, err := migrator.Status()
+		status, err := newMigrator(migrationDB()).Status()
 		if err != nil {
 			return err
 		}
-		printMigrationStatus(status)
+		printMigrationStatus(c.Output(), status)
 		return nil
 	default:
 		return fmt.Errorf("unknown action: %s", action)
 	}
 }
 
-func printMigrationStatus(status []map[string]interface{}) {
+func printMigrationStatus(w io.Writer, status []map[string]interface{}) {
 	if len(status) == 0 {
-		fmt.Printf("ℹ️  \033[33mNo migrations found\033[0m\n")
+		fmt.Fprintf(w, "ℹ️  \033[33mNo migrations found\033[0m\n")
 		return
 	}
 
 	executed := 0
 	pending := 0
 	for _, s := range status {
-		if s["status"].(string) == "Executed" {
+		switch s["status"].(string) {
+		case "Executed", "Missing":
 			executed++
-		} else {
+		default:
 			pending++
 		}
 	}
 
-	fmt.Printf("\n📊 \033[34mMigration Status Overview\033[0m\n")
-	fmt.Println("════════════════════════════════════════════════════════")
-	fmt.Printf("✅ Executed: \033[32m%d\033[0m  ⏳ Pending: \033[33m%d\033[0m  📝 Total: %d\n", executed, pending, len(status))
-	fmt.Println("════════════════════════════════════════════════════════")
-	fmt.Printf("%-40s %-12s %-8s %-20s\n", "Migration", "Status", "Batch", "Executed At")
-	fmt.Println("────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "\n📊 \033[34mMigration Status Overview\033[0m\n")
+	fmt.Fprintln(w, "════════════════════════════════════════════════════════")
+	fmt.Fprintf(w, "✅ Executed: \033[32m%d\033[0m  ⏳ Pending: \033[33m%d\033[0m  📝 Total: %d\n", executed, pending, len(status))
+	fmt.Fprintln(w, "════════════════════════════════════════════════════════")
+	fmt.Fprintf(w, "%-40s %-12s %-8s %-20s\n", "Migration", "Status", "Batch", "Executed At")
+	fmt.Fprintln(w, "────────────────────────────────────────────────────────")
 
 	for _, s := range status {
 		name := s["name"].(string)
@@ -106,9 +123,12 @@ func printMigrationStatus(status []map[string]interface{}) {
 
 		// Color code the status
 		statusColor := ""
-		if status == "Executed" {
+		switch status {
+		case "Executed":
 			statusColor = "\033[32m✓ " + status + "\033[0m"
-		} else {
+		case "Missing":
+			statusColor = "\033[31m✗ " + status + "\033[0m"
+		default:
 			statusColor = "\033[33m⏳ " + status + "\033[0m"
 		}
 
@@ -118,7 +138,22 @@ func printMigrationStatus(status []map[string]interface{}) {
 			displayName = name[:37] + "..."
 		}
 
-		fmt.Printf("%-40s %-20s %-8s %-20s\n", displayName, statusColor, batch, executedAt)
+		fmt.Fprintf(w, "%-40s %-20s %-8s %-20s\n", displayName, statusColor, batch, executedAt)
+	}
+	fmt.Fprintln(w, "════════════════════════════════════════════════════════")
+}
+
+// forceOption lets the destructive migration commands run in production
+var forceOption = console.Option{
+	Name:        "force",
+	Description: "Run the command in production",
+	Flag:        true,
+}
+
+// confirmProduction refuses to change the schema of a production database unless forced
+func confirmProduction(env string, force bool) error {
+	if env == "production" && !force {
+		return fmt.Errorf("the application is in production, use --force to run this command")
 	}
-	fmt.Println("════════════════════════════════════════════════════════")
+	return nil
 }
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"app/internal/database/migrations"
+	"app/internal/database/seeder"
+	"app/internal/database/seeders"
+	"app/pkg/console"
+
+	"gorm.io/gorm"
+)
+
+type MigrateFreshCommand struct {
+	*console.BaseCommand
+	env string
+}
+
+func NewMigrateFreshCommand(env string) *MigrateFreshCommand {
+	cmd := &MigrateFreshCommand{
+		BaseCommand: console.NewCommand("migrate:fresh", "Drop all tables and re-run all migrations"),
+		env:         env,
+	}
+	return cmd
+}
+
+func (c *MigrateFreshCommand) Configure(config *console.CommandConfig) {
+	config.Name = "migrate:fresh"
+	config.Description = "Drop all tables and re-run all migrations"
+	config.Usage = "migrate:fresh [--seed] [--force]"
+	config.Options = []console.Option{
+		{
+			Name:        "seed",
+			Description: "Run all seeders after the migrations",
+			Flag:        true,
+		},
+		forceOption,
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *MigrateFreshCommand) Handle(ctx context.Context) error {
+	if err := confirmProduction(c.env, c.HasOption("force")); err != nil {
+		return err
+	}
+
+	return migrations.Transaction(migrationDB(), func(tx *gorm.DB) error {
+		if err := newMigrator(tx).Fresh(); err != nil {
+			return err
+		}
+		if !c.HasOption("seed") {
+			return nil
+		}
+
+		fmt.Println()
+		seederManager := seeder.NewSeederManager(tx)
+		seeders.SetGlobalManager(seederManager)
+		return seederManager.Run()
+	})
+}
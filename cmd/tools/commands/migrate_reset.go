@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"context"
+
+	"app/internal/database/migrations"
+	"app/pkg/console"
+
+	"gorm.io/gorm"
+)
+
+type MigrateResetCommand struct {
+	*console.BaseCommand
+	env string
+}
+
+func NewMigrateResetCommand(env string) *MigrateResetCommand {
+	cmd := &MigrateResetCommand{
+		BaseCommand: console.NewCommand("migrate:reset", "Rollback all database migrations"),
+		env:         env,
+	}
+	return cmd
+}
+
+func (c *MigrateResetCommand) Configure(config *console.CommandConfig) {
+	config.Name = "migrate:reset"
+	config.Description = "Rollback all database migrations"
+	config.Usage = "migrate:reset [--force]"
+	config.Options = []console.Option{forceOption}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *MigrateResetCommand) Handle(ctx context.Context) error {
+	if err := confirmProduction(c.env, c.HasOption("force")); err != nil {
+		return err
+	}
+
+	return migrations.Transaction(migrationDB(), func(tx *gorm.DB) error {
+		return newMigrator(tx).Reset()
+	})
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"app/internal/database/migrations"
+	"app/pkg/console"
+
+	"gorm.io/gorm"
+)
+
+type MigrateRollbackCommand struct {
+	*console.BaseCommand
+	env string
+}
+
+func NewMigrateRollbackCommand(env string) *MigrateRollbackCommand {
+	cmd := &MigrateRollbackCommand{
+		BaseCommand: console.NewCommand("migrate:rollback", "Rollback the last database migrations"),
+		env:         env,
+	}
+	return cmd
+}
+
+func (c *MigrateRollbackCommand) Configure(config *console.CommandConfig) {
+	config.Name = "migrate:rollback"
+	config.Description = "Rollback the last batch of migrations, the last N migrations or a given batch"
+	config.Usage = "migrate:rollback [--step=N] [--batch=N] [--force]"
+	config.Options = []console.Option{
+		{
+			Name:        "step",
+			Description: "The number of migrations to rollback, across batches",
+		},
+		{
+			Name:        "batch",
+			Description: "The batch of migrations to rollback",
+		},
+		forceOption,
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *MigrateRollbackCommand) Handle(ctx context.Context) error {
+	if err := confirmProduction(c.env, c.HasOption("force")); err != nil {
+		return err
+	}
+
+	step, batch := c.GetOption("step"), c.GetOption("batch")
+	if step != "" && batch != "" {
+		return fmt.Errorf("use either --step or --batch")
+	}
+
+	return migrations.Transaction(migrationDB(), func(tx *gorm.DB) error {
+		migrator := newMigrator(tx)
+
+		switch {
+		case step != "":
+			n, err := strconv.Atoi(step)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid --step %q, expected a positive number", step)
+			}
+			return migrator.RollbackSteps(n)
+		case batch != "":
+			n, err := strconv.Atoi(batch)
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid --batch %q, expected a positive number", batch)
+			}
+			return migrator.RollbackBatch(n)
+		default:
+			return migrator.Rollback()
+		}
+	})
+}
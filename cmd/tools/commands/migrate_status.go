@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"context"
+
+	"app/pkg/console"
+)
+
+type MigrateStatusCommand struct {
+	*console.BaseCommand
+}
+
+func NewMigrateStatusCommand() *MigrateStatusCommand {
+	cmd := &MigrateStatusCommand{
+		BaseCommand: console.NewCommand("migrate:status", "Show the status of each migration"),
+	}
+	return cmd
+}
+
+func (c *MigrateStatusCommand) Configure(config *console.CommandConfig) {
+	config.Name = "migrate:status"
+	config.Description = "Show the status of each migration"
+	config.Usage = "migrate:status"
+	c.BaseCommand.Configure(config)
+}
+
+func (c *MigrateStatusCommand) Handle(ctx context.Context) error {
+	migrator := newMigrator(migrationDB())
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	printMigrationStatus(c.Output(), status)
+	return nil
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"app/internal/database/migrations"
+	"app/pkg/console"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// tableMigration creates a table of the migration's name
+func tableMigration(name string) migrations.Migration {
+	return migrations.NewMigration(name,
+		func(db *gorm.DB) error {
+			return db.Exec(fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY)", name)).Error
+		},
+		func(db *gorm.DB) error {
+			return db.Exec(fmt.Sprintf("DROP TABLE %s", name)).Error
+		},
+	)
+}
+
+// useTestMigrations points the migration commands at an in-memory database,
+// the returned function registers table migrations in order
+func useTestMigrations(t *testing.T) (*gorm.DB, func(names ...string)) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Every connection to :memory: opens a database of its own
+	sqlDB.SetMaxOpenConns(1)
+
+	var registered []string
+	originalDB, originalMigrator := migrationDB, newMigrator
+	migrationDB = func() *gorm.DB { return db }
+	newMigrator = func(db *gorm.DB) *migrations.Migrator {
+		migrator := migrations.NewMigrator(db)
+		for _, name := range registered {
+			migrator.Register(name, tableMigration(name))
+		}
+		return migrator
+	}
+	t.Cleanup(func() {
+		migrationDB, newMigrator = originalDB, originalMigrator
+		sqlDB.Close()
+	})
+
+	return db, func(names ...string) {
+		registered = append(registered, names...)
+	}
+}
+
+// newTestManager registers the migration commands for the given environment
+func newTestManager(env string) *console.Manager {
+	manager := console.NewManager()
+	manager.Register(NewMigrateCommand(env))
+	manager.Register(NewMigrateStatusCommand())
+	manager.Register(NewMigrateRollbackCommand(env))
+	manager.Register(NewMigrateResetCommand(env))
+	manager.Register(NewMigrateFreshCommand(env))
+	return manager
+}
+
+func TestMigrateStatusOutput(t *testing.T) {
+	_, register := useTestMigrations(t)
+	manager := newTestManager("local")
+	ctx := context.Background()
+
+	register("users", "roles")
+	require.NoError(t, manager.Call(ctx, "migrate", "run"))
+	register("todos")
+
+	status := NewMigrateStatusCommand()
+	var out bytes.Buffer
+	status.SetOutput(&out)
+	manager.Register(status)
+	require.NoError(t, manager.Call(ctx, "migrate:status"))
+
+	output := out.String()
+	assert.Contains(t, output, "Executed: \033[32m2\033[0m")
+	assert.Contains(t, output, "Pending: \033[33m1\033[0m")
+	assert.Regexp(t, `users\s+\S*✓ Executed\S*\s+1\s`, output)
+	assert.Regexp(t, `roles\s+\S*✓ Executed\S*\s+1\s`, output)
+	assert.Regexp(t, `todos\s+\S*⏳ Pending\S*\s+─`, output)
+}
+
+func TestMigrateRollbackBatch(t *testing.T) {
+	db, register := useTestMigrations(t)
+	manager := newTestManager("local")
+	ctx := context.Background()
+
+	register("users", "roles")
+	require.NoError(t, manager.Call(ctx, "migrate", "run"))
+	register("todos")
+	require.NoError(t, manager.Call(ctx, "migrate", "run"))
+
+	// Only the migrations of the first batch are rolled back
+	require.NoError(t, manager.Call(ctx, "migrate:rollback", "--batch=1"))
+	assert.False(t, db.Migrator().HasTable("users"))
+	assert.False(t, db.Migrator().HasTable("roles"))
+	assert.True(t, db.Migrator().HasTable("todos"))
+
+	var records []migrations.MigrationRecord
+	require.NoError(t, db.Find(&records).Error)
+	require.Len(t, records, 1)
+	assert.Equal(t, "todos", records[0].Name)
+	assert.Equal(t, 2, records[0].Batch)
+
+	assert.Error(t, manager.Call(ctx, "migrate:rollback", "--batch=1", "--step=1"))
+	assert.Error(t, manager.Call(ctx, "migrate:rollback", "--batch=0"))
+}
+
+func TestDestructiveMigrationsRefuseProduction(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+	}{
+		{command: "migrate", args: []string{"rollback"}},
+		{command: "migrate", args: []string{"reset"}},
+		{command: "migrate", args: []string{"refresh"}},
+		{command: "migrate:rollback"},
+		{command: "migrate:reset"},
+		{command: "migrate:fresh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.command, tt.args), func(t *testing.T) {
+			db, register := useTestMigrations(t)
+			manager := newTestManager("production")
+			ctx := context.Background()
+
+			register("users")
+			require.NoError(t, manager.Call(ctx, "migrate", "run"))
+			require.NoError(t, db.Exec("INSERT INTO users (id) VALUES (1)").Error)
+
+			// Without --force the schema and its data are left alone
+			err := manager.Call(ctx, tt.command, tt.args...)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "--force")
+			var count int64
+			require.NoError(t, db.Table("users").Count(&count).Error)
+			assert.Equal(t, int64(1), count)
+
+			require.NoError(t, manager.Call(ctx, tt.command, append(tt.args, "--force")...))
+		})
+	}
+}
@@ -35,26 +35,23 @@ func (c *SeedCommand) Configure(config *console.CommandConfig) {
 			Name:        "seeder",
 			Description: "Optional seeder name(s) to run",
 			Required:    false,
+			Array:       true,
 		},
 	}
+	c.BaseCommand.Configure(config)
 }
 
 func (c *SeedCommand) Handle(ctx context.Context) error {
-	args := ctx.Value("args").([]string)
-	if len(args) < 2 {
-		return fmt.Errorf("action required: run, reset, or status")
-	}
-
 	db := database.GetDB()
 	seederManager := seeder.NewSeederManager(db)
 	seeders.SetGlobalManager(seederManager)
 
-	action := args[1]
+	action := c.GetArgument("action")
 	switch action {
 	case "run":
 		// If specific seeders are provided, run only those
-		if len(args) > 2 {
-			return seederManager.Run(args[2:]...)
+		if names := c.GetArguments("seeder"); len(names) > 0 {
+			return seederManager.Run(names...)
 		}
 		// Otherwise run all seeders
 		return seederManager.Run()
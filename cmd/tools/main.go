@@ -25,7 +25,11 @@ func main() {
 	manager := console.NewManager()
 
 	// Register database commands
-	manager.Register(commands.NewMigrateCommand())
+	manager.Register(commands.NewMigrateCommand(cfg.App.Env))
+	manager.Register(commands.NewMigrateStatusCommand())
+	manager.Register(commands.NewMigrateRollbackCommand(cfg.App.Env))
+	manager.Register(commands.NewMigrateResetCommand(cfg.App.Env))
+	manager.Register(commands.NewMigrateFreshCommand(cfg.App.Env))
 	manager.Register(commands.NewSeedCommand())
 	manager.Register(commands.NewMakeMigrationCommand())
 
@@ -22,6 +22,11 @@ func NewSSEHandler() *SSEHandler {
 	return &SSEHandler{manager: manager}
 }
 
+// Manager returns the SSE manager so services can push events to connected clients
+func (h *SSEHandler) Manager() *sse.Manager {
+	return h.manager
+}
+
 // HandleSSE handles SSE connections
 func (h *SSEHandler) HandleSSE(c *gin.Context) {
 	userID := c.Query("user_id")
@@ -45,6 +45,20 @@ func JWT() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens revoked by a logout or a change to the account
+		revoked, err := authSvc.IsTokenRevoked(c.Request.Context(), claims)
+		if err != nil {
+			log.Printf("[ERROR] Failed to check token revocation: %v", err)
+			response.UnauthorizedError(c)
+			c.Abort()
+			return
+		}
+		if revoked {
+			response.UnauthorizedError(c)
+			c.Abort()
+			return
+		}
+
 		// Get user from claims
 		user, err := authSvc.GetUserFromClaims(c.Request.Context(), claims)
 		if err != nil {
@@ -67,6 +81,12 @@ func JWT() gin.HandlerFunc {
 		log.Printf("[DEBUG] Set IsSuperAdmin field for user %d: %v", user.ID, user.IsSuperAdmin)
 
 		c.Set("user", user)
+		c.Set("claims", claims)
+		// The session the refresh token of this access token belongs to
+		if sessionID, ok := claims["sid"].(string); ok {
+			c.Set("sessionID", sessionID)
+			authSvc.TouchSession(c.Request.Context(), sessionID)
+		}
 		c.Next()
 	}
 }
@@ -1,24 +1,51 @@
 package middleware
 
 import (
+	"log"
+
 	"app/internal/config"
 	"app/internal/core/repositories"
 	"app/internal/core/services"
+	"app/internal/core/sse"
+	"app/pkg/console"
 	"app/pkg/database"
+	"app/pkg/denylist"
+	"app/pkg/locker"
+	"app/pkg/redis"
 
 	"github.com/gin-gonic/gin"
 )
 
-// ServiceInjection injects all required services into the gin context
-func ServiceInjection(cfg *config.Config) gin.HandlerFunc {
+// ServiceInjection injects all required services into the gin context, commands
+// are the console commands the scheduled tasks can run
+func ServiceInjection(cfg *config.Config, events *sse.Manager, commands *console.Manager) gin.HandlerFunc {
+	// The queue service holds its own connection, so it is shared across requests
+	queueSvc, err := services.NewQueueService()
+	if err != nil {
+		log.Printf("[WARN] Queue service unavailable: %v", err)
+	} else if events != nil {
+		// Push queue stats to admins subscribed to the queue monitor group
+		queueSvc.PublishTo(events)
+	}
+
+	// Revoked tokens are shared through Redis, the revocations made by this
+	// process keep being enforced while Redis is unreachable
+	tokenDenylist := denylist.NewFallbackDenylist(denylist.NewRedisDenylist(redis.GetClient()), denylist.NewMemoryDenylist())
+	// Throttles the last activity writes of sessions across instances
+	sessionLocker := locker.NewRedisLocker(redis.GetClient())
+
 	return func(c *gin.Context) {
 		db := database.GetDB()
 
 		// Initialize repositories
 		userRepo := repositories.NewUserRepository(db)
+		sessionRepo := repositories.NewUserSessionRepository(db)
+		twoFactorRepo := repositories.NewTwoFactorRepository(db)
 		logRepo := repositories.NewLogRepository(db)
 		todoRepo := repositories.NewTodoRepository(db)
 		menuRepo := repositories.NewMenuRepository(db)
+		scheduleRunRepo := repositories.NewScheduleRunRepository(db)
+		scheduledTaskRepo := repositories.NewScheduledTaskRepository(db)
 
 		// Set config in userRepo
 		userRepo.SetConfig(cfg)
@@ -26,13 +53,21 @@ func ServiceInjection(cfg *config.Config) gin.HandlerFunc {
 		// Initialize services
 		logSvc := services.NewLogService(logRepo)
 		userSvc := services.NewUserService(userRepo, logSvc, cfg)
-		authSvc := services.NewAuthService(userRepo, logSvc, cfg)
+		authSvc := services.NewAuthService(userRepo, sessionRepo, tokenDenylist, logSvc, cfg)
+		twoFactorSvc := services.NewTwoFactorService(twoFactorRepo, cfg)
+		loginLockSvc := services.NewLoginLockService(logRepo, cfg)
 		rbacSvc := services.NewRBACService(db)
 		roleSvc := services.NewRoleService(db)
 		todoService := services.NewTodoService(todoRepo)
 		menuSvc := services.NewMenuService(menuRepo, userRepo)
+		scheduleRunSvc := services.NewScheduleRunService(scheduleRunRepo)
+		scheduledTaskSvc := services.NewScheduledTaskService(scheduledTaskRepo)
 
 		// Set up service dependencies
+		authSvc.SetSessionLocker(sessionLocker)
+		scheduledTaskSvc.SetCommands(commands)
+		authSvc.SetTwoFactorService(twoFactorSvc)
+		authSvc.SetLoginLockService(loginLockSvc)
 		userSvc.SetAuthService(authSvc)
 		rbacSvc.SetAuthService(authSvc)
 
@@ -40,10 +75,18 @@ func ServiceInjection(cfg *config.Config) gin.HandlerFunc {
 		c.Set("logService", logSvc)
 		c.Set("userService", userSvc)
 		c.Set("authService", authSvc)
+		c.Set("twoFactorService", twoFactorSvc)
+		c.Set("loginLockService", loginLockSvc)
 		c.Set("rbacService", rbacSvc)
 		c.Set("roleService", roleSvc)
 		c.Set("todoService", todoService)
 		c.Set("menuService", menuSvc)
+		c.Set("scheduleRunService", scheduleRunSvc)
+		c.Set("scheduledTaskService", scheduledTaskSvc)
+		// make:crud:services
+		if queueSvc != nil {
+			c.Set("queueService", queueSvc)
+		}
 
 		c.Next()
 	}
@@ -1,12 +1,17 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+
 	"app/internal/core/models"
 	"app/internal/core/services"
 	"app/pkg/captcha"
 	"app/pkg/response"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
 )
 
 // GetCaptcha generates and returns a captcha image
@@ -37,9 +42,17 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
 	authSvc := c.MustGet("authService").(*services.AuthService)
 	resp, err := authSvc.Login(c.Request.Context(), &req)
 	if err != nil {
+		var locked *services.LoginLockedError
+		if errors.As(err, &locked) {
+			loginLockedError(c, locked)
+			return
+		}
 		if err == services.ErrInvalidCredentials {
 			response.Error(c, response.CodeInvalidCredentials, "invalid credentials")
 			return
@@ -55,28 +68,104 @@ func Login(c *gin.Context) {
 	response.Success(c, resp)
 }
 
-// RefreshToken handles token refresh requests
+// loginLockedError tells the client how long the username or IP stays locked
+func loginLockedError(c *gin.Context, locked *services.LoginLockedError) {
+	retryAfter := locked.RetryAfter()
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	response.Error(c, response.CodeLoginLocked, fmt.Sprintf("too many failed login attempts, try again in %d seconds", retryAfter))
+}
+
+// LoginTwoFactor completes a login with a TOTP or recovery code
+func LoginTwoFactor(c *gin.Context) {
+	var req services.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
+	authSvc := c.MustGet("authService").(*services.AuthService)
+	resp, err := authSvc.LoginTwoFactor(c.Request.Context(), &req)
+	if err != nil {
+		var locked *services.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			loginLockedError(c, locked)
+		case errors.Is(err, services.ErrInvalidMFAToken):
+			response.Unauthorized(c, "invalid or expired mfa token")
+		case errors.Is(err, services.ErrInvalidTwoFactorCode):
+			response.Error(c, response.CodeInvalidTwoFactorCode, "invalid two-factor code")
+		case errors.Is(err, services.ErrUserInactive):
+			response.Error(c, response.CodeForbidden, "user is inactive")
+		case errors.Is(err, services.ErrTwoFactorNotEnrolled), errors.Is(err, services.ErrTwoFactorNotEnabled),
+			errors.Is(err, services.ErrTwoFactorEnabled):
+			response.BusinessError(c, err.Error())
+		default:
+			response.ServerError(c)
+		}
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// SetupTwoFactorLogin starts the two-factor enrolment a role requires before
+// the login can be completed
+func SetupTwoFactorLogin(c *gin.Context) {
+	var req services.TwoFactorSetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	authSvc := c.MustGet("authService").(*services.AuthService)
+	enrolment, err := authSvc.SetupTwoFactorLogin(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidMFAToken):
+			response.Unauthorized(c, "invalid or expired mfa token")
+		case errors.Is(err, services.ErrUserInactive):
+			response.Error(c, response.CodeForbidden, "user is inactive")
+		case errors.Is(err, services.ErrTwoFactorEnabled):
+			response.BusinessError(c, err.Error())
+		default:
+			response.ServerError(c)
+		}
+		return
+	}
+
+	response.Success(c, enrolment)
+}
+
+// RefreshToken exchanges a refresh token for a new access token and refresh token
 func RefreshToken(c *gin.Context) {
-	// Get user from context (set by JWT middleware)
-	user, exists := c.Get("user")
-	if !exists {
-		response.UnauthorizedError(c)
+	var req services.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
 		return
 	}
 
-	userModel := user.(*models.User)
+	req.IP = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+
 	authSvc := c.MustGet("authService").(*services.AuthService)
-	token, err := authSvc.RefreshToken(c.Request.Context(), userModel.ID)
+	resp, err := authSvc.Refresh(c.Request.Context(), &req)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			response.Unauthorized(c, "invalid refresh token")
+			return
+		}
+		if errors.Is(err, services.ErrUserInactive) {
+			response.Error(c, response.CodeForbidden, "user is inactive")
+			return
+		}
 		response.Error(c, response.CodeServerError, "failed to refresh token")
 		return
 	}
 
-	response.Success(c, gin.H{
-		"access_token": token,
-		"token_type":   "Bearer",
-		"expires_in":   authSvc.GetConfig().JWT.ExpireTime,
-	})
+	response.Success(c, resp)
 }
 
 // Logout handles user logout requests
@@ -91,8 +180,10 @@ func Logout(c *gin.Context) {
 	userModel := user.(*models.User)
 	authSvc := c.MustGet("authService").(*services.AuthService)
 
-	// Log the logout action
-	err := authSvc.Logout(c.Request.Context(), userModel.ID)
+	// Revoke the token, end its session and log the logout action
+	claims, _ := c.Get("claims")
+	tokenClaims, _ := claims.(jwt.MapClaims)
+	err := authSvc.Logout(c.Request.Context(), userModel.ID, tokenClaims)
 	if err != nil {
 		// Even if logout logging fails, we still consider logout successful
 		// as the client-side token will be removed
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"errors"
+
+	"app/internal/core/services"
+	"app/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListLoginLocks handles the request to list the usernames and IPs locked
+// after too many failed logins
+func ListLoginLocks(c *gin.Context) {
+	loginLockSvc := c.MustGet("loginLockService").(*services.LoginLockService)
+	locks, err := loginLockSvc.Locks(c.Request.Context())
+	if err != nil {
+		response.Error(c, response.CodeServerError, "failed to list login locks")
+		return
+	}
+
+	response.Success(c, locks)
+}
+
+// ClearLoginLock handles the request to unlock a username or an IP
+func ClearLoginLock(c *gin.Context) {
+	var req services.ClearLoginLockRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	loginLockSvc := c.MustGet("loginLockService").(*services.LoginLockService)
+	if err := loginLockSvc.Clear(c.Request.Context(), req.Type, req.Key); err != nil {
+		if errors.Is(err, services.ErrInvalidLoginLockType) {
+			response.ParamError(c, err.Error())
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to clear login lock")
+		return
+	}
+
+	response.Success(c, nil)
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"errors"
+
 	"app/internal/core/models"
 	"app/internal/core/services"
 	"app/pkg/response"
@@ -88,3 +90,33 @@ func UpdateCurrentUser(c *gin.Context) {
 
 	response.Success(c, updatedUser)
 }
+
+// ChangeCurrentUserPassword handles the request to change the current user's
+// password, every session of the user ends with it
+func ChangeCurrentUserPassword(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.UnauthorizedError(c)
+		return
+	}
+
+	userModel := user.(*models.User)
+
+	var req services.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	userSvc := c.MustGet("userService").(*services.UserService)
+	if err := userSvc.ChangePassword(c.Request.Context(), userModel.ID, &req); err != nil {
+		if errors.Is(err, services.ErrIncorrectPassword) {
+			response.BusinessError(c, err.Error())
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to change password")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "password changed, please sign in again"})
+}
@@ -0,0 +1,258 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"app/internal/core/services"
+	"app/pkg/queue"
+	"app/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queueService returns the queue service, writing an error response when the queue is not configured
+func queueService(c *gin.Context) (*services.QueueService, bool) {
+	svc, ok := c.Get("queueService")
+	if !ok {
+		response.BusinessError(c, "Queue service is not available")
+		return nil, false
+	}
+	return svc.(*services.QueueService), true
+}
+
+// queueError writes the response for a failed queue operation
+func queueError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, queue.ErrFailedJobNotFound), errors.Is(err, queue.ErrBatchNotFound):
+		response.NotFoundError(c)
+	case errors.Is(err, queue.ErrFailedStoreUnavailable), errors.Is(err, queue.ErrBatchStoreUnavailable),
+		errors.Is(err, queue.ErrMonitorUnavailable):
+		response.BusinessError(c, err.Error())
+	default:
+		response.ServerError(c)
+	}
+}
+
+// GetQueueOverview returns pending, delayed, reserved and failed counts, throughput and
+// average runtime of each queue, plus the live worker heartbeats.
+// The optional window query parameter sets the metrics window in minutes (default 60).
+// Subscribe to the "queues" SSE group for the same data pushed live.
+func GetQueueOverview(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	minutes, err := strconv.Atoi(c.DefaultQuery("window", "60"))
+	if err != nil || minutes <= 0 || minutes > int(queue.MetricsRetention/time.Minute) {
+		response.ParamError(c, "window must be between 1 and 1440 minutes")
+		return
+	}
+
+	overview, err := queueSvc.Overview(c.Request.Context(), time.Duration(minutes)*time.Minute)
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, overview)
+}
+
+// ListQueueWorkers returns the workers that reported a heartbeat recently, across all processes
+func ListQueueWorkers(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	workers, err := queueSvc.Heartbeats(c.Request.Context())
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, workers)
+}
+
+// PauseQueue stops workers from taking new jobs off a queue
+func PauseQueue(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	if err := queueSvc.Pause(c.Request.Context(), c.Param("name")); err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ResumeQueue lets workers take jobs off a paused queue again
+func ResumeQueue(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	if err := queueSvc.Resume(c.Request.Context(), c.Param("name")); err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ClearQueue deletes all pending, delayed and reserved jobs of a queue
+func ClearQueue(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	if err := queueSvc.Clear(c.Request.Context(), c.Param("name")); err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// GetQueueSettings returns the effective settings and running workers of each queue
+func GetQueueSettings(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	settings, err := queueSvc.Settings()
+	if err != nil {
+		response.BusinessError(c, err.Error())
+		return
+	}
+
+	response.Success(c, settings)
+}
+
+// ListFailedJobs returns all failed queue jobs
+func ListFailedJobs(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	jobs, err := queueSvc.FailedJobs(c.Request.Context())
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, jobs)
+}
+
+// GetFailedJob returns a failed queue job by ID
+func GetFailedJob(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	job, err := queueSvc.FindFailed(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, job)
+}
+
+// RetryFailedJob pushes a failed job back onto its queue, or all of them when the ID is "all"
+func RetryFailedJob(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+	if id == "all" {
+		count, err := queueSvc.RetryAll(c.Request.Context())
+		if err != nil {
+			queueError(c, err)
+			return
+		}
+		response.Success(c, gin.H{"retried": count})
+		return
+	}
+
+	if err := queueSvc.Retry(c.Request.Context(), id); err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"retried": 1})
+}
+
+// ForgetFailedJob deletes a failed queue job
+func ForgetFailedJob(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	if err := queueSvc.Forget(c.Request.Context(), c.Param("id")); err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// FlushFailedJobs deletes all failed queue jobs
+func FlushFailedJobs(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	if err := queueSvc.Flush(c.Request.Context()); err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ListBatches returns all job batches with their progress
+func ListBatches(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	batches, err := queueSvc.Batches(c.Request.Context())
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, batches)
+}
+
+// GetBatch returns a job batch and its progress by ID
+func GetBatch(c *gin.Context) {
+	queueSvc, ok := queueService(c)
+	if !ok {
+		return
+	}
+
+	batch, err := queueSvc.FindBatch(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+
+	response.Success(c, batch)
+}
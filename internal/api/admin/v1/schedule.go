@@ -0,0 +1,309 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"app/internal/core/models"
+	"app/internal/core/services"
+	"app/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListScheduleRuns handles the request to get a paginated list of scheduler runs
+// @Summary List schedule runs
+// @Description Get a paginated list of scheduled task runs, newest first. The output is only returned by the detail endpoint
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param task_name query string false "Task name"
+// @Param status query string false "Run status" Enums(success, failed)
+// @Param host query string false "Host that ran the task"
+// @Param start_time query string false "Started at or after (2006-01-02 15:04:05)"
+// @Param end_time query string false "Started at or before (2006-01-02 15:04:05)"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]models.ScheduleRun}}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/schedule/runs [get]
+func ListScheduleRuns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	var query services.ScheduleRunQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	pagination := &models.Pagination{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	runSvc := c.MustGet("scheduleRunService").(*services.ScheduleRunService)
+	runs, err := runSvc.ListRuns(c.Request.Context(), pagination, &query)
+	if err != nil {
+		response.Error(c, response.CodeServerError, "failed to fetch schedule runs")
+		return
+	}
+
+	response.PageSuccess(c, runs, pagination.Total, pagination.Page, pagination.PageSize)
+}
+
+// GetScheduleRun handles the request to get a scheduler run with its output
+// @Summary Get schedule run
+// @Description Get a scheduled task run by ID, including the captured output
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Run ID"
+// @Success 200 {object} response.Response{data=models.ScheduleRun}
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/schedule/runs/{id} [get]
+func GetScheduleRun(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid run ID")
+		return
+	}
+
+	runSvc := c.MustGet("scheduleRunService").(*services.ScheduleRunService)
+	run, err := runSvc.GetRun(c.Request.Context(), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		response.NotFoundError(c)
+		return
+	}
+	if err != nil {
+		response.ServerError(c)
+		return
+	}
+
+	response.Success(c, run)
+}
+
+// ListScheduledTasks handles the request to get a paginated list of scheduled tasks
+// @Summary List scheduled tasks
+// @Description Get a paginated list of the scheduled tasks managed from the admin panel
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} response.Response{data=response.PageData{list=[]models.ScheduledTask}}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/schedule/tasks [get]
+func ListScheduledTasks(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	pagination := &models.Pagination{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	taskSvc := c.MustGet("scheduledTaskService").(*services.ScheduledTaskService)
+	tasks, err := taskSvc.ListTasks(c.Request.Context(), pagination)
+	if err != nil {
+		response.Error(c, response.CodeServerError, "failed to fetch scheduled tasks")
+		return
+	}
+
+	response.PageSuccess(c, tasks, pagination.Total, pagination.Page, pagination.PageSize)
+}
+
+// CreateScheduledTask handles the request to create a scheduled task
+// @Summary Create scheduled task
+// @Description Create a scheduled task, running schedulers pick it up without a restart
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param task body services.CreateScheduledTaskRequest true "Scheduled task data"
+// @Success 200 {object} response.Response{data=models.ScheduledTask}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/schedule/tasks [post]
+func CreateScheduledTask(c *gin.Context) {
+	var req services.CreateScheduledTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	taskSvc := c.MustGet("scheduledTaskService").(*services.ScheduledTaskService)
+	task, err := taskSvc.CreateTask(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSchedule) {
+			response.ValidationError(c, err.Error())
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to create scheduled task")
+		return
+	}
+
+	response.Success(c, task)
+}
+
+// GetScheduledTask handles the request to get a scheduled task by ID
+// @Summary Get scheduled task
+// @Description Get a scheduled task by ID
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Scheduled task ID"
+// @Success 200 {object} response.Response{data=models.ScheduledTask}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/schedule/tasks/{id} [get]
+func GetScheduledTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid scheduled task ID")
+		return
+	}
+
+	taskSvc := c.MustGet("scheduledTaskService").(*services.ScheduledTaskService)
+	task, err := taskSvc.GetTask(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrScheduledTaskNotFound) {
+			response.NotFoundError(c)
+			return
+		}
+		response.ServerError(c)
+		return
+	}
+
+	response.Success(c, task)
+}
+
+// UpdateScheduledTask handles the request to update a scheduled task
+// @Summary Update scheduled task
+// @Description Update a scheduled task, running schedulers pick the change up without a restart
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Scheduled task ID"
+// @Param task body services.UpdateScheduledTaskRequest true "Scheduled task data"
+// @Success 200 {object} response.Response{data=models.ScheduledTask}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/schedule/tasks/{id} [put]
+func UpdateScheduledTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid scheduled task ID")
+		return
+	}
+
+	var req services.UpdateScheduledTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	taskSvc := c.MustGet("scheduledTaskService").(*services.ScheduledTaskService)
+	task, err := taskSvc.UpdateTask(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrScheduledTaskNotFound):
+			response.NotFoundError(c)
+		case errors.Is(err, services.ErrInvalidSchedule):
+			response.ValidationError(c, err.Error())
+		default:
+			response.Error(c, response.CodeServerError, "failed to update scheduled task")
+		}
+		return
+	}
+
+	response.Success(c, task)
+}
+
+// DeleteScheduledTask handles the request to delete a scheduled task
+// @Summary Delete scheduled task
+// @Description Delete a scheduled task, running schedulers stop scheduling it without a restart
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Scheduled task ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/schedule/tasks/{id} [delete]
+func DeleteScheduledTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid scheduled task ID")
+		return
+	}
+
+	taskSvc := c.MustGet("scheduledTaskService").(*services.ScheduledTaskService)
+	if err := taskSvc.DeleteTask(c.Request.Context(), uint(id)); err != nil {
+		if errors.Is(err, services.ErrScheduledTaskNotFound) {
+			response.NotFoundError(c)
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to delete scheduled task")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RunScheduledTask handles the request to run a scheduled task now
+// @Summary Run scheduled task now
+// @Description Ask the schedulers to run a task on their next sync, with the same locking as scheduled runs. The result shows up in the run history
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param id path int true "Scheduled task ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/schedule/tasks/{id}/run [post]
+func RunScheduledTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid scheduled task ID")
+		return
+	}
+
+	taskSvc := c.MustGet("scheduledTaskService").(*services.ScheduledTaskService)
+	if err := taskSvc.RequestRun(c.Request.Context(), uint(id)); err != nil {
+		if errors.Is(err, services.ErrScheduledTaskNotFound) {
+			response.NotFoundError(c)
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to request scheduled task run")
+		return
+	}
+
+	response.Success(c, nil)
+}
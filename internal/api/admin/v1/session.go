@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"app/internal/core/models"
+	"app/internal/core/services"
+	"app/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListCurrentUserSessions handles the request to list the devices the
+// current user is signed in on
+func ListCurrentUserSessions(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.UnauthorizedError(c)
+		return
+	}
+
+	userModel := user.(*models.User)
+	authSvc := c.MustGet("authService").(*services.AuthService)
+	sessions, err := authSvc.ListSessions(c.Request.Context(), userModel.ID, c.GetString("sessionID"))
+	if err != nil {
+		response.Error(c, response.CodeServerError, "failed to list sessions")
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// RevokeCurrentUserSession handles the request to sign the current user out
+// of one device
+func RevokeCurrentUserSession(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.UnauthorizedError(c)
+		return
+	}
+
+	userModel := user.(*models.User)
+	authSvc := c.MustGet("authService").(*services.AuthService)
+	if err := authSvc.RevokeUserSession(c.Request.Context(), userModel.ID, c.Param("id")); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			response.NotFoundError(c)
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to revoke session")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeOtherCurrentUserSessions handles the request to sign the current user
+// out of every device but the one making the request
+func RevokeOtherCurrentUserSessions(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.UnauthorizedError(c)
+		return
+	}
+
+	userModel := user.(*models.User)
+	authSvc := c.MustGet("authService").(*services.AuthService)
+	if err := authSvc.RevokeOtherSessions(c.Request.Context(), userModel.ID, c.GetString("sessionID")); err != nil {
+		response.Error(c, response.CodeServerError, "failed to revoke sessions")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Other sessions revoked successfully"})
+}
+
+// ListUserSessions handles the request to list the devices a user is signed in on
+func ListUserSessions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid user ID")
+		return
+	}
+
+	userSvc := c.MustGet("userService").(*services.UserService)
+	if _, err := userSvc.GetByID(c.Request.Context(), uint(id)); err != nil {
+		response.NotFoundError(c)
+		return
+	}
+
+	authSvc := c.MustGet("authService").(*services.AuthService)
+	sessions, err := authSvc.ListSessions(c.Request.Context(), uint(id), c.GetString("sessionID"))
+	if err != nil {
+		response.Error(c, response.CodeServerError, "failed to list sessions")
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// RevokeUserSession handles the request to sign a user out of one device
+func RevokeUserSession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid user ID")
+		return
+	}
+
+	userSvc := c.MustGet("userService").(*services.UserService)
+	if userSvc.IsSuperAdmin(uint(id)) {
+		response.BusinessError(c, services.ErrSuperAdminModify.Error())
+		return
+	}
+
+	authSvc := c.MustGet("authService").(*services.AuthService)
+	if err := authSvc.RevokeUserSession(c.Request.Context(), uint(id), c.Param("session_id")); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			response.NotFoundError(c)
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to revoke session")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Session revoked successfully"})
+}
@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"errors"
+
+	"app/internal/core/models"
+	"app/internal/core/services"
+	"app/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTwoFactorStatus handles the request to get the two-factor
+// authentication status of the current user
+func GetTwoFactorStatus(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.UnauthorizedError(c)
+		return
+	}
+
+	userModel := user.(*models.User)
+	twoFactorSvc := c.MustGet("twoFactorService").(*services.TwoFactorService)
+	status, err := twoFactorSvc.Status(c.Request.Context(), userModel.ID)
+	if err != nil {
+		response.Error(c, response.CodeServerError, "failed to get two-factor status")
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// EnrolTwoFactor handles the request to start the two-factor enrolment of
+// the current user, returning the secret as an otpauth URI and a QR code
+func EnrolTwoFactor(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.UnauthorizedError(c)
+		return
+	}
+
+	userModel := user.(*models.User)
+	twoFactorSvc := c.MustGet("twoFactorService").(*services.TwoFactorService)
+	enrolment, err := twoFactorSvc.Enrol(c.Request.Context(), userModel)
+	if err != nil {
+		if errors.Is(err, services.ErrTwoFactorEnabled) {
+			response.BusinessError(c, err.Error())
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to start two-factor enrolment")
+		return
+	}
+
+	response.Success(c, enrolment)
+}
+
+// ConfirmTwoFactor handles the request to turn two-factor authentication on
+// with a first code, returning the recovery codes
+func ConfirmTwoFactor(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.UnauthorizedError(c)
+		return
+	}
+
+	var req services.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	userModel := user.(*models.User)
+	twoFactorSvc := c.MustGet("twoFactorService").(*services.TwoFactorService)
+	codes, err := twoFactorSvc.Confirm(c.Request.Context(), userModel.ID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err, "failed to enable two-factor authentication")
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// DisableTwoFactor handles the request to turn two-factor authentication off
+func DisableTwoFactor(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.UnauthorizedError(c)
+		return
+	}
+
+	var req services.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	userModel := user.(*models.User)
+	twoFactorSvc := c.MustGet("twoFactorService").(*services.TwoFactorService)
+	if err := twoFactorSvc.Disable(c.Request.Context(), userModel.ID, req.Code); err != nil {
+		respondTwoFactorError(c, err, "failed to disable two-factor authentication")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes handles the request to replace the recovery codes
+// of the current user
+func RegenerateRecoveryCodes(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.UnauthorizedError(c)
+		return
+	}
+
+	var req services.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	userModel := user.(*models.User)
+	twoFactorSvc := c.MustGet("twoFactorService").(*services.TwoFactorService)
+	codes, err := twoFactorSvc.RegenerateRecoveryCodes(c.Request.Context(), userModel.ID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err, "failed to regenerate recovery codes")
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// respondTwoFactorError maps the errors of the two-factor service
+func respondTwoFactorError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		response.Error(c, response.CodeInvalidTwoFactorCode, "invalid two-factor code")
+	case errors.Is(err, services.ErrTwoFactorEnabled), errors.Is(err, services.ErrTwoFactorNotEnabled),
+		errors.Is(err, services.ErrTwoFactorNotEnrolled), errors.Is(err, services.ErrTwoFactorRequired):
+		response.BusinessError(c, err.Error())
+	default:
+		response.Error(c, response.CodeServerError, message)
+	}
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -225,6 +226,30 @@ func UpdateUserRoles(c *gin.Context) {
 	response.Success(c, gin.H{"message": "User roles updated successfully"})
 }
 
+// ForceLogoutUser handles the request to sign a user out of every device
+func ForceLogoutUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid user ID")
+		return
+	}
+
+	userSvc := c.MustGet("userService").(*services.UserService)
+	if err := userSvc.ForceLogout(c.Request.Context(), uint(id)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrSuperAdminModify):
+			response.BusinessError(c, err.Error())
+		case errors.Is(err, services.ErrUserNotFound):
+			response.NotFoundError(c)
+		default:
+			response.Error(c, response.CodeServerError, "failed to sign the user out")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"message": "User signed out successfully"})
+}
+
 // UpdateUserStatus handles the request to update a user's status
 func UpdateUserStatus(c *gin.Context) {
 	traceID := c.GetString("trace_id")
@@ -0,0 +1,28 @@
+package bootstrap
+
+import (
+	"errors"
+	"time"
+
+	"app/internal/config"
+	"app/internal/core/repositories"
+	"app/internal/core/services"
+	"app/internal/schedule"
+	"app/pkg/database"
+)
+
+// SetupScheduleDatabase records the scheduler runs in the schedule_runs table
+// and schedules the tasks of the scheduled_tasks table
+func SetupScheduleDatabase(cfg *config.Config, scheduler *schedule.Scheduler) error {
+	db := database.GetDB()
+	if db == nil {
+		return errors.New("database is not initialized")
+	}
+
+	history := services.NewScheduleRunService(repositories.NewScheduleRunRepository(db))
+	scheduler.SetHistory(history, time.Duration(cfg.Schedule.RetentionDays)*24*time.Hour)
+
+	tasks := services.NewScheduledTaskService(repositories.NewScheduledTaskRepository(db))
+	scheduler.SetTaskStore(tasks, time.Duration(cfg.Schedule.SyncInterval)*time.Second)
+	return nil
+}
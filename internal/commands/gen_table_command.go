@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"app/pkg/console"
+	"app/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+type GenTableCommand struct {
+	*console.BaseCommand
+}
+
+func NewGenTableCommand() *GenTableCommand {
+	return &GenTableCommand{
+		BaseCommand: console.NewCommand("gen:table", "Generate CRUD code from an existing table"),
+	}
+}
+
+func (c *GenTableCommand) Configure(config *console.CommandConfig) {
+	config.Name = "gen:table"
+	config.Description = "Generate the model, repository, service, handlers, routes and menu seeder of an existing table"
+	config.Usage = "gen:table {table} [--model=Name] [--dry-run]"
+	config.Arguments = []console.Argument{
+		{Name: "table", Description: "The database table, e.g. blog_posts", Required: true},
+	}
+	config.Options = []console.Option{
+		{
+			Name:        "model",
+			Description: "The model name, the singular of the table name by default",
+		},
+		{
+			Name:        "dry-run",
+			Description: "Print the changes as a diff instead of writing the files",
+			Flag:        true,
+		},
+		{
+			Name:        "force",
+			Shortcut:    "f",
+			Description: "Overwrite existing files",
+			Flag:        true,
+		},
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *GenTableCommand) Handle(ctx context.Context) error {
+	db := database.GetDB()
+	if db == nil {
+		return fmt.Errorf("gen:table needs a database connection")
+	}
+
+	table := c.GetArgument("table")
+	if !db.Migrator().HasTable(table) {
+		return fmt.Errorf("table %s does not exist", table)
+	}
+	columns, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return fmt.Errorf("failed to read the columns of %s: %v", table, err)
+	}
+
+	name := c.GetOption("model")
+	if name == "" {
+		words := splitWords(table)
+		if len(words) == 0 {
+			return fmt.Errorf("cannot derive a model name from %q, use --model", table)
+		}
+		words[len(words)-1] = singularize(words[len(words)-1])
+		name = strings.Join(words, "_")
+	}
+
+	resource, err := newTableResource(name, table, columns)
+	if err != nil {
+		return err
+	}
+	for _, column := range resource.Columns {
+		if column.Unknown {
+			c.Error("Unknown type of column %s, mapped to string", column.Column)
+		}
+	}
+
+	targets := []crudTarget{
+		{filepath.Join("internal/core/models", resource.File+".go"), crudModelTemplate},
+		{filepath.Join("internal/core/repositories", resource.File+"_repo.go"), crudRepositoryTemplate},
+		{filepath.Join("internal/core/services", resource.File+"_svc.go"), crudServiceTemplate},
+		{filepath.Join("internal/api/admin/v1", resource.File+".go"), crudHandlerTemplate},
+		{filepath.Join("internal/routes", resource.File+"_routes.go"), crudRoutesTemplate},
+		{filepath.Join("internal/database/seeders", resource.File+"_menu_seeder.go"), crudSeederTemplate},
+	}
+	dryRun := c.HasOption("dry-run")
+	if err := generateCrud(c.BaseCommand, resource, targets, c.HasOption("force"), dryRun); err != nil {
+		return err
+	}
+
+	if !dryRun {
+		c.Success("Resource %s generated from %s, run the %s seeder", resource.Model, table, resource.Seeder)
+	}
+	return nil
+}
+
+// newTableResource builds a resource from the columns of an existing table
+func newTableResource(name, table string, columns []gorm.ColumnType) (*crudResource, error) {
+	resource, err := newCrudResource(name)
+	if err != nil {
+		return nil, err
+	}
+	resource.Table = table
+
+	primaryKeys := 0
+	resource.Columns = make([]crudField, 0, len(columns))
+	for _, column := range columns {
+		field, err := tableField(column)
+		if err != nil {
+			return nil, err
+		}
+		resource.Columns = append(resource.Columns, field)
+
+		if isPrimaryKey, _ := column.PrimaryKey(); isPrimaryKey {
+			primaryKeys++
+			continue
+		}
+		switch field.Column {
+		case "created_at", "updated_at", "deleted_at":
+			// Maintained by GORM
+			continue
+		}
+		if autoIncrement, _ := column.AutoIncrement(); autoIncrement {
+			continue
+		}
+		resource.Fields = append(resource.Fields, field)
+	}
+
+	// The handlers and repositories look records up by a numeric ID
+	if primaryKeys != 1 {
+		return nil, fmt.Errorf("table %s needs a single integer primary key", table)
+	}
+	for _, field := range resource.Columns {
+		if strings.Contains(field.Tags, "primaryKey") && field.Type != "uint" {
+			return nil, fmt.Errorf("table %s needs a single integer primary key", table)
+		}
+	}
+	return resource, nil
+}
+
+// tableField maps a database column to a model field
+func tableField(column gorm.ColumnType) (crudField, error) {
+	words := splitWords(column.Name())
+	if len(words) == 0 {
+		return crudField{}, fmt.Errorf("cannot map column %q to a Go name", column.Name())
+	}
+
+	field := crudField{
+		Name:   pascal(words),
+		Column: column.Name(),
+		Label:  strings.ToUpper(words[0][:1]) + strings.Join(words, " ")[1:],
+	}
+
+	columnType, _ := column.ColumnType()
+	columnType = strings.ToLower(columnType)
+	isPrimaryKey, _ := column.PrimaryKey()
+	nullable, _ := column.Nullable()
+	_, hasDefault := column.DefaultValue()
+
+	var tags []string
+	if strings.Join(words, "_") != field.Column {
+		tags = append(tags, "column:"+field.Column)
+	}
+	if isPrimaryKey {
+		tags = append(tags, "primaryKey")
+		if autoIncrement, _ := column.AutoIncrement(); autoIncrement {
+			tags = append(tags, "autoIncrement")
+		}
+	}
+	if columnType != "" {
+		tags = append(tags, "type:"+columnType)
+	}
+	if !nullable && !isPrimaryKey {
+		tags = append(tags, "not null")
+	}
+	if unique, _ := column.Unique(); unique && !isPrimaryKey {
+		tags = append(tags, "unique")
+	}
+	field.Tags = strings.Join(tags, ";")
+
+	field.Type, field.Unknown = columnGoType(strings.ToLower(column.DatabaseTypeName()), columnType)
+	switch {
+	case field.Column == "deleted_at" && field.Type == "time.Time":
+		field.Type = "gorm.DeletedAt"
+	case isPrimaryKey:
+		if strings.Contains(field.Type, "int") {
+			field.Type = "uint"
+		}
+	case field.Type == "[]byte":
+		// nil already means unset
+		field.Pointer = true
+	case nullable:
+		field.Type = "*" + field.Type
+		field.Pointer = true
+	default:
+		field.Required = !hasDefault && field.Type != "bool"
+	}
+	return field, nil
+}
+
+// columnGoType returns the Go type of a database type, unknown types are strings
+func columnGoType(databaseType, columnType string) (goType string, unknown bool) {
+	unsigned := strings.Contains(columnType, "unsigned")
+	switch databaseType {
+	case "tinyint":
+		if strings.HasPrefix(columnType, "tinyint(1)") {
+			return "bool", false
+		}
+		if unsigned {
+			return "uint", false
+		}
+		return "int", false
+	case "bool", "boolean":
+		return "bool", false
+	case "smallint", "mediumint", "int", "integer", "int2", "int4", "serial", "year":
+		if unsigned {
+			return "uint", false
+		}
+		return "int", false
+	case "bigint", "int8", "bigserial":
+		if unsigned {
+			return "uint64", false
+		}
+		return "int64", false
+	case "decimal", "numeric", "float", "double", "real", "float4", "float8", "double precision":
+		return "float64", false
+	case "char", "varchar", "character", "character varying", "tinytext", "text", "mediumtext", "longtext",
+		"enum", "set", "json", "jsonb", "uuid", "time":
+		return "string", false
+	case "date", "datetime", "timestamp", "timestamptz":
+		return "time.Time", false
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bytea":
+		return "[]byte", false
+	default:
+		return "string", true
+	}
+}
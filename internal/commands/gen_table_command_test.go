@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+)
+
+// column describes a MySQL column the way the driver reports it
+func column(name, databaseType, columnType string, nullable bool) migrator.ColumnType {
+	return migrator.ColumnType{
+		NameValue:       sql.NullString{String: name, Valid: true},
+		DataTypeValue:   sql.NullString{String: databaseType, Valid: true},
+		ColumnTypeValue: sql.NullString{String: columnType, Valid: true},
+		NullableValue:   sql.NullBool{Bool: nullable, Valid: true},
+	}
+}
+
+func TestNewTableResource(t *testing.T) {
+	id := column("id", "BIGINT", "bigint unsigned", false)
+	id.PrimaryKeyValue = sql.NullBool{Bool: true, Valid: true}
+	id.AutoIncrementValue = sql.NullBool{Bool: true, Valid: true}
+	status := column("status", "TINYINT", "tinyint", false)
+	status.DefaultValueValue = sql.NullString{String: "1", Valid: true}
+
+	columns := []gorm.ColumnType{
+		id,
+		column("title", "VARCHAR", "varchar(255)", false),
+		column("summary", "TEXT", "text", true),
+		column("published", "TINYINT", "tinyint(1)", false),
+		status,
+		column("price", "DECIMAL", "decimal(10,2)", false),
+		column("published_at", "DATETIME", "datetime", true),
+		column("created_at", "DATETIME", "datetime(3)", true),
+		column("updated_at", "DATETIME", "datetime(3)", true),
+		column("deleted_at", "DATETIME", "datetime(3)", true),
+	}
+
+	resource, err := newTableResource("blog_post", "blog_posts", columns)
+	require.NoError(t, err)
+	assert.Equal(t, "BlogPost", resource.Model)
+	assert.Equal(t, "blog_posts", resource.Table)
+
+	types := make(map[string]string)
+	for _, field := range resource.Columns {
+		types[field.Column] = field.Type
+	}
+	assert.Equal(t, map[string]string{
+		"id":           "uint",
+		"title":        "string",
+		"summary":      "*string",
+		"published":    "bool",
+		"status":       "int",
+		"price":        "float64",
+		"published_at": "*time.Time",
+		"created_at":   "*time.Time",
+		"updated_at":   "*time.Time",
+		"deleted_at":   "gorm.DeletedAt",
+	}, types)
+	assert.Equal(t, "primaryKey;autoIncrement;type:bigint unsigned", resource.Columns[0].Tags)
+
+	// Only the columns the requests set
+	var editable []string
+	required := make(map[string]bool)
+	for _, field := range resource.Fields {
+		editable = append(editable, field.Column)
+		required[field.Column] = field.Required
+	}
+	assert.Equal(t, []string{"title", "summary", "published", "status", "price", "published_at"}, editable)
+	assert.Equal(t, map[string]bool{
+		"title": true, "summary": false, "published": false, "status": false, "price": true, "published_at": false,
+	}, required)
+}
+
+func TestNewTableResourceNeedsIntegerPrimaryKey(t *testing.T) {
+	code := column("code", "VARCHAR", "varchar(32)", false)
+	code.PrimaryKeyValue = sql.NullBool{Bool: true, Valid: true}
+
+	_, err := newTableResource("country", "countries", []gorm.ColumnType{code})
+	assert.Error(t, err)
+
+	_, err = newTableResource("log", "logs", []gorm.ColumnType{column("message", "TEXT", "text", false)})
+	assert.Error(t, err)
+}
+
+func TestSingularize(t *testing.T) {
+	for plural, singular := range map[string]string{
+		"posts": "post", "categories": "category", "boxes": "box", "branches": "branch", "statuses": "status", "address": "address",
+	} {
+		assert.Equal(t, singular, singularize(plural), plural)
+	}
+}
@@ -15,14 +15,20 @@ type MakeCommand struct {
 }
 
 func NewMakeCommand() *MakeCommand {
-	cmd := &MakeCommand{
+	return &MakeCommand{
 		BaseCommand: console.NewCommand("make", "Generate code files"),
 	}
+}
 
-	cmd.AddArgument("type", "Type of file to create (controller/model/service)")
-	cmd.AddArgument("name", "Name of the file to create")
-
-	return cmd
+func (c *MakeCommand) Configure(config *console.CommandConfig) {
+	config.Name = "make"
+	config.Description = "Generate code files"
+	config.Usage = "make {handler|model|service} {name}"
+	config.Arguments = []console.Argument{
+		{Name: "type", Description: "Type of file to create (handler/model/service), see make:crud for a full resource", Required: true},
+		{Name: "name", Description: "Name of the file to create", Required: true},
+	}
+	c.BaseCommand.Configure(config)
 }
 
 func (c *MakeCommand) Handle(ctx context.Context) error {
@@ -30,8 +36,8 @@ func (c *MakeCommand) Handle(ctx context.Context) error {
 	name := c.GetArgument("name")
 
 	switch strings.ToLower(fileType) {
-	case "controller":
-		return c.makeController(name)
+	case "handler", "controller":
+		return c.makeHandler(name)
 	case "model":
 		return c.makeModel(name)
 	case "service":
@@ -41,40 +47,65 @@ func (c *MakeCommand) Handle(ctx context.Context) error {
 	}
 }
 
-func (c *MakeCommand) makeController(name string) error {
-	template := `package controllers
+// makeHandler creates a v1 admin handler, handlers are gin functions rather than controllers
+func (c *MakeCommand) makeHandler(name string) error {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return fmt.Errorf("invalid name: %s", name)
+	}
+	handler := pascal(words)
+
+	template := `package v1
+
+import (
+	"app/pkg/response"
 
-type %sController struct {}
+	"github.com/gin-gonic/gin"
+)
 
-func New%sController() *%sController {
-	return &%sController{}
+// %s handles the request
+func %s(c *gin.Context) {
+	response.Success(c, nil)
 }
 `
-	return c.createFile("internal/api/admin/controllers", name+"_controller.go", template, name, name, name, name)
+	return c.createFile("internal/api/admin/v1", strings.Join(words, "_")+".go", template, handler, handler)
 }
 
 func (c *MakeCommand) makeModel(name string) error {
 	template := `package models
 
 type %s struct {
-	ID        uint      ` + "`json:\"id\"`" + `
-	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
-	UpdatedAt time.Time ` + "`json:\"updated_at\"`" + `
+	BaseModel
+}
+
+func (%s) TableName() string {
+	return "%s"
 }
 `
-	return c.createFile("internal/core/models", name+".go", template, name)
+	words := splitWords(name)
+	if len(words) == 0 {
+		return fmt.Errorf("invalid name: %s", name)
+	}
+	table := append(append([]string(nil), words[:len(words)-1]...), pluralize(words[len(words)-1]))
+	model := pascal(words)
+	return c.createFile("internal/core/models", strings.Join(words, "_")+".go", template, model, model, strings.Join(table, "_"))
 }
 
 func (c *MakeCommand) makeService(name string) error {
 	template := `package services
 
-type %sService struct {}
+type %sService struct{}
 
 func New%sService() *%sService {
 	return &%sService{}
 }
 `
-	return c.createFile("internal/core/services", name+"_service.go", template, name, name, name, name)
+	words := splitWords(name)
+	if len(words) == 0 {
+		return fmt.Errorf("invalid name: %s", name)
+	}
+	service := pascal(words)
+	return c.createFile("internal/core/services", strings.Join(words, "_")+"_svc.go", template, service, service, service, service)
 }
 
 func (c *MakeCommand) createFile(dir, filename, template string, args ...interface{}) error {
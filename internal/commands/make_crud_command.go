@@ -0,0 +1,553 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"app/pkg/console"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Markers in the hand-written files where make:crud wires the generated resources
+const (
+	crudRoutesMarker   = "// make:crud:routes"
+	crudServicesMarker = "// make:crud:services"
+)
+
+type MakeCrudCommand struct {
+	*console.BaseCommand
+}
+
+func NewMakeCrudCommand() *MakeCrudCommand {
+	return &MakeCrudCommand{
+		BaseCommand: console.NewCommand("make:crud", "Generate a CRUD resource"),
+	}
+}
+
+func (c *MakeCrudCommand) Configure(config *console.CommandConfig) {
+	config.Name = "make:crud"
+	config.Description = "Generate a CRUD resource: model, migration, repository, service, handlers, routes, menu seeder and test"
+	config.Usage = `make:crud {Name} --fields="title:string:required,done:bool"`
+	config.Arguments = []console.Argument{
+		{Name: "name", Description: "The resource name, e.g. Article or BlogPost", Required: true},
+	}
+	config.Options = []console.Option{
+		{
+			Name:        "fields",
+			Description: "Comma separated name:type[:modifier...] fields. Types: string, text, int, uint, int64, bool, float, decimal, date, datetime. Modifiers: required, unique, index",
+			Required:    true,
+		},
+		{
+			Name:        "force",
+			Shortcut:    "f",
+			Description: "Overwrite existing files",
+			Flag:        true,
+		},
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *MakeCrudCommand) Handle(ctx context.Context) error {
+	resource, err := newCrudResource(c.GetArgument("name"))
+	if err != nil {
+		return err
+	}
+	if resource.Fields, err = parseCrudFields(c.GetOption("fields")); err != nil {
+		return err
+	}
+
+	if err := generateCrud(c.BaseCommand, resource, crudTargets(resource), c.HasOption("force"), false); err != nil {
+		return err
+	}
+
+	c.Success("Resource %s created, run the migration and the %s seeder", resource.Model, resource.Seeder)
+	return nil
+}
+
+// crudTarget is a file generated from a template
+type crudTarget struct {
+	path     string
+	template string
+}
+
+// crudTargets returns the files generated for a resource
+func crudTargets(resource *crudResource) []crudTarget {
+	return []crudTarget{
+		{filepath.Join("internal/core/models", resource.File+".go"), crudModelTemplate},
+		{filepath.Join("internal/database/migrations", resource.Migration+".go"), crudMigrationTemplate},
+		{filepath.Join("internal/core/repositories", resource.File+"_repo.go"), crudRepositoryTemplate},
+		{filepath.Join("internal/core/services", resource.File+"_svc.go"), crudServiceTemplate},
+		{filepath.Join("internal/api/admin/v1", resource.File+".go"), crudHandlerTemplate},
+		{filepath.Join("internal/api/admin/v1", resource.File+"_test.go"), crudHandlerTestTemplate},
+		{filepath.Join("internal/routes", resource.File+"_routes.go"), crudRoutesTemplate},
+		{filepath.Join("internal/database/seeders", resource.File+"_menu_seeder.go"), crudSeederTemplate},
+	}
+}
+
+// crudEdit is a line make:crud adds to a hand-written file
+type crudEdit struct {
+	path, marker, text string
+}
+
+// crudEdits returns the changes wiring a resource into the hand-written files
+func crudEdits(resource *crudResource) ([]crudEdit, error) {
+	interfaces, err := renderGo(crudInterfaceTemplate, resource)
+	if err != nil {
+		return nil, err
+	}
+	return []crudEdit{
+		{"internal/core/services/interfaces.go", "", "\n" + string(interfaces[bytes.Index(interfaces, []byte("//")):])},
+		{"internal/routes/router.go", crudRoutesMarker, fmt.Sprintf("register%sRoutes(adminV1Protected)", resource.Model)},
+		{"internal/api/admin/middleware/service_injection.go", crudServicesMarker, fmt.Sprintf(
+			"c.Set(%q, services.New%sService(repositories.New%sRepository(db)))", resource.ServiceKey, resource.Model, resource.Model)},
+	}, nil
+}
+
+// generateCrud renders the files of a resource and wires it into the
+// hand-written files. With dryRun nothing is written, the changes are
+// printed as a unified diff instead.
+func generateCrud(c *console.BaseCommand, resource *crudResource, targets []crudTarget, force, dryRun bool) error {
+	// Check everything first so a failure does not leave half a resource behind
+	rendered := make([][]byte, len(targets))
+	for i, target := range targets {
+		if _, err := os.Stat(target.path); err == nil && !force && !dryRun {
+			return fmt.Errorf("file already exists: %s (use --force to overwrite)", target.path)
+		}
+		var err error
+		if rendered[i], err = renderGo(target.template, resource); err != nil {
+			return fmt.Errorf("failed to generate %s: %v", target.path, err)
+		}
+	}
+
+	edits, err := crudEdits(resource)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		for i, target := range targets {
+			if err := writeDiff(c.Output(), target.path, rendered[i]); err != nil {
+				return err
+			}
+		}
+		for _, edit := range edits {
+			content, err := insertedCode(edit.path, edit.marker, edit.text)
+			if err != nil {
+				c.Error("%v, add this to %s yourself:\n%s", err, edit.path, edit.text)
+				continue
+			}
+			if err := writeDiff(c.Output(), edit.path, content); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, target := range targets {
+		if err := os.WriteFile(target.path, rendered[i], 0644); err != nil {
+			return err
+		}
+		c.Line("Created %s", target.path)
+	}
+
+	for _, edit := range edits {
+		content, err := insertedCode(edit.path, edit.marker, edit.text)
+		if err == nil {
+			err = os.WriteFile(edit.path, content, 0644)
+		}
+		if err != nil {
+			c.Error("%v, add this to %s yourself:\n%s", err, edit.path, edit.text)
+			continue
+		}
+		c.Line("Updated %s", edit.path)
+	}
+	return nil
+}
+
+// writeDiff writes the unified diff between a file on disk and its new content
+func writeDiff(w io.Writer, path string, content []byte) error {
+	from := "a/" + path
+	current, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		from = "/dev/null"
+	} else if err != nil {
+		return err
+	}
+
+	var lines []string
+	if len(current) > 0 {
+		lines = difflib.SplitLines(string(current))
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines,
+		B:        difflib.SplitLines(string(content)),
+		FromFile: from,
+		ToFile:   "b/" + path,
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, diff)
+	return err
+}
+
+// renderGo executes a template and formats the result as Go source
+func renderGo(text string, data interface{}) ([]byte, error) {
+	tmpl, err := template.New("crud").Funcs(template.FuncMap{
+		"tag": func(tag string) string { return "`" + tag + "`" },
+		"usesType": func(fields []crudField, pkg string) bool {
+			for _, field := range fields {
+				if strings.Contains(field.Type, pkg) {
+					return true
+				}
+			}
+			return false
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// insertedCode returns the content of a file with a line added before the
+// marker, indented like it, or with text appended when marker is empty.
+// Code already present is not added twice.
+func insertedCode(path, marker, text string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(content, []byte(strings.TrimSpace(text))) {
+		return content, nil
+	}
+
+	if marker == "" {
+		content = append(bytes.TrimRight(content, "\n"), []byte("\n"+text)...)
+	} else {
+		at := bytes.Index(content, []byte(marker))
+		if at < 0 {
+			return nil, fmt.Errorf("marker %q not found", marker)
+		}
+		lineStart := bytes.LastIndexByte(content[:at], '\n') + 1
+		indent := string(content[lineStart:at])
+
+		var updated []byte
+		updated = append(updated, content[:lineStart]...)
+		updated = append(updated, []byte(indent+text+"\n")...)
+		content = append(updated, content[lineStart:]...)
+	}
+
+	return format.Source(content)
+}
+
+// crudResource holds the names used across the generated files
+type crudResource struct {
+	Model       string      // BlogPost
+	Plural      string      // BlogPosts
+	Var         string      // blogPost
+	PluralVar   string      // blogPosts
+	Label       string      // blog post
+	ALabel      string      // a blog post
+	PluralLabel string      // blog posts
+	Title       string      // Blog Posts
+	File        string      // blog_post
+	Table       string      // blog_posts
+	Path        string      // blog-posts
+	Permission  string      // blog_post
+	ServiceKey  string      // blogPostService
+	Migration   string      // 2026_01_02_150405_create_blog_posts_table
+	Seeder      string      // blog_post_menus
+	Fields      []crudField // editable fields, in the requests
+	Columns     []crudField // all the columns of an existing table, the model does not embed BaseModel
+}
+
+// crudField is a column of the generated model
+type crudField struct {
+	Name      string // PublishedAt
+	Column    string // published_at
+	Label     string // Published at
+	Type      string // *time.Time
+	Tags      string // gorm tags without the comment
+	Pointer   bool
+	Required  bool
+	Sample    string // Go literal used as a value in the test requests
+	SampleAlt string
+	Unknown   bool // the database type of the column could not be mapped
+}
+
+// ModelTag returns the struct tag of the model field
+func (f crudField) ModelTag() string {
+	name := f.Column
+	if f.Type == "gorm.DeletedAt" {
+		// Hidden like in BaseModel
+		name = "-"
+	}
+	if f.Tags == "" {
+		return fmt.Sprintf("`json:%q`", name)
+	}
+	return fmt.Sprintf("`json:%q gorm:%q`", name, f.Tags)
+}
+
+// MigrationTag returns the struct tag of the migration column
+func (f crudField) MigrationTag() string {
+	tags := f.Tags
+	if tags != "" {
+		tags += ";"
+	}
+	return fmt.Sprintf("`gorm:%q`", tags+"comment:'"+f.Label+"'")
+}
+
+// CreateTag returns the struct tag of the create request field
+func (f crudField) CreateTag() string {
+	// A required bool would reject false
+	if f.Required && f.Type != "bool" {
+		return fmt.Sprintf("`json:%q binding:\"required\"`", f.Column)
+	}
+	return fmt.Sprintf("`json:%q`", f.Column)
+}
+
+// UpdateType is the type of the update request field, nil leaves the column unchanged
+func (f crudField) UpdateType() string {
+	if f.Pointer {
+		return f.Type
+	}
+	return "*" + f.Type
+}
+
+// ModelFields returns the fields declared in the model, the columns of the
+// table when it was read from the database, otherwise the fields next to BaseModel
+func (r *crudResource) ModelFields() []crudField {
+	if r.Columns != nil {
+		return r.Columns
+	}
+	return r.Fields
+}
+
+func newCrudResource(name string) (*crudResource, error) {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("invalid resource name %q", name)
+	}
+	pluralWords := append(append([]string(nil), words[:len(words)-1]...), pluralize(words[len(words)-1]))
+
+	r := &crudResource{
+		Model:       pascal(words),
+		Plural:      pascal(pluralWords),
+		Var:         camel(words),
+		PluralVar:   camel(pluralWords),
+		Label:       strings.Join(words, " "),
+		PluralLabel: strings.Join(pluralWords, " "),
+		File:        strings.Join(words, "_"),
+		Table:       strings.Join(pluralWords, "_"),
+		Path:        strings.Join(pluralWords, "-"),
+		Permission:  strings.Join(words, "_"),
+	}
+	r.ALabel = "a " + r.Label
+	if strings.ContainsRune("aeiou", rune(r.Label[0])) {
+		r.ALabel = "an " + r.Label
+	}
+	r.Title = titleCase(r.PluralLabel)
+	r.ServiceKey = r.Var + "Service"
+	r.Migration = time.Now().Format("2006_01_02_150405") + "_create_" + r.Table + "_table"
+	r.Seeder = r.File + "_menus"
+	return r, nil
+}
+
+// parseCrudFields parses the --fields option
+func parseCrudFields(spec string) ([]crudField, error) {
+	var fields []crudField
+	seen := make(map[string]bool)
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, ":")
+		words := splitWords(parts[0])
+		if len(parts) < 2 || len(words) == 0 {
+			return nil, fmt.Errorf("invalid field %q, expected name:type", item)
+		}
+
+		field := crudField{
+			Name:   pascal(words),
+			Column: strings.Join(words, "_"),
+			Label:  strings.ToUpper(words[0][:1]) + strings.Join(words, " ")[1:],
+		}
+		switch field.Column {
+		case "id", "created_at", "updated_at", "deleted_at":
+			return nil, fmt.Errorf("field %q is already part of BaseModel", field.Column)
+		}
+		if seen[field.Column] {
+			return nil, fmt.Errorf("duplicate field %q", field.Column)
+		}
+		seen[field.Column] = true
+
+		var tags []string
+		switch strings.ToLower(parts[1]) {
+		case "string":
+			field.Type, field.Sample, field.SampleAlt = "string", `"example"`, `"updated"`
+			tags = append(tags, "size:255")
+		case "text":
+			field.Type, field.Sample, field.SampleAlt = "string", `"example"`, `"updated"`
+			tags = append(tags, "type:text")
+		case "int", "uint", "int64":
+			field.Type, field.Sample, field.SampleAlt = strings.ToLower(parts[1]), "1", "2"
+		case "bool":
+			field.Type, field.Sample, field.SampleAlt = "bool", "true", "false"
+			tags = append(tags, "not null", "default:false")
+		case "float":
+			field.Type, field.Sample, field.SampleAlt = "float64", "1.5", "2.5"
+		case "decimal":
+			field.Type, field.Sample, field.SampleAlt = "float64", "1.5", "2.5"
+			tags = append(tags, "type:decimal(10,2)")
+		case "date":
+			field.Type, field.Sample, field.SampleAlt = "*time.Time", `"2026-01-02T00:00:00Z"`, `"2026-01-03T00:00:00Z"`
+			field.Pointer = true
+			tags = append(tags, "type:date")
+		case "datetime", "timestamp", "time":
+			field.Type, field.Sample, field.SampleAlt = "*time.Time", `"2026-01-02T15:04:05Z"`, `"2026-01-03T15:04:05Z"`
+			field.Pointer = true
+			tags = append(tags, "type:timestamp")
+		default:
+			return nil, fmt.Errorf("unsupported type %q for field %q", parts[1], parts[0])
+		}
+
+		for _, modifier := range parts[2:] {
+			switch strings.ToLower(modifier) {
+			case "required":
+				field.Required = true
+				if field.Type != "bool" {
+					tags = append(tags, "not null")
+				}
+			case "unique":
+				tags = append(tags, "uniqueIndex")
+			case "index":
+				tags = append(tags, "index")
+			default:
+				return nil, fmt.Errorf("unsupported modifier %q for field %q", modifier, parts[0])
+			}
+		}
+
+		field.Tags = strings.Join(tags, ";")
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("at least one field is required")
+	}
+	return fields, nil
+}
+
+// initialisms are written in upper case in Go names
+var initialisms = map[string]bool{"id": true, "url": true, "api": true, "ip": true, "http": true, "uuid": true, "json": true, "sql": true}
+
+// splitWords splits a CamelCase, snake_case or kebab-case name into lower case words
+func splitWords(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(strings.TrimSpace(name))
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+			continue
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			return nil
+		case unicode.IsUpper(r) && i > 0:
+			// A new word starts at an upper case letter, except inside an acronym
+			previousUpper := unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !previousUpper || nextLower {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	if len(words) > 0 && unicode.IsDigit([]rune(words[0])[0]) {
+		return nil
+	}
+	return words
+}
+
+// pascal joins words as PascalCase
+func pascal(words []string) string {
+	var b strings.Builder
+	for _, word := range words {
+		if initialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// camel joins words as camelCase
+func camel(words []string) string {
+	return words[0] + pascal(words[1:])
+}
+
+// titleCase upper cases the first letter of each word
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// pluralize returns the English plural of a lower case word
+func pluralize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
+
+// singularize returns the English singular of a lower case plural word
+func singularize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ses"), strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
@@ -0,0 +1,281 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"app/pkg/console"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the generated code")
+
+func TestParseCrudFields(t *testing.T) {
+	fields, err := parseCrudFields("title:string:required:unique, body:text, PublishedAt:datetime:index, done:bool:required, price:decimal")
+	require.NoError(t, err)
+
+	byColumn := make(map[string]crudField)
+	for _, field := range fields {
+		byColumn[field.Column] = field
+	}
+	assert.Len(t, fields, 5)
+	assert.Equal(t, crudField{
+		Name: "Title", Column: "title", Label: "Title", Type: "string", Tags: "size:255;not null;uniqueIndex",
+		Required: true, Sample: `"example"`, SampleAlt: `"updated"`,
+	}, byColumn["title"])
+	assert.Equal(t, "PublishedAt", byColumn["published_at"].Name)
+	assert.Equal(t, "*time.Time", byColumn["published_at"].Type)
+	assert.Equal(t, "type:timestamp;index", byColumn["published_at"].Tags)
+	assert.True(t, byColumn["published_at"].Pointer)
+	// A required bool stays nullable in the request, false is a valid value
+	assert.Equal(t, "not null;default:false", byColumn["done"].Tags)
+	assert.Equal(t, "`json:\"done\"`", byColumn["done"].CreateTag())
+	assert.Equal(t, "type:decimal(10,2)", byColumn["price"].Tags)
+
+	tests := []struct {
+		name string
+		spec string
+		err  string
+	}{
+		{name: "unsupported type", spec: "title:varchar", err: `unsupported type "varchar"`},
+		{name: "unsupported modifier", spec: "title:string:nullable", err: `unsupported modifier "nullable"`},
+		{name: "missing type", spec: "title", err: "expected name:type"},
+		{name: "invalid name", spec: "9lives:int", err: "expected name:type"},
+		{name: "duplicate field", spec: "title:string,Title:text", err: `duplicate field "title"`},
+		{name: "duplicate in another case", spec: "publishedAt:date,published_at:datetime", err: `duplicate field "published_at"`},
+		{name: "reserved id", spec: "id:uint", err: "already part of BaseModel"},
+		{name: "reserved timestamp", spec: "title:string,CreatedAt:datetime", err: "already part of BaseModel"},
+		{name: "reserved soft delete", spec: "deleted_at:datetime", err: "already part of BaseModel"},
+		{name: "no fields", spec: " , ", err: "at least one field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCrudFields(tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+// testCrudResource returns a resource using every field type, with a fixed
+// migration name so the generated files do not depend on the clock
+func testCrudResource(t *testing.T) *crudResource {
+	resource, err := newCrudResource("BlogPost")
+	require.NoError(t, err)
+	resource.Migration = "2026_01_02_150405_create_blog_posts_table"
+	resource.Fields, err = parseCrudFields("title:string:required:unique,body:text,views:int,author_id:uint,score:int64," +
+		"featured:bool:required,rating:float,price:decimal:required,published_on:date,published_at:datetime:index")
+	require.NoError(t, err)
+	return resource
+}
+
+// useCrudSkeleton runs the test in a directory holding the hand-written
+// files make:crud edits, the generated paths are relative to it
+func useCrudSkeleton(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"internal/core/services/interfaces.go":               "package services\n",
+		"internal/routes/router.go":                          "package routes\n\nfunc SetupRoutes() {\n\t// make:crud:routes\n}\n",
+		"internal/api/admin/middleware/service_injection.go": "package middleware\n\nfunc ServiceInjection() {\n\t// make:crud:services\n}\n",
+		"internal/core/models/.keep":                         "",
+		"internal/database/migrations/.keep":                 "",
+		"internal/core/repositories/.keep":                   "",
+		"internal/api/admin/v1/.keep":                        "",
+		"internal/database/seeders/.keep":                    "",
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+// newTestCrudCommand returns a command writing its messages to out
+func newTestCrudCommand(out *bytes.Buffer) *console.BaseCommand {
+	cmd := console.NewCommand("make:crud", "")
+	cmd.SetOutput(out)
+	return cmd
+}
+
+func TestGenerateCrudRefusesExistingFiles(t *testing.T) {
+	useCrudSkeleton(t)
+	resource := testCrudResource(t)
+	targets := crudTargets(resource)
+	var out bytes.Buffer
+
+	require.NoError(t, generateCrud(newTestCrudCommand(&out), resource, targets, false, false))
+	for _, target := range targets {
+		assert.FileExists(t, target.path)
+	}
+
+	// A second run without --force writes nothing, not even the files that do not exist
+	model := targets[0].path
+	require.NoError(t, os.WriteFile(model, []byte("package models\n\n// edited by hand\n"), 0644))
+	seeder := targets[len(targets)-1].path
+	require.NoError(t, os.Remove(seeder))
+
+	err := generateCrud(newTestCrudCommand(&out), resource, targets, false, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "file already exists: "+model)
+	content, err := os.ReadFile(model)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "edited by hand")
+	assert.NoFileExists(t, seeder)
+
+	// --force overwrites them, the wiring is not added twice
+	require.NoError(t, generateCrud(newTestCrudCommand(&out), resource, targets, true, false))
+	content, err = os.ReadFile(model)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "edited by hand")
+	assert.FileExists(t, seeder)
+
+	router, err := os.ReadFile("internal/routes/router.go")
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(router), "registerBlogPostRoutes(adminV1Protected)"))
+	interfaces, err := os.ReadFile("internal/core/services/interfaces.go")
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(interfaces), "type BlogPostRepository interface"))
+}
+
+func TestGenerateCrudDryRun(t *testing.T) {
+	useCrudSkeleton(t)
+	resource := testCrudResource(t)
+	targets := crudTargets(resource)
+
+	// The diff of an existing file shows the lines that would change
+	model := targets[0].path
+	require.NoError(t, os.WriteFile(model, []byte("package models\n"), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, generateCrud(newTestCrudCommand(&out), resource, targets, false, true))
+	diff := out.String()
+
+	for _, target := range targets[1:] {
+		assert.Contains(t, diff, "--- /dev/null\n+++ b/"+target.path+"\n")
+		assert.NoFileExists(t, target.path)
+	}
+	assert.Contains(t, diff, "--- a/"+model+"\n+++ b/"+model+"\n")
+	assert.Contains(t, diff, "+type BlogPost struct {\n")
+	assert.Contains(t, diff, "--- a/internal/routes/router.go\n+++ b/internal/routes/router.go\n")
+	assert.Contains(t, diff, "+\tregisterBlogPostRoutes(adminV1Protected)\n \t// make:crud:routes\n")
+	assert.Contains(t, diff, "+\tc.Set(\"blogPostService\", services.NewBlogPostService(repositories.NewBlogPostRepository(db)))\n")
+
+	// Nothing was written
+	content, err := os.ReadFile(model)
+	require.NoError(t, err)
+	assert.Equal(t, "package models\n", string(content))
+	router, err := os.ReadFile("internal/routes/router.go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(router), "registerBlogPostRoutes")
+}
+
+// TestCrudTemplatesGolden compares the generated files with testdata, run
+// with -update to regenerate them after changing the templates
+func TestCrudTemplatesGolden(t *testing.T) {
+	resource := testCrudResource(t)
+
+	for _, target := range crudTargets(resource) {
+		rendered, err := renderGo(target.template, resource)
+		require.NoError(t, err, target.path)
+
+		formatted, err := format.Source(rendered)
+		require.NoError(t, err, target.path)
+		assert.Equal(t, string(formatted), string(rendered), "%s is not gofmt-clean", target.path)
+
+		golden := filepath.Join("testdata", "make_crud", target.path+".golden")
+		if *update {
+			require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0755))
+			require.NoError(t, os.WriteFile(golden, rendered, 0644))
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		require.NoError(t, err, "run the test with -update to create %s", golden)
+		assert.Equal(t, string(want), string(rendered), target.path)
+	}
+}
+
+// TestCrudTemplatesCompile type checks the generated files, their tests
+// included, within the packages they are generated into
+func TestCrudTemplatesCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the application packages")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	resource := testCrudResource(t)
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err)
+
+	// The generated files are added to the packages through an overlay,
+	// the tree itself is left untouched
+	replace := make(map[string]string)
+	overlayDir := t.TempDir()
+	add := func(path string, content []byte) {
+		file := filepath.Join(overlayDir, strings.ReplaceAll(path, string(filepath.Separator), "_"))
+		require.NoError(t, os.WriteFile(file, content, 0644))
+		replace[filepath.Join(root, path)] = file
+	}
+
+	packages := make(map[string]bool)
+	for _, target := range crudTargets(resource) {
+		rendered, err := renderGo(target.template, resource)
+		require.NoError(t, err, target.path)
+		add(target.path, rendered)
+		packages["./"+filepath.Dir(target.path)] = true
+	}
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(root))
+	edits, err := crudEdits(resource)
+	if err == nil {
+		for _, edit := range edits {
+			var content []byte
+			if content, err = insertedCode(edit.path, edit.marker, edit.text); err != nil {
+				break
+			}
+			add(edit.path, content)
+		}
+	}
+	require.NoError(t, os.Chdir(wd))
+	require.NoError(t, err)
+
+	overlay, err := json.Marshal(map[string]interface{}{"Replace": replace})
+	require.NoError(t, err)
+	overlayFile := filepath.Join(overlayDir, "overlay.json")
+	require.NoError(t, os.WriteFile(overlayFile, overlay, 0644))
+
+	for pkg := range packages {
+		t.Run(pkg, func(t *testing.T) {
+			// Only the errors the generated code brings in are reported
+			vet := exec.Command(goBin, "vet", pkg)
+			vet.Dir = root
+			if out, err := vet.CombinedOutput(); err != nil {
+				t.Skipf("%s does not build without the generated files:\n%s", pkg, out)
+			}
+
+			vet = exec.Command(goBin, "vet", "-overlay", overlayFile, pkg)
+			vet.Dir = root
+			out, err := vet.CombinedOutput()
+			assert.NoError(t, err, "%s", out)
+		})
+	}
+}
@@ -0,0 +1,630 @@
+package commands
+
+// Templates of the files generated by make:crud, shaped after the Todo resource
+
+const crudModelTemplate = `package models
+{{$time := usesType .ModelFields "time."}}{{$gorm := usesType .ModelFields "gorm."}}
+{{- if and $time $gorm}}
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+{{else if $time}}
+import "time"
+{{else if $gorm}}
+import "gorm.io/gorm"
+{{end}}
+// {{.Model}} is {{.ALabel}} managed from the admin panel
+type {{.Model}} struct {
+{{- if not .Columns}}
+	BaseModel
+{{- end}}
+{{- range .ModelFields}}
+	{{.Name}} {{.Type}} {{.ModelTag}}
+{{- end}}
+}
+
+func ({{.Model}}) TableName() string {
+	return "{{.Table}}"
+}
+`
+
+const crudMigrationTemplate = `package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	up := func(tx *gorm.DB) error {
+		type {{.Model}} struct {
+			ID uint {{tag "gorm:\"primarykey\""}}
+{{- range .Fields}}
+			{{.Name}} {{.Type}} {{.MigrationTag}}
+{{- end}}
+			CreatedAt time.Time {{tag "gorm:\"type:timestamp\""}}
+			UpdatedAt time.Time {{tag "gorm:\"type:timestamp\""}}
+			DeletedAt gorm.DeletedAt {{tag "gorm:\"index;type:timestamp\""}}
+		}
+
+		return tx.Table("{{.Table}}").AutoMigrate(&{{.Model}}{})
+	}
+
+	down := func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("{{.Table}}")
+	}
+
+	Register("create_{{.Table}}_table", NewMigration("{{.Migration}}.go", up, down))
+}
+`
+
+const crudRepositoryTemplate = `package repositories
+
+import (
+	"context"
+
+	"app/internal/core/models"
+
+	"gorm.io/gorm"
+)
+
+type {{.Model}}Repository struct {
+	*BaseRepository
+}
+
+func New{{.Model}}Repository(db *gorm.DB) *{{.Model}}Repository {
+	return &{{.Model}}Repository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Create{{.Model}} creates a new {{.Label}}
+func (r *{{.Model}}Repository) Create{{.Model}}(ctx context.Context, {{.Var}} *models.{{.Model}}) error {
+	return r.Create(ctx, {{.Var}})
+}
+
+// Update{{.Model}} saves all fields of {{.ALabel}}
+func (r *{{.Model}}Repository) Update{{.Model}}(ctx context.Context, {{.Var}} *models.{{.Model}}) error {
+	return r.Update(ctx, {{.Var}})
+}
+
+// Delete{{.Model}} soft deletes {{.ALabel}}
+func (r *{{.Model}}Repository) Delete{{.Model}}(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.{{.Model}}{}, id).Error
+}
+
+// Find{{.Model}}ByID retrieves {{.ALabel}} by its ID
+func (r *{{.Model}}Repository) Find{{.Model}}ByID(ctx context.Context, id uint) (*models.{{.Model}}, error) {
+	var {{.Var}} models.{{.Model}}
+	if err := r.FindByID(ctx, id, &{{.Var}}); err != nil {
+		return nil, err
+	}
+	return &{{.Var}}, nil
+}
+
+// List{{.Plural}} retrieves a paginated list of {{.PluralLabel}}, newest first
+func (r *{{.Model}}Repository) List{{.Plural}}(ctx context.Context, pagination *models.Pagination) ([]models.{{.Model}}, error) {
+	var {{.PluralVar}} []models.{{.Model}}
+	db := r.db.WithContext(ctx).Model(&models.{{.Model}}{})
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, err
+	}
+
+	err := db.Order("id DESC").
+		Offset(pagination.GetOffset()).
+		Limit(pagination.GetLimit()).
+		Find(&{{.PluralVar}}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return {{.PluralVar}}, nil
+}
+`
+
+const crudInterfaceTemplate = `package services
+
+// {{.Model}}Repository defines the interface for {{.Label}} data access
+type {{.Model}}Repository interface {
+	Create{{.Model}}(ctx context.Context, {{.Var}} *models.{{.Model}}) error
+	Update{{.Model}}(ctx context.Context, {{.Var}} *models.{{.Model}}) error
+	Delete{{.Model}}(ctx context.Context, id uint) error
+	Find{{.Model}}ByID(ctx context.Context, id uint) (*models.{{.Model}}, error)
+	List{{.Plural}}(ctx context.Context, pagination *models.Pagination) ([]models.{{.Model}}, error)
+}
+`
+
+const crudServiceTemplate = `package services
+
+import (
+	"context"
+	"errors"
+{{- if usesType .Fields "time."}}
+	"time"
+{{- end}}
+
+	"app/internal/core/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	Err{{.Model}}NotFound = errors.New("{{.Label}} not found")
+)
+
+type {{.Model}}Service struct {
+	repo {{.Model}}Repository
+}
+
+func New{{.Model}}Service(repo {{.Model}}Repository) *{{.Model}}Service {
+	return &{{.Model}}Service{
+		repo: repo,
+	}
+}
+
+type Create{{.Model}}Request struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.CreateTag}}
+{{- end}}
+}
+
+type Update{{.Model}}Request struct {
+{{- range .Fields}}
+	{{.Name}} {{.UpdateType}} {{tag (printf "json:%q" .Column)}}
+{{- end}}
+}
+
+// List retrieves a paginated list of {{.PluralLabel}}
+func (s *{{.Model}}Service) List(ctx context.Context, pagination *models.Pagination) ([]models.{{.Model}}, error) {
+	return s.repo.List{{.Plural}}(ctx, pagination)
+}
+
+// GetByID retrieves {{.ALabel}} by ID
+func (s *{{.Model}}Service) GetByID(ctx context.Context, id uint) (*models.{{.Model}}, error) {
+	{{.Var}}, err := s.repo.Find{{.Model}}ByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, Err{{.Model}}NotFound
+		}
+		return nil, err
+	}
+	return {{.Var}}, nil
+}
+
+// Create creates {{.ALabel}}
+func (s *{{.Model}}Service) Create(ctx context.Context, req *Create{{.Model}}Request) (*models.{{.Model}}, error) {
+	{{.Var}} := &models.{{.Model}}{
+{{- range .Fields}}
+		{{.Name}}: req.{{.Name}},
+{{- end}}
+	}
+	if err := s.repo.Create{{.Model}}(ctx, {{.Var}}); err != nil {
+		return nil, err
+	}
+	return {{.Var}}, nil
+}
+
+// Update updates the given fields of {{.ALabel}}
+func (s *{{.Model}}Service) Update(ctx context.Context, id uint, req *Update{{.Model}}Request) (*models.{{.Model}}, error) {
+	{{.Var}}, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+{{range .Fields}}
+	if req.{{.Name}} != nil {
+		{{$.Var}}.{{.Name}} = {{if not .Pointer}}*{{end}}req.{{.Name}}
+	}
+{{- end}}
+
+	if err := s.repo.Update{{.Model}}(ctx, {{.Var}}); err != nil {
+		return nil, err
+	}
+	return {{.Var}}, nil
+}
+
+// Delete deletes {{.ALabel}}
+func (s *{{.Model}}Service) Delete(ctx context.Context, id uint) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete{{.Model}}(ctx, id)
+}
+`
+
+const crudHandlerTemplate = `package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"app/internal/core/models"
+	"app/internal/core/services"
+	"app/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Create{{.Model}} handles the request to create a new {{.Label}}
+// @Summary Create {{.Label}}
+// @Description Create a new {{.Label}}
+// @Tags {{.Path}}
+// @Accept json
+// @Produce json
+// @Param {{.Var}} body services.Create{{.Model}}Request true "{{.Title}} info"
+// @Success 200 {object} response.Response{data=models.{{.Model}}}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/{{.Path}} [post]
+func Create{{.Model}}(c *gin.Context) {
+	var req services.Create{{.Model}}Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	{{.Var}}Svc := c.MustGet("{{.ServiceKey}}").(*services.{{.Model}}Service)
+	{{.Var}}, err := {{.Var}}Svc.Create(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, response.CodeServerError, "failed to create {{.Label}}")
+		return
+	}
+
+	response.Success(c, {{.Var}})
+}
+
+// List{{.Plural}} handles the request to get a paginated list of {{.PluralLabel}}
+// @Summary List {{.PluralLabel}}
+// @Description Get a paginated list of {{.PluralLabel}}
+// @Tags {{.Path}}
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} response.Response{data=response.PageData{list=[]models.{{.Model}}}}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/{{.Path}} [get]
+func List{{.Plural}}(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	pagination := &models.Pagination{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	{{.Var}}Svc := c.MustGet("{{.ServiceKey}}").(*services.{{.Model}}Service)
+	{{.PluralVar}}, err := {{.Var}}Svc.List(c.Request.Context(), pagination)
+	if err != nil {
+		response.Error(c, response.CodeServerError, "failed to fetch {{.PluralLabel}}")
+		return
+	}
+
+	response.PageSuccess(c, {{.PluralVar}}, pagination.Total, pagination.Page, pagination.PageSize)
+}
+
+// Get{{.Model}} handles the request to get {{.ALabel}} by ID
+// @Summary Get {{.Label}}
+// @Description Get {{.Label}} by ID
+// @Tags {{.Path}}
+// @Accept json
+// @Produce json
+// @Param id path int true "{{.Title}} ID"
+// @Success 200 {object} response.Response{data=models.{{.Model}}}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/{{.Path}}/{id} [get]
+func Get{{.Model}}(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid {{.Label}} ID")
+		return
+	}
+
+	{{.Var}}Svc := c.MustGet("{{.ServiceKey}}").(*services.{{.Model}}Service)
+	{{.Var}}, err := {{.Var}}Svc.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, services.Err{{.Model}}NotFound) {
+			response.NotFoundError(c)
+			return
+		}
+		response.ServerError(c)
+		return
+	}
+
+	response.Success(c, {{.Var}})
+}
+
+// Update{{.Model}} handles the request to update {{.ALabel}}
+// @Summary Update {{.Label}}
+// @Description Update {{.Label}} by ID, only the given fields are changed
+// @Tags {{.Path}}
+// @Accept json
+// @Produce json
+// @Param id path int true "{{.Title}} ID"
+// @Param {{.Var}} body services.Update{{.Model}}Request true "{{.Title}} info"
+// @Success 200 {object} response.Response{data=models.{{.Model}}}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/{{.Path}}/{id} [put]
+func Update{{.Model}}(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid {{.Label}} ID")
+		return
+	}
+
+	var req services.Update{{.Model}}Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, err.Error())
+		return
+	}
+
+	{{.Var}}Svc := c.MustGet("{{.ServiceKey}}").(*services.{{.Model}}Service)
+	{{.Var}}, err := {{.Var}}Svc.Update(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		if errors.Is(err, services.Err{{.Model}}NotFound) {
+			response.NotFoundError(c)
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to update {{.Label}}")
+		return
+	}
+
+	response.Success(c, {{.Var}})
+}
+
+// Delete{{.Model}} handles the request to delete {{.ALabel}}
+// @Summary Delete {{.Label}}
+// @Description Delete {{.Label}} by ID
+// @Tags {{.Path}}
+// @Accept json
+// @Produce json
+// @Param id path int true "{{.Title}} ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security Bearer
+// @Router /admin/v1/{{.Path}}/{id} [delete]
+func Delete{{.Model}}(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ParamError(c, "invalid {{.Label}} ID")
+		return
+	}
+
+	{{.Var}}Svc := c.MustGet("{{.ServiceKey}}").(*services.{{.Model}}Service)
+	if err := {{.Var}}Svc.Delete(c.Request.Context(), uint(id)); err != nil {
+		if errors.Is(err, services.Err{{.Model}}NotFound) {
+			response.NotFoundError(c)
+			return
+		}
+		response.Error(c, response.CodeServerError, "failed to delete {{.Label}}")
+		return
+	}
+
+	response.Success(c, nil)
+}
+`
+
+const crudHandlerTestTemplate = `package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal/core/models"
+	"app/internal/core/services"
+	"app/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fake{{.Model}}Repository keeps {{.PluralLabel}} in memory
+type fake{{.Model}}Repository struct {
+	items  map[uint]*models.{{.Model}}
+	nextID uint
+}
+
+func (r *fake{{.Model}}Repository) Create{{.Model}}(ctx context.Context, {{.Var}} *models.{{.Model}}) error {
+	{{.Var}}.ID = r.nextID
+	r.nextID++
+	r.items[{{.Var}}.ID] = {{.Var}}
+	return nil
+}
+
+func (r *fake{{.Model}}Repository) Update{{.Model}}(ctx context.Context, {{.Var}} *models.{{.Model}}) error {
+	r.items[{{.Var}}.ID] = {{.Var}}
+	return nil
+}
+
+func (r *fake{{.Model}}Repository) Delete{{.Model}}(ctx context.Context, id uint) error {
+	delete(r.items, id)
+	return nil
+}
+
+func (r *fake{{.Model}}Repository) Find{{.Model}}ByID(ctx context.Context, id uint) (*models.{{.Model}}, error) {
+	{{.Var}}, ok := r.items[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return {{.Var}}, nil
+}
+
+func (r *fake{{.Model}}Repository) List{{.Plural}}(ctx context.Context, pagination *models.Pagination) ([]models.{{.Model}}, error) {
+	{{.PluralVar}} := make([]models.{{.Model}}, 0, len(r.items))
+	for _, {{.Var}} := range r.items {
+		{{.PluralVar}} = append({{.PluralVar}}, *{{.Var}})
+	}
+	pagination.Total = int64(len({{.PluralVar}}))
+	return {{.PluralVar}}, nil
+}
+
+func setup{{.Model}}Router() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	repo := &fake{{.Model}}Repository{
+		items:  map[uint]*models.{{.Model}}{1: {BaseModel: models.BaseModel{ID: 1}}},
+		nextID: 2,
+	}
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("{{.ServiceKey}}", services.New{{.Model}}Service(repo))
+	})
+	r.GET("/{{.Path}}", List{{.Plural}})
+	r.POST("/{{.Path}}", Create{{.Model}})
+	r.GET("/{{.Path}}/:id", Get{{.Model}})
+	r.PUT("/{{.Path}}/:id", Update{{.Model}})
+	r.DELETE("/{{.Path}}/:id", Delete{{.Model}})
+	return r
+}
+
+func Test{{.Model}}Handlers(t *testing.T) {
+	created := map[string]interface{}{
+{{- range .Fields}}
+		"{{.Column}}": {{.Sample}},
+{{- end}}
+	}
+	updated := map[string]interface{}{
+{{- range .Fields}}
+		"{{.Column}}": {{.SampleAlt}},
+{{- end}}
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     interface{}
+		wantCode int
+	}{
+		{"list", http.MethodGet, "/{{.Path}}?page=1&page_size=10", nil, response.CodeSuccess},
+		{"create", http.MethodPost, "/{{.Path}}", created, response.CodeSuccess},
+		{"create with invalid body", http.MethodPost, "/{{.Path}}", "not an object", response.CodeValidationError},
+		{"get", http.MethodGet, "/{{.Path}}/1", nil, response.CodeSuccess},
+		{"get missing", http.MethodGet, "/{{.Path}}/99", nil, response.CodeNotFound},
+		{"get with invalid id", http.MethodGet, "/{{.Path}}/abc", nil, response.CodeParamError},
+		{"update", http.MethodPut, "/{{.Path}}/1", updated, response.CodeSuccess},
+		{"update missing", http.MethodPut, "/{{.Path}}/99", updated, response.CodeNotFound},
+		{"delete", http.MethodDelete, "/{{.Path}}/1", nil, response.CodeSuccess},
+		{"delete missing", http.MethodDelete, "/{{.Path}}/99", nil, response.CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setup{{.Model}}Router()
+
+			var body bytes.Buffer
+			if tt.body != nil {
+				assert.NoError(t, json.NewEncoder(&body).Encode(tt.body))
+			}
+			req, _ := http.NewRequest(tt.method, tt.path, &body)
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var resp response.Response
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantCode, resp.Code, resp.Message)
+		})
+	}
+}
+`
+
+const crudRoutesTemplate = `package routes
+
+import (
+	"app/internal/api/admin/middleware"
+	adminv1 "app/internal/api/admin/v1"
+
+	"github.com/gin-gonic/gin"
+)
+
+// register{{.Model}}Routes registers the {{.Label}} routes on the protected admin group
+func register{{.Model}}Routes(group *gin.RouterGroup) {
+	{{.PluralVar}} := group.Group("/{{.Path}}")
+	{
+		{{.PluralVar}}.GET("", middleware.RBAC("{{.Permission}}:view"), wrapHandler(adminv1.List{{.Plural}}))
+		{{.PluralVar}}.POST("", middleware.RBAC("{{.Permission}}:create"), wrapHandler(adminv1.Create{{.Model}}))
+		{{.PluralVar}}.GET("/:id", middleware.RBAC("{{.Permission}}:view"), wrapHandler(adminv1.Get{{.Model}}))
+		{{.PluralVar}}.PUT("/:id", middleware.RBAC("{{.Permission}}:edit"), wrapHandler(adminv1.Update{{.Model}}))
+		{{.PluralVar}}.DELETE("/:id", middleware.RBAC("{{.Permission}}:delete"), wrapHandler(adminv1.Delete{{.Model}}))
+	}
+}
+`
+
+const crudSeederTemplate = `package seeders
+
+import (
+	"app/internal/database/seeder"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Register("{{.Seeder}}", &seeder.Seeder{
+		Name:         "{{.Seeder}}",
+		Description:  "Create the {{.Label}} menu and permissions",
+		Dependencies: []string{"roles", "menus"},
+		Run: func(tx *gorm.DB) error {
+			menuID, err := upsertMenu(tx, permissionMenu{
+				Name:       "{{.Model}}",
+				Title:      "{{.Title}}",
+				Icon:       "Document",
+				Path:       "/{{.Path}}",
+				Component:  "@/views/{{.Path}}/index.vue",
+				Permission: "{{.Permission}}:view",
+			})
+			if err != nil {
+				return err
+			}
+
+			menuIDs := []uint{menuID}
+			buttons := []permissionMenu{
+				{Name: "{{.Model}}Create", Title: "Create {{.Label}}", Permission: "{{.Permission}}:create"},
+				{Name: "{{.Model}}Edit", Title: "Edit {{.Label}}", Permission: "{{.Permission}}:edit"},
+				{Name: "{{.Model}}Delete", Title: "Delete {{.Label}}", Permission: "{{.Permission}}:delete"},
+			}
+			for _, button := range buttons {
+				button.ParentID = &menuID
+				id, err := upsertMenu(tx, button)
+				if err != nil {
+					return err
+				}
+				menuIDs = append(menuIDs, id)
+			}
+
+			return grantMenus(tx, "admin", menuIDs...)
+		},
+	})
+}
+`
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"app/internal/core/services"
+	"app/pkg/console"
+)
+
+type QueueFailedCommand struct {
+	*console.BaseCommand
+}
+
+func NewQueueFailedCommand() *QueueFailedCommand {
+	return &QueueFailedCommand{
+		BaseCommand: console.NewCommand("queue:failed", "List all failed queue jobs"),
+	}
+}
+
+func (c *QueueFailedCommand) Configure(config *console.CommandConfig) {
+	config.Name = "queue:failed"
+	config.Description = "List all failed queue jobs"
+	config.Usage = "queue:failed"
+	c.BaseCommand.Configure(config)
+}
+
+func (c *QueueFailedCommand) Handle(ctx context.Context) error {
+	queueService, err := services.NewQueueService()
+	if err != nil {
+		return err
+	}
+
+	jobs, err := queueService.FailedJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(jobs) == 0 {
+		c.Info("No failed jobs")
+		return nil
+	}
+
+	c.Line("%-36s  %-12s  %-20s  %-19s  %s", "ID", "Queue", "Job", "Failed At", "Exception")
+	for _, job := range jobs {
+		c.Line("%-36s  %-12s  %-20s  %-19s  %s",
+			job.ID, job.Queue, job.Job, job.FailedAt.Format("2006-01-02 15:04:05"), firstLine(job.Exception))
+	}
+	return nil
+}
+
+type QueueRetryCommand struct {
+	*console.BaseCommand
+}
+
+func NewQueueRetryCommand() *QueueRetryCommand {
+	return &QueueRetryCommand{
+		BaseCommand: console.NewCommand("queue:retry", "Retry a failed queue job"),
+	}
+}
+
+func (c *QueueRetryCommand) Configure(config *console.CommandConfig) {
+	config.Name = "queue:retry"
+	config.Description = "Retry a failed queue job"
+	config.Usage = "queue:retry {id...|all}"
+	config.Arguments = []console.Argument{
+		{Name: "id", Description: "The IDs of the failed jobs, or \"all\" to retry every failed job", Required: true, Array: true},
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *QueueRetryCommand) Handle(ctx context.Context) error {
+	ids := c.GetArguments("id")
+
+	queueService, err := services.NewQueueService()
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 1 && ids[0] == "all" {
+		count, err := queueService.RetryAll(ctx)
+		if err != nil {
+			return err
+		}
+		c.Success("%d failed job(s) pushed back onto the queue", count)
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := queueService.Retry(ctx, id); err != nil {
+			return fmt.Errorf("failed to retry job %s: %w", id, err)
+		}
+		c.Success("Failed job %s pushed back onto the queue", id)
+	}
+	return nil
+}
+
+type QueueForgetCommand struct {
+	*console.BaseCommand
+}
+
+func NewQueueForgetCommand() *QueueForgetCommand {
+	return &QueueForgetCommand{
+		BaseCommand: console.NewCommand("queue:forget", "Delete a failed queue job"),
+	}
+}
+
+func (c *QueueForgetCommand) Configure(config *console.CommandConfig) {
+	config.Name = "queue:forget"
+	config.Description = "Delete a failed queue job"
+	config.Usage = "queue:forget {id}"
+	config.Arguments = []console.Argument{
+		{Name: "id", Description: "The ID of the failed job", Required: true},
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *QueueForgetCommand) Handle(ctx context.Context) error {
+	id := c.GetArgument("id")
+
+	queueService, err := services.NewQueueService()
+	if err != nil {
+		return err
+	}
+
+	if err := queueService.Forget(ctx, id); err != nil {
+		return err
+	}
+	c.Success("Failed job %s deleted", id)
+	return nil
+}
+
+type QueueFlushCommand struct {
+	*console.BaseCommand
+}
+
+func NewQueueFlushCommand() *QueueFlushCommand {
+	return &QueueFlushCommand{
+		BaseCommand: console.NewCommand("queue:flush", "Flush all failed queue jobs"),
+	}
+}
+
+func (c *QueueFlushCommand) Configure(config *console.CommandConfig) {
+	config.Name = "queue:flush"
+	config.Description = "Flush all failed queue jobs"
+	config.Usage = "queue:flush"
+	c.BaseCommand.Configure(config)
+}
+
+func (c *QueueFlushCommand) Handle(ctx context.Context) error {
+	queueService, err := services.NewQueueService()
+	if err != nil {
+		return err
+	}
+
+	if err := queueService.Flush(ctx); err != nil {
+		return err
+	}
+	c.Success("All failed jobs deleted")
+	return nil
+}
+
+// firstLine trims a multi-line message down to its first line for table output
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"app/internal/schedule"
+	"app/pkg/console"
+)
+
+type ScheduleListCommand struct {
+	console.BaseCommand
+	kernel *schedule.Kernel
+}
+
+func NewScheduleListCommand(kernel *schedule.Kernel) *ScheduleListCommand {
+	return &ScheduleListCommand{
+		BaseCommand: *console.NewCommand("schedule:list", "List the scheduled tasks"),
+		kernel:      kernel,
+	}
+}
+
+func (c *ScheduleListCommand) Configure(config *console.CommandConfig) {
+	config.Name = "schedule:list"
+	config.Description = "List the scheduled tasks"
+	config.Usage = "schedule:list"
+	c.BaseCommand.Configure(config)
+}
+
+func (c *ScheduleListCommand) Handle(ctx context.Context) error {
+	if err := c.kernel.Schedule(); err != nil {
+		return err
+	}
+
+	scheduler := c.kernel.Scheduler()
+	tasks, err := scheduler.Tasks(ctx)
+	if err != nil {
+		// The tasks defined in the kernel are still listed
+		c.Error("%v", err)
+	}
+
+	if len(tasks) == 0 {
+		c.Info("No scheduled tasks")
+		return nil
+	}
+
+	width := len("Task")
+	for _, task := range tasks {
+		width = max(width, len(task.Name))
+	}
+
+	now := time.Now()
+	// The number selects the task in schedule:test
+	number := len(fmt.Sprint(len(tasks)))
+	c.Line("%*s  %-*s  %-20s  %-19s  %-20s  %s", number, "#", width, "Task", "Expression", "Next Run", "Timezone", "Unique")
+	for i, task := range tasks {
+		location := scheduler.Location()
+		if task.Location != nil {
+			location = task.Location
+		}
+
+		next := "-"
+		if at := scheduler.NextRun(task, now); !at.IsZero() {
+			next = at.In(location).Format("2006-01-02 15:04:05")
+		}
+
+		unique := "no"
+		if task.Unique {
+			unique = "yes"
+		}
+
+		c.Line("%*d  %-*s  %-20s  %-19s  %-20s  %s", number, i+1, width, task.Name, task.Schedule, next, location, unique)
+	}
+	return nil
+}
@@ -31,10 +31,7 @@ func (c *ScheduleRunCommand) Configure(config *console.CommandConfig) {
 func (c *ScheduleRunCommand) Handle(ctx context.Context) error {
 	c.Info("Starting scheduler...")
 
-	// Register scheduled tasks
-	c.kernel.Schedule()
-
-	// Start the scheduler
+	// Register scheduled tasks and start the scheduler
 	if err := c.kernel.Start(ctx); err != nil {
 		return err
 	}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"app/internal/schedule"
+	"app/pkg/console"
+)
+
+type ScheduleTestCommand struct {
+	console.BaseCommand
+	kernel *schedule.Kernel
+}
+
+func NewScheduleTestCommand(kernel *schedule.Kernel) *ScheduleTestCommand {
+	return &ScheduleTestCommand{
+		BaseCommand: *console.NewCommand("schedule:test", "Run a scheduled task now"),
+		kernel:      kernel,
+	}
+}
+
+func (c *ScheduleTestCommand) Configure(config *console.CommandConfig) {
+	config.Name = "schedule:test"
+	config.Description = "Run a scheduled task now"
+	config.Usage = "schedule:test {task}"
+	config.Arguments = []console.Argument{
+		{Name: "task", Description: "The task number or name as shown by schedule:list", Required: true, Array: true},
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *ScheduleTestCommand) Handle(ctx context.Context) error {
+	if err := c.kernel.Schedule(); err != nil {
+		return err
+	}
+
+	name, err := c.taskName(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.Info("Running task %s...", name)
+	started := time.Now()
+	if err := c.kernel.Scheduler().RunTask(ctx, name); err != nil {
+		return err
+	}
+
+	c.Success("Task %s finished in %s", name, time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// taskName resolves the task argument. Task names contain the command
+// arguments, and options in them are taken as options of schedule:test, so
+// tasks are selected by their number in schedule:list. A name is still
+// accepted when its words are not options.
+func (c *ScheduleTestCommand) taskName(ctx context.Context) (string, error) {
+	words := c.GetArguments("task")
+	if len(words) > 1 {
+		return strings.Join(words, " "), nil
+	}
+
+	number, err := strconv.Atoi(words[0])
+	if err != nil {
+		return words[0], nil
+	}
+
+	tasks, err := c.kernel.Scheduler().Tasks(ctx)
+	if err != nil {
+		return "", err
+	}
+	if number < 1 || number > len(tasks) {
+		return "", fmt.Errorf("no task #%d, run schedule:list to see the tasks", number)
+	}
+	return tasks[number-1].Name, nil
+}
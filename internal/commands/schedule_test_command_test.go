@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"app/internal/schedule"
+	"app/pkg/console"
+	"app/pkg/locker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pruneCommand records the --days it was run with, days is a pointer as every
+// scheduled run gets its own copy of the command
+type pruneCommand struct {
+	*console.BaseCommand
+	days *[]string
+}
+
+func (c *pruneCommand) Configure(config *console.CommandConfig) {
+	config.Name = "test:prune"
+	config.Description = "Prune old records"
+	config.Options = []console.Option{
+		{Name: "days", Description: "Keep the records of the last days", Default: "7"},
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *pruneCommand) Handle(ctx context.Context) error {
+	*c.days = append(*c.days, c.GetOption("days"))
+	return nil
+}
+
+func newScheduleTestManager(t *testing.T) (*console.Manager, *[]string) {
+	manager := console.NewManager()
+	days := &[]string{}
+	manager.Register(NewHelloWorldCommand())
+	manager.Register(&pruneCommand{BaseCommand: console.NewCommand("test:prune", "Prune old records"), days: days})
+
+	scheduler := schedule.NewScheduler(manager, locker.NewMemoryLocker())
+	require.NoError(t, scheduler.Command("test:prune", "--days=30").Daily().Register())
+
+	kernel := schedule.NewKernel(scheduler)
+	list := NewScheduleListCommand(kernel)
+	list.SetOutput(io.Discard)
+	manager.Register(list)
+	test := NewScheduleTestCommand(kernel)
+	test.SetOutput(io.Discard)
+	manager.Register(test)
+	return manager, days
+}
+
+func TestScheduleListNumbersTasks(t *testing.T) {
+	manager, _ := newScheduleTestManager(t)
+	list := manager.FindCommand("schedule:list").(*ScheduleListCommand)
+	var out bytes.Buffer
+	list.SetOutput(&out)
+
+	require.NoError(t, manager.Call(context.Background(), "schedule:list"))
+	assert.Regexp(t, `(?m)^1  test:prune --days=30 `, out.String())
+	assert.Regexp(t, `(?m)^2  hello:world `, out.String())
+}
+
+func TestScheduleTestRunsTaskWithOptions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("by number", func(t *testing.T) {
+		manager, days := newScheduleTestManager(t)
+		require.NoError(t, manager.Call(ctx, "schedule:test", "1"))
+		assert.Equal(t, []string{"30"}, *days)
+	})
+
+	t.Run("by name after --", func(t *testing.T) {
+		manager, days := newScheduleTestManager(t)
+		require.NoError(t, manager.Call(ctx, "schedule:test", "--", "test:prune", "--days=30"))
+		assert.Equal(t, []string{"30"}, *days)
+	})
+
+	t.Run("unknown number", func(t *testing.T) {
+		manager, days := newScheduleTestManager(t)
+		err := manager.Call(ctx, "schedule:test", "3")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no task #3")
+		assert.Empty(t, *days)
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		manager, days := newScheduleTestManager(t)
+		err := manager.Call(ctx, "schedule:test", "test:prune")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "task not found: test:prune")
+		assert.Empty(t, *days)
+	})
+}
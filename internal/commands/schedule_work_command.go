@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"app/internal/schedule"
+	"app/pkg/console"
+)
+
+type ScheduleWorkCommand struct {
+	console.BaseCommand
+	kernel *schedule.Kernel
+}
+
+func NewScheduleWorkCommand(kernel *schedule.Kernel) *ScheduleWorkCommand {
+	return &ScheduleWorkCommand{
+		BaseCommand: *console.NewCommand("schedule:work", "Run the scheduler in the foreground"),
+		kernel:      kernel,
+	}
+}
+
+func (c *ScheduleWorkCommand) Configure(config *console.CommandConfig) {
+	config.Name = "schedule:work"
+	config.Description = "Run the scheduler in the foreground, printing every minute and every due task"
+	config.Usage = "schedule:work"
+	c.BaseCommand.Configure(config)
+}
+
+func (c *ScheduleWorkCommand) Handle(ctx context.Context) error {
+	c.Info("Running the scheduler in the foreground, press Ctrl+C to stop")
+
+	scheduler := c.kernel.Scheduler()
+	scheduler.SetVerbose(c.Output())
+	defer scheduler.SetVerbose(nil)
+
+	if err := c.kernel.Start(ctx); err != nil {
+		return err
+	}
+
+	// Wait for termination signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	c.Info("Shutting down scheduler...")
+	c.kernel.Stop()
+	return nil
+}
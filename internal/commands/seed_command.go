@@ -2,7 +2,6 @@ package commands
 
 import (
 	"context"
-	"flag"
 
 	"app/internal/database/seeder"
 	"app/internal/database/seeders"
@@ -24,6 +23,12 @@ func NewSeedCommand() *SeedCommand {
 func (c *SeedCommand) Configure(config *console.CommandConfig) {
 	config.Name = "seed"
 	config.Description = "Manage database seeding"
+	config.Usage = "seed {run|status|reset} [names...]"
+	config.Arguments = []console.Argument{
+		{Name: "action", Description: "Action to perform (run, status or reset)"},
+		{Name: "names", Description: "Seeders to run, all when empty", Array: true},
+	}
+	c.BaseCommand.Configure(config)
 }
 
 func (c *SeedCommand) Handle(ctx context.Context) error {
@@ -33,17 +38,11 @@ func (c *SeedCommand) Handle(ctx context.Context) error {
 	// Set the global manager for seeders to register themselves
 	seeders.SetGlobalManager(seederManager)
 
-	// Parse command arguments
-	args := flag.Args()
-	if len(args) < 2 {
-		return c.showUsage()
-	}
-
-	switch args[1] {
+	switch c.GetArgument("action") {
 	case "run":
-		if len(args) > 2 {
+		if names := c.GetArguments("names"); len(names) > 0 {
 			// Run specific seeders
-			return seederManager.Run(args[2:]...)
+			return seederManager.Run(names...)
 		}
 		// Run all seeders
 		return seederManager.Run()
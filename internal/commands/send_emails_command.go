@@ -24,13 +24,13 @@ func (c *SendEmailsCommand) Configure(config *console.CommandConfig) {
 			Name:        "queue",
 			Shortcut:    "q",
 			Description: "Queue name",
-			Required:    false,
+			Default:     "default",
 		},
 		{
 			Name:        "limit",
 			Shortcut:    "l",
 			Description: "Number of emails to send",
-			Required:    false,
+			Default:     "50",
 		},
 	}
 
@@ -39,14 +39,7 @@ func (c *SendEmailsCommand) Configure(config *console.CommandConfig) {
 
 func (c *SendEmailsCommand) Handle(ctx context.Context) error {
 	queue := c.GetOption("queue")
-	if queue == "" {
-		queue = "default"
-	}
-
 	limit := c.GetOption("limit")
-	if limit == "" {
-		limit = "50"
-	}
 
 	c.Info("Starting to send emails from queue: %s", queue)
 	c.Info("Processing limit: %s", limit)
@@ -16,11 +16,13 @@ import (
 type Config struct {
 	App        AppConfig        `mapstructure:"app"`
 	JWT        JWTConfig        `mapstructure:"jwt"`
+	Lockout    LockoutConfig    `mapstructure:"lockout"`
 	Database   DatabaseConfig   `mapstructure:"database"`
 	Redis      RedisConfig      `mapstructure:"redis"`
 	Log        LogConfig        `mapstructure:"log"`
 	Cache      CacheConfig      `mapstructure:"cache"`
 	Queue      QueueConfig      `mapstructure:"queue"`
+	Schedule   ScheduleConfig   `mapstructure:"schedule"`
 	I18n       i18n.Config      `mapstructure:"i18n"`
 	CORS       CORSConfig       `mapstructure:"cors"`
 	Server     ServerConfig     `mapstructure:"server"`
@@ -47,8 +49,21 @@ type AppConfig struct {
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret     string `mapstructure:"secret"`
-	ExpireTime int    `mapstructure:"expire_time"`
+	Secret              string `mapstructure:"secret"`
+	ExpireTime          int    `mapstructure:"expire_time"`
+	RefreshIdleTime     int    `mapstructure:"refresh_idle_time"`     // seconds a refresh token stays valid unused
+	RefreshAbsoluteTime int    `mapstructure:"refresh_absolute_time"` // seconds a session lasts however often it is refreshed
+}
+
+// LockoutConfig holds the login lockout configuration, failures are counted
+// from the login logs
+type LockoutConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	MaxAttempts    int  `mapstructure:"max_attempts"`     // failures of a username before it is locked
+	IPMaxAttempts  int  `mapstructure:"ip_max_attempts"`  // failures from an IP before it is locked
+	LockoutTime    int  `mapstructure:"lockout_time"`     // seconds of the first lock, doubled by every further failure
+	MaxLockoutTime int  `mapstructure:"max_lockout_time"` // seconds a lock lasts at most
+	Window         int  `mapstructure:"window"`           // seconds failures are counted over
 }
 
 // DatabaseConfig holds database configuration
@@ -100,13 +115,14 @@ type QueueConfig struct {
 		Database string `mapstructure:"database"`
 	} `mapstructure:"connection"`
 	Worker struct {
-		Sleep   int `mapstructure:"sleep"`
-		MaxJobs int `mapstructure:"max_jobs"`
-		MaxTime int `mapstructure:"max_time"`
-		Rest    int `mapstructure:"rest"`
-		Memory  int `mapstructure:"memory"`
-		Tries   int `mapstructure:"tries"`
-		Timeout int `mapstructure:"timeout"`
+		Sleep   int    `mapstructure:"sleep"`
+		MaxJobs int    `mapstructure:"max_jobs"`
+		MaxTime int    `mapstructure:"max_time"`
+		Rest    int    `mapstructure:"rest"`
+		Memory  int    `mapstructure:"memory"`
+		Tries   int    `mapstructure:"tries"`
+		Timeout int    `mapstructure:"timeout"`
+		Balance string `mapstructure:"balance"`
 	} `mapstructure:"worker"`
 	Queues map[string]QueueDetail `mapstructure:"queues"`
 }
@@ -119,6 +135,23 @@ type QueueDetail struct {
 	Tries      int   `mapstructure:"tries"`
 	RetryAfter int   `mapstructure:"retry_after"`
 	Backoff    []int `mapstructure:"backoff"`
+	// Throttle limits how many jobs of the queue may start per period
+	Throttle QueueThrottle `mapstructure:"throttle"`
+}
+
+// QueueThrottle holds the throttle of a queue, a limit of 0 disables it
+type QueueThrottle struct {
+	Limit        int `mapstructure:"limit"`
+	Per          int `mapstructure:"per"`
+	ReleaseAfter int `mapstructure:"release_after"`
+}
+
+// ScheduleConfig holds scheduler configuration
+type ScheduleConfig struct {
+	// RetentionDays is how long run history is kept, 0 keeps it forever
+	RetentionDays int `mapstructure:"retention_days"`
+	// SyncInterval is how often, in seconds, the tasks managed from the admin panel are reloaded
+	SyncInterval int `mapstructure:"sync_interval"`
 }
 
 // CORSConfig holds CORS configuration
@@ -191,6 +224,16 @@ func LoadConfig() (*Config, error) {
 	// JWT
 	config.JWT.Secret = getEnvOrDefault("JWT_SECRET", viper.GetString("jwt.secret"))
 	config.JWT.ExpireTime = getEnvIntOrDefault("JWT_EXPIRE", viper.GetInt("jwt.expire_time"))
+	config.JWT.RefreshIdleTime = getEnvIntOrDefault("JWT_REFRESH_IDLE_TIME", viper.GetInt("jwt.refresh_idle_time"))
+	config.JWT.RefreshAbsoluteTime = getEnvIntOrDefault("JWT_REFRESH_ABSOLUTE_TIME", viper.GetInt("jwt.refresh_absolute_time"))
+
+	// Lockout
+	config.Lockout.Enabled = getEnvBoolOrDefault("LOCKOUT_ENABLED", viper.GetBool("lockout.enabled"))
+	config.Lockout.MaxAttempts = getEnvIntOrDefault("LOCKOUT_MAX_ATTEMPTS", viper.GetInt("lockout.max_attempts"))
+	config.Lockout.IPMaxAttempts = getEnvIntOrDefault("LOCKOUT_IP_MAX_ATTEMPTS", viper.GetInt("lockout.ip_max_attempts"))
+	config.Lockout.LockoutTime = getEnvIntOrDefault("LOCKOUT_TIME", viper.GetInt("lockout.lockout_time"))
+	config.Lockout.MaxLockoutTime = getEnvIntOrDefault("LOCKOUT_MAX_TIME", viper.GetInt("lockout.max_lockout_time"))
+	config.Lockout.Window = getEnvIntOrDefault("LOCKOUT_WINDOW", viper.GetInt("lockout.window"))
 
 	// Database
 	config.Database.Driver = getEnvOrDefault("DB_DRIVER", viper.GetString("database.driver"))
@@ -231,6 +274,7 @@ func LoadConfig() (*Config, error) {
 	config.Queue.Worker.Memory = viper.GetInt("queue.worker.memory")
 	config.Queue.Worker.Tries = viper.GetInt("queue.worker.tries")
 	config.Queue.Worker.Timeout = viper.GetInt("queue.worker.timeout")
+	config.Queue.Worker.Balance = viper.GetString("queue.worker.balance")
 
 	// Queue details
 	config.Queue.Queues = make(map[string]QueueDetail)
@@ -243,6 +287,10 @@ func LoadConfig() (*Config, error) {
 		config.Queue.Queues[name] = detail
 	}
 
+	// Schedule
+	config.Schedule.RetentionDays = getEnvIntOrDefault("SCHEDULE_RETENTION_DAYS", viper.GetInt("schedule.retention_days"))
+	config.Schedule.SyncInterval = getEnvIntOrDefault("SCHEDULE_SYNC_INTERVAL", viper.GetInt("schedule.sync_interval"))
+
 	// Server
 	config.Server.Address = getEnvOrDefault("SERVER_ADDRESS", viper.GetString("server.address"))
 	config.Server.Mode = getEnvOrDefault("SERVER_MODE", viper.GetString("server.mode"))
@@ -1,6 +1,7 @@
 package jobs
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -8,6 +9,14 @@ import (
 	"app/pkg/queue"
 )
 
+func init() {
+	// 注册任务类型，名称会写入队列数据，修改会导致已入队的任务无法识别
+	queue.RegisterJob("example", func() queue.JobInterface { return &ExampleJob{} })
+	queue.RegisterJob("send_welcome_email", func() queue.JobInterface { return &SendWelcomeEmailJob{} })
+	queue.RegisterJob("process_upload", func() queue.JobInterface { return &ProcessUploadJob{} })
+	queue.RegisterJob("cleanup", func() queue.JobInterface { return &CleanupJob{} })
+}
+
 // ExampleJob 示例任务
 type ExampleJob struct {
 	queue.BaseJob
@@ -33,10 +42,12 @@ func NewExampleJob(message string) *ExampleJob {
 }
 
 // Handle 处理任务
-func (j *ExampleJob) Handle() error {
+func (j *ExampleJob) HandleContext(ctx context.Context) error {
 	// 模拟任务处理
 	fmt.Printf("Processing example job: %s\n", j.Message)
-	time.Sleep(2 * time.Second)
+	if err := sleep(ctx, 2*time.Second); err != nil {
+		return err
+	}
 	fmt.Printf("Example job completed: %s\n", j.Message)
 	return nil
 }
@@ -70,14 +81,26 @@ func NewSendWelcomeEmailJob(email, name string) *SendWelcomeEmailJob {
 }
 
 // Handle 处理任务
-func (j *SendWelcomeEmailJob) Handle() error {
+func (j *SendWelcomeEmailJob) HandleContext(ctx context.Context) error {
 	// 模拟发送邮件
 	fmt.Printf("Sending welcome email to %s (%s)\n", j.Name, j.Email)
-	time.Sleep(1 * time.Second)
+	if err := sleep(ctx, 1*time.Second); err != nil {
+		return err
+	}
 	fmt.Printf("Welcome email sent to %s\n", j.Email)
 	return nil
 }
 
+// UniqueID 同一邮箱的欢迎邮件在发送完成前只入队一次
+func (j *SendWelcomeEmailJob) UniqueID() string {
+	return j.Email
+}
+
+// Throttle 每分钟最多发送60封欢迎邮件
+func (j *SendWelcomeEmailJob) Throttle() queue.Throttle {
+	return queue.Throttle{Limit: 60, Per: time.Minute}
+}
+
 // ProcessUploadJob 处理上传文件任务
 type ProcessUploadJob struct {
 	queue.BaseJob
@@ -107,10 +130,12 @@ func NewProcessUploadJob(fileID, fileName string, fileSize int64) *ProcessUpload
 }
 
 // Handle 处理任务
-func (j *ProcessUploadJob) Handle() error {
+func (j *ProcessUploadJob) HandleContext(ctx context.Context) error {
 	// 模拟处理上传文件
 	fmt.Printf("Processing uploaded file: %s (ID: %s, Size: %d bytes)\n", j.FileName, j.FileID, j.FileSize)
-	time.Sleep(5 * time.Second)
+	if err := sleep(ctx, 5*time.Second); err != nil {
+		return err
+	}
 	fmt.Printf("File processing completed: %s\n", j.FileName)
 	return nil
 }
@@ -146,10 +171,22 @@ func NewCleanupJob(target string, options json.RawMessage, startTime, endTime ti
 }
 
 // Handle 处理任务
-func (j *CleanupJob) Handle() error {
+func (j *CleanupJob) HandleContext(ctx context.Context) error {
 	// 模拟清理操作
 	fmt.Printf("Cleaning up %s from %s to %s\n", j.Target, j.StartTime.Format(time.RFC3339), j.EndTime.Format(time.RFC3339))
-	time.Sleep(3 * time.Second)
+	if err := sleep(ctx, 3*time.Second); err != nil {
+		return err
+	}
 	fmt.Printf("Cleanup completed for %s\n", j.Target)
 	return nil
 }
+
+// sleep 模拟耗时操作，任务被取消时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
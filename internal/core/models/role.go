@@ -41,17 +41,18 @@ func (s *StringSlice) Scan(value interface{}) error {
 
 // Role represents a user role in the system
 type Role struct {
-	ID          uint           `json:"id" gorm:"primarykey"`
-	Name        string         `json:"name" gorm:"size:50;not null;comment:'角色名称'"`
-	Code        string         `json:"code" gorm:"size:50;not null;unique;comment:'角色编码'"`
-	Description string         `json:"description" gorm:"size:255;comment:'角色描述'"`
-	Status      int            `json:"status" gorm:"default:1;comment:'状态：0-禁用，1-启用'"`
-	PermList    StringSlice    `json:"perm_list" gorm:"type:json"`
-	CreatedAt   CustomTime     `json:"created_at" gorm:"type:timestamp"`
-	UpdatedAt   CustomTime     `json:"updated_at" gorm:"type:timestamp"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index;type:timestamp"`
-	Users       []User         `json:"users,omitempty" gorm:"many2many:user_roles;"`
-	Menus       []Menu         `json:"menus,omitempty" gorm:"many2many:role_menus;"`
+	ID               uint           `json:"id" gorm:"primarykey"`
+	Name             string         `json:"name" gorm:"size:50;not null;comment:'角色名称'"`
+	Code             string         `json:"code" gorm:"size:50;not null;unique;comment:'角色编码'"`
+	Description      string         `json:"description" gorm:"size:255;comment:'角色描述'"`
+	Status           int            `json:"status" gorm:"default:1;comment:'状态：0-禁用，1-启用'"`
+	PermList         StringSlice    `json:"perm_list" gorm:"type:json"`
+	RequireTwoFactor bool           `json:"require_two_factor" gorm:"not null;default:false;comment:'该角色的用户必须启用两步验证'"`
+	CreatedAt        CustomTime     `json:"created_at" gorm:"type:timestamp"`
+	UpdatedAt        CustomTime     `json:"updated_at" gorm:"type:timestamp"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index;type:timestamp"`
+	Users            []User         `json:"users,omitempty" gorm:"many2many:user_roles;"`
+	Menus            []Menu         `json:"menus,omitempty" gorm:"many2many:role_menus;"`
 }
 
 // TableName specifies the table name for Role model
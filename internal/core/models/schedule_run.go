@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Schedule run statuses
+const (
+	ScheduleRunSuccess = "success"
+	ScheduleRunFailed  = "failed"
+)
+
+// ScheduleRun represents one execution of a scheduled task
+type ScheduleRun struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	TaskName   string    `gorm:"size:100;index" json:"task_name"`
+	Host       string    `gorm:"size:100" json:"host"`
+	Status     string    `gorm:"size:20;index" json:"status"`
+	Error      string    `gorm:"type:text" json:"error"`
+	Output     string    `gorm:"type:mediumtext" json:"output"`
+	StartedAt  time.Time `gorm:"type:timestamp;index;not null" json:"started_at"`
+	FinishedAt time.Time `gorm:"type:timestamp;not null" json:"finished_at"`
+	Duration   int64     `json:"duration"` // milliseconds
+}
+
+// TableName returns the table name
+func (ScheduleRun) TableName() string {
+	return "schedule_runs"
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ScheduledTask is a scheduled command managed from the admin panel
+type ScheduledTask struct {
+	BaseModel
+	Command     string   `json:"command" gorm:"size:100;not null"`
+	Args        []string `json:"args" gorm:"type:text;serializer:json"`
+	Expression  string   `json:"expression" gorm:"size:100;not null"`
+	Timezone    string   `json:"timezone" gorm:"size:64"`
+	Enabled     bool     `json:"enabled" gorm:"not null;index"`
+	Unique      bool     `json:"unique" gorm:"not null"`
+	Description string   `json:"description" gorm:"size:255"`
+	// RunRequestedAt is set by "run now" until a scheduler picks the request up
+	RunRequestedAt *time.Time `json:"run_requested_at" gorm:"type:timestamp;index"`
+}
+
+func (ScheduledTask) TableName() string {
+	return "scheduled_tasks"
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// UserSession is a refresh token issued to a user. Refreshing rotates the
+// token into a new row of the same family, a family is one signed in client.
+type UserSession struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	UserID        uint       `gorm:"index;not null" json:"user_id"`
+	FamilyID      string     `gorm:"size:36;index;not null" json:"family_id"`
+	TokenHash     string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	IP            string     `gorm:"size:45" json:"ip"`
+	UserAgent     string     `gorm:"size:255" json:"user_agent"`
+	ExpiresAt     time.Time  `gorm:"type:timestamp;not null" json:"expires_at"`      // end of the absolute lifetime of the family
+	IdleExpiresAt time.Time  `gorm:"type:timestamp;not null" json:"idle_expires_at"` // the token expires when not used before
+	RotatedAt     *time.Time `gorm:"type:timestamp" json:"rotated_at"`
+	RevokedAt     *time.Time `gorm:"type:timestamp;index" json:"revoked_at"`
+	LastSeenAt    *time.Time `gorm:"type:timestamp" json:"last_seen_at"` // last authenticated request, written at most once a minute
+	CreatedAt     time.Time  `gorm:"type:timestamp" json:"created_at"`
+}
+
+// TableName returns the table name
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
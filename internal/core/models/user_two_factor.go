@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// UserTwoFactor is the TOTP secret of a user. Two-factor authentication is
+// on once the enrolment is confirmed with a first code.
+type UserTwoFactor struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	UserID       uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	Secret       string     `gorm:"size:64;not null" json:"-"`
+	ConfirmedAt  *time.Time `gorm:"type:timestamp" json:"confirmed_at"`
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"` // time step of the last accepted code, codes are accepted once
+	CreatedAt    time.Time  `gorm:"type:timestamp" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"type:timestamp" json:"updated_at"`
+}
+
+// TableName returns the table name
+func (UserTwoFactor) TableName() string {
+	return "user_two_factors"
+}
+
+// Enabled reports whether the enrolment was confirmed
+func (t *UserTwoFactor) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// UserRecoveryCode is a one-time code signing a user in without the TOTP
+// device, only its hash is stored
+type UserRecoveryCode struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`
+	UsedAt    *time.Time `gorm:"type:timestamp" json:"used_at"`
+	CreatedAt time.Time  `gorm:"type:timestamp" json:"created_at"`
+}
+
+// TableName returns the table name
+func (UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
@@ -2,6 +2,9 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"time"
 
 	"app/internal/core/models"
 
@@ -109,3 +112,45 @@ func (r *LogRepository) GetOperationLogsByUserID(ctx context.Context, userID uin
 		Find(&logs).Error
 	return logs, err
 }
+
+// loginLogKeyColumns are the columns login logs can be counted by
+var loginLogKeyColumns = map[string]bool{"username": true, "ip": true}
+
+// CountLoginLogs counts the login logs of a username or IP with one of the
+// messages since a time, and returns the login time of the latest one
+func (r *LogRepository) CountLoginLogs(ctx context.Context, column, value string, messages []string, since time.Time) (int64, *time.Time, error) {
+	if !loginLogKeyColumns[column] {
+		return 0, nil, fmt.Errorf("login logs cannot be counted by %s", column)
+	}
+
+	var stats struct {
+		Count  int64
+		Latest sql.NullTime
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.LoginLog{}).
+		Select("COUNT(*) AS count, MAX(login_time) AS latest").
+		Where(column+" = ? AND message IN ? AND login_time >= ?", value, messages, since).
+		Scan(&stats).Error
+	if err != nil || !stats.Latest.Valid {
+		return stats.Count, nil, err
+	}
+	return stats.Count, &stats.Latest.Time, nil
+}
+
+// ListLoginLogKeys lists the usernames or IPs with at least minCount login logs
+// with one of the messages since a time
+func (r *LogRepository) ListLoginLogKeys(ctx context.Context, column string, messages []string, since time.Time, minCount int64) ([]string, error) {
+	if !loginLogKeyColumns[column] {
+		return nil, fmt.Errorf("login logs cannot be counted by %s", column)
+	}
+
+	var keys []string
+	err := r.db.WithContext(ctx).
+		Model(&models.LoginLog{}).
+		Where(column+" <> '' AND message IN ? AND login_time >= ?", messages, since).
+		Group(column).
+		Having("COUNT(*) >= ?", minCount).
+		Pluck(column, &keys).Error
+	return keys, err
+}
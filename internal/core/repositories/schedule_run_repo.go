@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"app/internal/core/models"
+
+	"gorm.io/gorm"
+)
+
+type ScheduleRunRepository struct {
+	*BaseRepository
+}
+
+func NewScheduleRunRepository(db *gorm.DB) *ScheduleRunRepository {
+	return &ScheduleRunRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// CreateRun creates a new schedule run record
+func (r *ScheduleRunRepository) CreateRun(ctx context.Context, run *models.ScheduleRun) error {
+	return r.Create(ctx, run)
+}
+
+// FindRunByID retrieves a schedule run by its ID
+func (r *ScheduleRunRepository) FindRunByID(ctx context.Context, id uint) (*models.ScheduleRun, error) {
+	var run models.ScheduleRun
+	if err := r.FindByID(ctx, id, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListRuns retrieves a paginated list of schedule runs, newest first
+func (r *ScheduleRunRepository) ListRuns(ctx context.Context, pagination *models.Pagination, query map[string]interface{}) ([]models.ScheduleRun, error) {
+	var runs []models.ScheduleRun
+	db := r.db.WithContext(ctx).Model(&models.ScheduleRun{})
+
+	// Apply query conditions
+	for key, value := range query {
+		if value != nil && value != "" {
+			db = db.Where(key, value)
+		}
+	}
+
+	// Get total count
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, err
+	}
+
+	// The output can be large, it is only returned by FindRunByID
+	err := db.Omit("output").
+		Order("started_at DESC").
+		Offset(pagination.GetOffset()).
+		Limit(pagination.GetLimit()).
+		Find(&runs).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// DeleteRunsBefore deletes the runs started before the given time
+func (r *ScheduleRunRepository) DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("started_at < ?", before).Delete(&models.ScheduleRun{})
+	return result.RowsAffected, result.Error
+}
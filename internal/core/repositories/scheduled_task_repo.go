@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"app/internal/core/models"
+
+	"gorm.io/gorm"
+)
+
+type ScheduledTaskRepository struct {
+	*BaseRepository
+}
+
+func NewScheduledTaskRepository(db *gorm.DB) *ScheduledTaskRepository {
+	return &ScheduledTaskRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// CreateTask creates a new scheduled task
+func (r *ScheduledTaskRepository) CreateTask(ctx context.Context, task *models.ScheduledTask) error {
+	return r.Create(ctx, task)
+}
+
+// UpdateTask saves all fields of a scheduled task
+func (r *ScheduledTaskRepository) UpdateTask(ctx context.Context, task *models.ScheduledTask) error {
+	return r.Update(ctx, task)
+}
+
+// DeleteTask soft deletes a scheduled task
+func (r *ScheduledTaskRepository) DeleteTask(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ScheduledTask{}, id).Error
+}
+
+// FindTaskByID retrieves a scheduled task by its ID
+func (r *ScheduledTaskRepository) FindTaskByID(ctx context.Context, id uint) (*models.ScheduledTask, error) {
+	var task models.ScheduledTask
+	if err := r.FindByID(ctx, id, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ListTasks retrieves a paginated list of scheduled tasks
+func (r *ScheduledTaskRepository) ListTasks(ctx context.Context, pagination *models.Pagination) ([]models.ScheduledTask, error) {
+	var tasks []models.ScheduledTask
+	db := r.db.WithContext(ctx).Model(&models.ScheduledTask{})
+
+	if err := db.Count(&pagination.Total).Error; err != nil {
+		return nil, err
+	}
+
+	err := db.Order("id ASC").
+		Offset(pagination.GetOffset()).
+		Limit(pagination.GetLimit()).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// ListEnabledTasks retrieves all enabled scheduled tasks
+func (r *ScheduledTaskRepository) ListEnabledTasks(ctx context.Context) ([]models.ScheduledTask, error) {
+	var tasks []models.ScheduledTask
+	err := r.db.WithContext(ctx).Where("enabled = ?", true).Order("id ASC").Find(&tasks).Error
+	return tasks, err
+}
+
+// ListRunRequests retrieves the tasks with a pending run request
+func (r *ScheduledTaskRepository) ListRunRequests(ctx context.Context) ([]models.ScheduledTask, error) {
+	var tasks []models.ScheduledTask
+	err := r.db.WithContext(ctx).Where("run_requested_at IS NOT NULL").Find(&tasks).Error
+	return tasks, err
+}
+
+// SetRunRequest marks a task to be run by the next scheduler sync, without touching updated_at
+func (r *ScheduledTaskRepository) SetRunRequest(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.ScheduledTask{}).
+		Where("id = ?", id).
+		UpdateColumn("run_requested_at", at).Error
+}
+
+// ClearRunRequest clears a run request, it returns false when another scheduler cleared it first
+func (r *ScheduledTaskRepository) ClearRunRequest(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.ScheduledTask{}).
+		Where("id = ? AND run_requested_at = ?", id, at).
+		UpdateColumn("run_requested_at", nil)
+	return result.RowsAffected == 1, result.Error
+}
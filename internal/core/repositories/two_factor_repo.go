@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"app/internal/core/models"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository struct {
+	*BaseRepository
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// FindTwoFactor retrieves the TOTP secret of a user, confirmed or not
+func (r *TwoFactorRepository) FindTwoFactor(ctx context.Context, userID uint) (*models.UserTwoFactor, error) {
+	var twoFactor models.UserTwoFactor
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// SaveTwoFactor stores a new unconfirmed secret, replacing the previous one of the user
+func (r *TwoFactorRepository) SaveTwoFactor(ctx context.Context, twoFactor *models.UserTwoFactor) error {
+	return r.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", twoFactor.UserID).Delete(&models.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(twoFactor).Error
+	})
+}
+
+// EnableTwoFactor confirms the secret of a user with the code accepted at
+// step and stores the recovery codes. It returns false when the secret was
+// confirmed or replaced meanwhile.
+func (r *TwoFactorRepository) EnableTwoFactor(ctx context.Context, twoFactor *models.UserTwoFactor, step int64, codeHashes []string, at time.Time) (bool, error) {
+	enabled := false
+	err := r.Transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserTwoFactor{}).
+			Where("id = ? AND confirmed_at IS NULL", twoFactor.ID).
+			Updates(map[string]interface{}{"confirmed_at": at, "last_used_step": step})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		enabled = true
+		return replaceRecoveryCodes(tx, twoFactor.UserID, codeHashes, at)
+	})
+	return enabled, err
+}
+
+// DeleteTwoFactor turns two-factor authentication off for a user
+func (r *TwoFactorRepository) DeleteTwoFactor(ctx context.Context, userID uint) error {
+	return r.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error
+	})
+}
+
+// UseTwoFactorStep records the time step of an accepted code. It returns
+// false when a code of the same or a later step was accepted already.
+func (r *TwoFactorRepository) UseTwoFactorStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.UserTwoFactor{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		UpdateColumn("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReplaceRecoveryCodes replaces the recovery codes of a user
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	return r.Transaction(ctx, func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes, time.Now())
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code of a user as used, it
+// returns false when there is no such code
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		UpdateColumn("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountRecoveryCodes counts the unused recovery codes of a user
+func (r *TwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// RequiresTwoFactor reports whether an active role of a user makes
+// two-factor authentication mandatory
+func (r *TwoFactorRepository) RequiresTwoFactor(ctx context.Context, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("user_roles").
+		Joins("JOIN roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ? AND roles.require_two_factor = ? AND roles.status = 1 AND roles.deleted_at IS NULL", userID, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint, codeHashes []string, at time.Time) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]models.UserRecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, models.UserRecoveryCode{UserID: userID, CodeHash: hash, CreatedAt: at})
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
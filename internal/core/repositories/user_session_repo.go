@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"app/internal/core/models"
+
+	"gorm.io/gorm"
+)
+
+type UserSessionRepository struct {
+	*BaseRepository
+}
+
+func NewUserSessionRepository(db *gorm.DB) *UserSessionRepository {
+	return &UserSessionRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// CreateSession stores a new refresh token
+func (r *UserSessionRepository) CreateSession(ctx context.Context, session *models.UserSession) error {
+	return r.Create(ctx, session)
+}
+
+// FindSessionByTokenHash retrieves a refresh token by its hash, rotated and revoked tokens included
+func (r *UserSessionRepository) FindSessionByTokenHash(ctx context.Context, hash string) (*models.UserSession, error) {
+	var session models.UserSession
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RotateSession marks a token as rotated and stores the token replacing it.
+// It returns false when the token was already rotated or revoked meanwhile.
+func (r *UserSessionRepository) RotateSession(ctx context.Context, current, next *models.UserSession, at time.Time) (bool, error) {
+	rotated := false
+	err := r.Transaction(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserSession{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", current.ID).
+			UpdateColumn("rotated_at", at)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		rotated = true
+		return tx.Create(next).Error
+	})
+	return rotated, err
+}
+
+// RevokeSessionFamily revokes every token of a session
+func (r *UserSessionRepository) RevokeSessionFamily(ctx context.Context, familyID string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.UserSession{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		UpdateColumn("revoked_at", at).Error
+}
+
+// RevokeUserSessions revokes every token of every session of a user
+func (r *UserSessionRepository) RevokeUserSessions(ctx context.Context, userID uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumn("revoked_at", at).Error
+}
+
+// ListActiveSessions retrieves the current token of every session of a user
+// that is neither revoked nor expired, the most recently active first
+func (r *UserSessionRepository) ListActiveSessions(ctx context.Context, userID uint, now time.Time) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND rotated_at IS NULL AND revoked_at IS NULL", userID).
+		Where("expires_at > ? AND idle_expires_at > ?", now, now).
+		Order("COALESCE(last_seen_at, created_at) DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// SessionStartTimes returns when each session was signed in, the issue time
+// of the first token of the session
+func (r *UserSessionRepository) SessionStartTimes(ctx context.Context, familyIDs []string) (map[string]time.Time, error) {
+	var rows []struct {
+		FamilyID  string
+		StartedAt time.Time
+	}
+	if len(familyIDs) > 0 {
+		err := r.db.WithContext(ctx).Model(&models.UserSession{}).
+			Select("family_id, MIN(created_at) AS started_at").
+			Where("family_id IN ?", familyIDs).
+			Group("family_id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	startTimes := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		startTimes[row.FamilyID] = row.StartedAt
+	}
+	return startTimes, nil
+}
+
+// TouchSession records activity on the current token of a session
+func (r *UserSessionRepository) TouchSession(ctx context.Context, familyID string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.UserSession{}).
+		Where("family_id = ? AND rotated_at IS NULL AND revoked_at IS NULL", familyID).
+		UpdateColumn("last_seen_at", at).Error
+}
@@ -2,37 +2,94 @@ package services
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"log"
+	"math"
 	"strings"
 	"time"
 
 	"app/internal/config"
 	"app/internal/core/models"
+	"app/pkg/denylist"
+	"app/pkg/locker"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUserInactive       = errors.New("user is inactive")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrUserInactive        = errors.New("user is inactive")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidMFAToken     = errors.New("invalid mfa token")
 )
 
+// Refresh token lifetimes used when the JWT configuration leaves them unset
+const (
+	defaultRefreshIdleTime     = 7 * 24 * time.Hour
+	defaultRefreshAbsoluteTime = 30 * 24 * time.Hour
+)
+
+// mfaChallengeLifetime is how long the second step of a login may take
+const mfaChallengeLifetime = 5 * time.Minute
+
+// Purposes of an MFA challenge token
+const (
+	mfaChallengeVerify = "verify" // the user enters a code of the enrolled device
+	mfaChallengeSetup  = "setup"  // a role requires two-factor authentication the user has yet to enrol
+)
+
+// sessionSeenInterval is how often the last activity of a session is written
+const sessionSeenInterval = time.Minute
+
 type AuthService struct {
-	userRepo UserRepository
-	logSvc   *LogService
-	config   *config.Config
+	userRepo    UserRepository
+	sessionRepo UserSessionRepository
+	denylist    denylist.Denylist
+	seenLocker  locker.Locker
+	twoFactor   *TwoFactorService
+	loginLock   *LoginLockService
+	logSvc      *LogService
+	config      *config.Config
 }
 
-func NewAuthService(userRepo UserRepository, logSvc *LogService, config *config.Config) *AuthService {
+func NewAuthService(userRepo UserRepository, sessionRepo UserSessionRepository, denylist denylist.Denylist, logSvc *LogService, config *config.Config) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
-		logSvc:   logSvc,
-		config:   config,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		denylist:    denylist,
+		logSvc:      logSvc,
+		config:      config,
 	}
 }
 
+// SetSessionLocker sets the locker throttling the last activity writes of
+// sessions, without it every request is written
+func (s *AuthService) SetSessionLocker(seenLocker locker.Locker) {
+	s.seenLocker = seenLocker
+}
+
+// SetTwoFactorService sets the two-factor service, without it logins take
+// a single step
+func (s *AuthService) SetTwoFactorService(twoFactor *TwoFactorService) {
+	s.twoFactor = twoFactor
+}
+
+// SetLoginLockService sets the login lockout, without it failed logins are
+// not limited
+func (s *AuthService) SetLoginLockService(loginLock *LoginLockService) {
+	s.loginLock = loginLock
+}
+
 // IsSuperAdmin checks if a user ID is in the super admin list
 func (s *AuthService) IsSuperAdmin(userID uint) bool {
 	if s.config == nil {
@@ -57,12 +114,46 @@ type LoginRequest struct {
 	Password    string `json:"password" binding:"required"`
 	CaptchaID   string `json:"captcha_id" binding:"required"`
 	CaptchaCode string `json:"captcha_code" binding:"required"`
+	IP          string `json:"-"`
+	UserAgent   string `json:"-"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+	IP           string `json:"-"`
+	UserAgent    string `json:"-"`
+}
+
+// TwoFactorLoginRequest completes a login with a TOTP or recovery code
+type TwoFactorLoginRequest struct {
+	MFAToken  string `json:"mfa_token" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// TwoFactorSetupRequest starts the enrolment a role requires during a login
+type TwoFactorSetupRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// LoginResponse holds the tokens of a completed login. When a second factor
+// is needed it holds the MFA challenge token to complete the login with instead.
+type LoginResponse struct {
+	*TokenResponse
+	MFARequired      bool     `json:"mfa_required,omitempty"`
+	MFASetupRequired bool     `json:"mfa_setup_required,omitempty"`
+	MFAToken         string   `json:"mfa_token,omitempty"`
+	MFAExpiresIn     int      `json:"mfa_expires_in,omitempty"`
+	RecoveryCodes    []string `json:"recovery_codes,omitempty"`
 }
 
 type TokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int    `json:"expires_in"`
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
 }
 
 // ValidateToken validates a JWT token and returns its claims
@@ -87,52 +178,114 @@ func (s *AuthService) ValidateToken(tokenString string) (jwt.MapClaims, error) {
 
 // GetUserFromClaims retrieves user information from JWT claims
 func (s *AuthService) GetUserFromClaims(ctx context.Context, claims jwt.MapClaims) (*models.User, error) {
-	// Get user_id from claims with type checking
+	userID, err := userIDFromClaims(claims)
+	if err != nil {
+		log.Printf("[ERROR] %v", err)
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] Getting user from claims, user_id: %d", userID)
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to find user by ID %d: %v", userID, err)
+		return nil, err
+	}
+
+	// Set IsSuperAdmin field
+	user.IsSuperAdmin = s.IsSuperAdmin(user.ID)
+	log.Printf("[DEBUG] User %d IsSuperAdmin: %v", user.ID, user.IsSuperAdmin)
+
+	return user, nil
+}
+
+// userIDFromClaims reads the user_id claim
+func userIDFromClaims(claims jwt.MapClaims) (uint, error) {
 	userIDValue, exists := claims["user_id"]
 	if !exists {
-		log.Printf("[ERROR] user_id not found in claims")
-		return nil, errors.New("user_id not found in claims")
+		return 0, errors.New("user_id not found in claims")
 	}
 
-	var userID uint
 	switch v := userIDValue.(type) {
 	case float64:
-		userID = uint(v)
+		return uint(v), nil
 	case float32:
-		userID = uint(v)
+		return uint(v), nil
 	case int:
-		userID = uint(v)
+		return uint(v), nil
 	case int64:
-		userID = uint(v)
+		return uint(v), nil
 	case uint:
-		userID = v
+		return v, nil
 	case uint64:
-		userID = uint(v)
+		return uint(v), nil
 	default:
-		log.Printf("[ERROR] invalid user_id type in claims: %T", userIDValue)
-		return nil, errors.New("invalid user_id type in claims")
+		return 0, fmt.Errorf("invalid user_id type in claims: %T", userIDValue)
 	}
+}
 
-	log.Printf("[DEBUG] Getting user from claims, user_id: %d", userID)
-	user, err := s.userRepo.FindByID(ctx, userID)
-	if err != nil {
-		log.Printf("[ERROR] Failed to find user by ID %d: %v", userID, err)
-		return nil, err
+// issuedAtClaim returns the iat claim of a token issued at now. It keeps the
+// milliseconds, a NumericDate may be fractional, so the denylist can tell a
+// token issued right after a revocation of its user from the revoked ones.
+func issuedAtClaim(now time.Time) float64 {
+	return float64(now.UnixMilli()) / 1000
+}
+
+// claimTime reads a NumericDate claim such as exp or iat
+func claimTime(claims jwt.MapClaims, name string) time.Time {
+	switch v := claims[name].(type) {
+	case float64:
+		return time.UnixMilli(int64(math.Round(v * 1000)))
+	case int64:
+		return time.Unix(v, 0)
 	}
+	return time.Time{}
+}
 
-	// Set IsSuperAdmin field
-	user.IsSuperAdmin = s.IsSuperAdmin(user.ID)
-	log.Printf("[DEBUG] User %d IsSuperAdmin: %v", user.ID, user.IsSuperAdmin)
+// IsTokenRevoked reports whether an access token was revoked by a logout or
+// by a change to its user
+func (s *AuthService) IsTokenRevoked(ctx context.Context, claims jwt.MapClaims) (bool, error) {
+	if s.denylist == nil {
+		return false, nil
+	}
 
-	return user, nil
+	userID, err := userIDFromClaims(claims)
+	if err != nil {
+		return false, err
+	}
+	tokenID, _ := claims["jti"].(string)
+	sessionID, _ := claims["sid"].(string)
+	return s.denylist.IsRevoked(ctx, userID, claimTime(claims, "iat"), tokenID, sessionID)
+}
+
+// RevokeUserTokens revokes the access tokens issued to a user so far. With
+// endSessions the refresh tokens are revoked too and the user has to sign in
+// again, otherwise the client obtains new access tokens through a refresh.
+func (s *AuthService) RevokeUserTokens(ctx context.Context, userID uint, endSessions bool) error {
+	now := time.Now()
+	if s.denylist != nil {
+		// No access token issued before now outlives its configured lifetime
+		ttl := time.Duration(s.config.JWT.ExpireTime) * time.Second
+		if err := s.denylist.RevokeUser(ctx, userID, now, ttl); err != nil {
+			return err
+		}
+	}
+	if endSessions {
+		return s.sessionRepo.RevokeUserSessions(ctx, userID, now)
+	}
+	return nil
 }
 
-func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*TokenResponse, error) {
+func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+	// A locked username or IP is refused before the password is checked
+	if err := s.checkLoginLock(ctx, 0, req.Username, req.IP, req.UserAgent); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByUsername(ctx, req.Username)
 	if err != nil {
 		// Record failed login attempt
 		if s.logSvc != nil {
-			s.logSvc.RecordLoginLog(ctx, 0, req.Username, "", "", 0, "user not found")
+			s.logSvc.RecordLoginLog(ctx, 0, req.Username, req.IP, req.UserAgent, 0, "user not found")
 		}
 		return nil, ErrInvalidCredentials
 	}
@@ -140,7 +293,7 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*TokenRespo
 	if user.Status == 0 {
 		// Record failed login attempt for inactive user
 		if s.logSvc != nil {
-			s.logSvc.RecordLoginLog(ctx, user.ID, user.Username, "", "", 0, "user is inactive")
+			s.logSvc.RecordLoginLog(ctx, user.ID, user.Username, req.IP, req.UserAgent, 0, "user is inactive")
 		}
 		return nil, ErrUserInactive
 	}
@@ -148,11 +301,112 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*TokenRespo
 	if !s.validatePassword(user.Password, req.Password) {
 		// Record failed login attempt for invalid password
 		if s.logSvc != nil {
-			s.logSvc.RecordLoginLog(ctx, user.ID, user.Username, "", "", 0, "invalid password")
+			s.logSvc.RecordLoginLog(ctx, user.ID, user.Username, req.IP, req.UserAgent, 0, "invalid password")
 		}
 		return nil, ErrInvalidCredentials
 	}
 
+	// A second factor completes the login when it is on or a role requires it
+	if s.twoFactor != nil {
+		enabled, required, err := s.twoFactor.State(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if enabled || required {
+			purpose := mfaChallengeVerify
+			if !enabled {
+				purpose = mfaChallengeSetup
+			}
+			mfaToken, err := s.newMFAChallenge(user, purpose)
+			if err != nil {
+				return nil, err
+			}
+			return &LoginResponse{
+				MFARequired:      enabled,
+				MFASetupRequired: !enabled,
+				MFAToken:         mfaToken,
+				MFAExpiresIn:     int(mfaChallengeLifetime.Seconds()),
+			}, nil
+		}
+	}
+
+	tokens, err := s.completeLogin(ctx, user, req.IP, req.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{TokenResponse: tokens}, nil
+}
+
+// LoginTwoFactor completes a login with a TOTP or recovery code. When a role
+// required an enrolment it confirms the enrolment with the code and returns
+// the recovery codes too.
+func (s *AuthService) LoginTwoFactor(ctx context.Context, req *TwoFactorLoginRequest) (*LoginResponse, error) {
+	user, purpose, claims, err := s.parseMFAChallenge(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	if s.twoFactor == nil {
+		return nil, ErrInvalidMFAToken
+	}
+	if err := s.checkLoginLock(ctx, user.ID, user.Username, req.IP, req.UserAgent); err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	if purpose == mfaChallengeSetup {
+		recoveryCodes, err = s.twoFactor.Confirm(ctx, user.ID, req.Code)
+	} else {
+		err = s.twoFactor.Verify(ctx, user.ID, req.Code)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) && s.logSvc != nil {
+			s.logSvc.RecordLoginLog(ctx, user.ID, user.Username, req.IP, req.UserAgent, 0, "invalid two-factor code")
+		}
+		return nil, err
+	}
+
+	// The challenge completes a single login
+	if tokenID, _ := claims["jti"].(string); tokenID != "" && s.denylist != nil {
+		if err := s.denylist.RevokeToken(ctx, tokenID, time.Until(claimTime(claims, "exp"))); err != nil {
+			return nil, err
+		}
+	}
+
+	tokens, err := s.completeLogin(ctx, user, req.IP, req.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{TokenResponse: tokens, RecoveryCodes: recoveryCodes}, nil
+}
+
+// SetupTwoFactorLogin starts the enrolment a role of the user requires
+// before the login can be completed
+func (s *AuthService) SetupTwoFactorLogin(ctx context.Context, req *TwoFactorSetupRequest) (*TwoFactorEnrolment, error) {
+	user, purpose, _, err := s.parseMFAChallenge(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	if purpose != mfaChallengeSetup || s.twoFactor == nil {
+		return nil, ErrInvalidMFAToken
+	}
+	return s.twoFactor.Enrol(ctx, user)
+}
+
+// checkLoginLock refuses a login attempt while the username or the IP is
+// locked after too many failures
+func (s *AuthService) checkLoginLock(ctx context.Context, userID uint, username, ip, userAgent string) error {
+	if s.loginLock == nil {
+		return nil
+	}
+	err := s.loginLock.Check(ctx, username, ip)
+	if errors.Is(err, ErrLoginLocked) && s.logSvc != nil {
+		s.logSvc.RecordLoginLog(ctx, userID, username, ip, userAgent, 0, loginLockedMessage)
+	}
+	return err
+}
+
+// completeLogin starts a session for a user who passed every login step
+func (s *AuthService) completeLogin(ctx context.Context, user *models.User, ip, userAgent string) (*TokenResponse, error) {
 	// Set IsSuperAdmin field
 	user.IsSuperAdmin = s.IsSuperAdmin(user.ID)
 
@@ -162,22 +416,83 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*TokenRespo
 		log.Printf("[WARN] Failed to update last login time: %v", err)
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(user)
+	// Start a session and generate the tokens
+	resp, err := s.issueTokens(ctx, user, nil, ip, userAgent)
 	if err != nil {
 		return nil, err
 	}
 
 	// Record successful login
 	if s.logSvc != nil {
-		s.logSvc.RecordLoginLog(ctx, user.ID, user.Username, "", "", 1, "login successful")
+		s.logSvc.RecordLoginLog(ctx, user.ID, user.Username, ip, userAgent, 1, "login successful")
 	}
 
-	return &TokenResponse{
-		AccessToken: token,
-		TokenType:   "Bearer",
-		ExpiresIn:   s.config.JWT.ExpireTime, // ExpireTime is already in seconds
-	}, nil
+	return resp, nil
+}
+
+// newMFAChallenge signs the token a login continues with after the password
+// was checked. It is signed with a key of its own so it is never mistaken
+// for an access token.
+func (s *AuthService) newMFAChallenge(user *models.User, purpose string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"jti":     uuid.NewString(),
+		"user_id": user.ID,
+		"purpose": purpose,
+		"iat":     issuedAtClaim(now),
+		"exp":     now.Add(mfaChallengeLifetime).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.mfaChallengeKey())
+}
+
+// parseMFAChallenge validates an MFA challenge token and loads its user
+func (s *AuthService) parseMFAChallenge(ctx context.Context, tokenString string) (*models.User, string, jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return s.mfaChallengeKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, "", nil, ErrInvalidMFAToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, "", nil, ErrInvalidMFAToken
+	}
+	purpose, _ := claims["purpose"].(string)
+	userID, err := userIDFromClaims(claims)
+	if err != nil || (purpose != mfaChallengeVerify && purpose != mfaChallengeSetup) {
+		return nil, "", nil, ErrInvalidMFAToken
+	}
+
+	// Used challenges and challenges of users signed out since are revoked
+	revoked, err := s.IsTokenRevoked(ctx, claims)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if revoked {
+		return nil, "", nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", nil, ErrInvalidMFAToken
+		}
+		return nil, "", nil, err
+	}
+	if user.Status != 1 {
+		return nil, "", nil, ErrUserInactive
+	}
+	return user, purpose, claims, nil
+}
+
+// mfaChallengeKey derives the signing key of MFA challenge tokens from the JWT secret
+func (s *AuthService) mfaChallengeKey() []byte {
+	mac := hmac.New(sha256.New, []byte(s.config.JWT.Secret))
+	mac.Write([]byte("mfa_challenge"))
+	return mac.Sum(nil)
 }
 
 func (s *AuthService) validatePassword(hashedPassword, plainPassword string) bool {
@@ -185,11 +500,16 @@ func (s *AuthService) validatePassword(hashedPassword, plainPassword string) boo
 	return err == nil
 }
 
-func (s *AuthService) generateToken(user *models.User) (string, error) {
+// generateToken signs an access token, sessionID is the family of the refresh token issued with it
+func (s *AuthService) generateToken(user *models.User, sessionID string) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
+		"jti":      uuid.NewString(),
 		"user_id":  user.ID,
 		"username": user.Username,
-		"exp":      time.Now().Add(time.Second * time.Duration(s.config.JWT.ExpireTime)).Unix(),
+		"sid":      sessionID,
+		"iat":      issuedAtClaim(now),
+		"exp":      now.Add(time.Second * time.Duration(s.config.JWT.ExpireTime)).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -214,21 +534,248 @@ func (s *AuthService) HashPassword(password string) (string, error) {
 	return string(hashedBytes), nil
 }
 
-// RefreshToken generates a new access token for the given user ID
-func (s *AuthService) RefreshToken(ctx context.Context, userID uint) (string, error) {
-	user, err := s.userRepo.FindByID(ctx, userID)
+// Refresh exchanges a refresh token for new tokens. The refresh token is
+// rotated, presenting it again revokes the whole session.
+func (s *AuthService) Refresh(ctx context.Context, req *RefreshTokenRequest) (*TokenResponse, error) {
+	session, err := s.sessionRepo.FindSessionByTokenHash(ctx, hashRefreshToken(req.RefreshToken))
 	if err != nil {
-		return "", err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case session.RevokedAt != nil:
+		return nil, ErrInvalidRefreshToken
+	case session.RotatedAt != nil:
+		// Only a copy of the token can be presented after it was rotated,
+		// the user or a thief holds the newer token: end the session for both
+		log.Printf("[WARN] Refresh token reuse detected for user %d, revoking session %s", session.UserID, session.FamilyID)
+		s.revokeSession(ctx, session.FamilyID)
+		return nil, ErrInvalidRefreshToken
+	case now.After(session.ExpiresAt), now.After(session.IdleExpiresAt):
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.revokeSession(ctx, session.FamilyID)
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
 	}
 
 	if user.Status != 1 {
-		return "", ErrUserInactive
+		s.revokeSession(ctx, session.FamilyID)
+		return nil, ErrUserInactive
 	}
 
 	// Set IsSuperAdmin field
 	user.IsSuperAdmin = s.IsSuperAdmin(user.ID)
 
-	return s.generateToken(user)
+	return s.issueTokens(ctx, user, session, req.IP, req.UserAgent)
+}
+
+// issueTokens generates an access token and a refresh token. Without a
+// current token it starts a new session, otherwise it rotates current.
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User, current *models.UserSession, ip, userAgent string) (*TokenResponse, error) {
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	next := &models.UserSession{
+		UserID:     user.ID,
+		TokenHash:  hashRefreshToken(refreshToken),
+		IP:         ip,
+		UserAgent:  userAgent,
+		LastSeenAt: &now,
+		CreatedAt:  now,
+	}
+	if current == nil {
+		next.FamilyID = uuid.NewString()
+		next.ExpiresAt = now.Add(s.refreshLifetime(s.config.JWT.RefreshAbsoluteTime, defaultRefreshAbsoluteTime))
+	} else {
+		next.FamilyID = current.FamilyID
+		next.ExpiresAt = current.ExpiresAt
+	}
+	next.IdleExpiresAt = now.Add(s.refreshLifetime(s.config.JWT.RefreshIdleTime, defaultRefreshIdleTime))
+	if next.IdleExpiresAt.After(next.ExpiresAt) {
+		next.IdleExpiresAt = next.ExpiresAt
+	}
+
+	accessToken, err := s.generateToken(user, next.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		if err := s.sessionRepo.CreateSession(ctx, next); err != nil {
+			return nil, err
+		}
+	} else {
+		rotated, err := s.sessionRepo.RotateSession(ctx, current, next, now)
+		if err != nil {
+			return nil, err
+		}
+		if !rotated {
+			// Another request rotated the same token first
+			log.Printf("[WARN] Concurrent refresh token reuse for user %d, revoking session %s", user.ID, current.FamilyID)
+			s.revokeSession(ctx, current.FamilyID)
+			return nil, ErrInvalidRefreshToken
+		}
+	}
+
+	return &TokenResponse{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        s.config.JWT.ExpireTime, // ExpireTime is already in seconds
+		RefreshExpiresIn: int(next.IdleExpiresAt.Sub(now).Seconds()),
+	}, nil
+}
+
+// refreshLifetime converts a lifetime in seconds from the configuration
+func (s *AuthService) refreshLifetime(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// revokeSession revokes every refresh token of a session and the access
+// tokens issued with them, failures are only logged
+func (s *AuthService) revokeSession(ctx context.Context, sessionID string) {
+	if s.denylist != nil {
+		ttl := time.Duration(s.config.JWT.ExpireTime) * time.Second
+		if err := s.denylist.RevokeToken(ctx, sessionID, ttl); err != nil {
+			log.Printf("[ERROR] Failed to revoke the access tokens of session %s: %v", sessionID, err)
+		}
+	}
+	if err := s.sessionRepo.RevokeSessionFamily(ctx, sessionID, time.Now()); err != nil {
+		log.Printf("[ERROR] Failed to revoke session %s: %v", sessionID, err)
+	}
+}
+
+// SessionInfo describes a signed in device of a user
+type SessionInfo struct {
+	ID         string     `json:"id"`
+	Device     string     `json:"device"`
+	IP         string     `json:"ip"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	Current    bool       `json:"current"`
+}
+
+// ListSessions lists the active sessions of a user, currentSessionID marks
+// the session of the request
+func (s *AuthService) ListSessions(ctx context.Context, userID uint, currentSessionID string) ([]SessionInfo, error) {
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	familyIDs := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		familyIDs = append(familyIDs, session.FamilyID)
+	}
+	startTimes, err := s.sessionRepo.SessionStartTimes(ctx, familyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		createdAt, ok := startTimes[session.FamilyID]
+		if !ok {
+			createdAt = session.CreatedAt
+		}
+		infos = append(infos, SessionInfo{
+			ID:         session.FamilyID,
+			Device:     describeDevice(session.UserAgent),
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  createdAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.FamilyID == currentSessionID,
+		})
+	}
+	return infos, nil
+}
+
+// RevokeUserSession signs a user out of one session
+func (s *AuthService) RevokeUserSession(ctx context.Context, userID uint, sessionID string) error {
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.FamilyID == sessionID {
+			s.revokeSession(ctx, sessionID)
+			return nil
+		}
+	}
+	return ErrSessionNotFound
+}
+
+// RevokeOtherSessions signs a user out of every session but the current one
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID uint, currentSessionID string) error {
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.FamilyID != currentSessionID {
+			s.revokeSession(ctx, session.FamilyID)
+		}
+	}
+	return nil
+}
+
+// TouchSession records activity on a session, at most once per
+// sessionSeenInterval. Failures are only logged.
+func (s *AuthService) TouchSession(ctx context.Context, sessionID string) {
+	if sessionID == "" {
+		return
+	}
+	if s.seenLocker != nil {
+		acquired, err := s.seenLocker.TryLock(ctx, "session_seen:"+sessionID, sessionSeenInterval)
+		if err != nil {
+			log.Printf("[WARN] Failed to throttle the activity of session %s: %v", sessionID, err)
+			return
+		}
+		if !acquired {
+			return
+		}
+	}
+	if err := s.sessionRepo.TouchSession(ctx, sessionID, time.Now()); err != nil {
+		log.Printf("[ERROR] Failed to record the activity of session %s: %v", sessionID, err)
+	}
+}
+
+// newRefreshToken returns a random opaque refresh token
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashRefreshToken returns the hash stored in place of a refresh token
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // GetConfig returns the JWT configuration
@@ -236,8 +783,18 @@ func (s *AuthService) GetConfig() *config.Config {
 	return s.config
 }
 
-// Logout handles user logout and logs the action
-func (s *AuthService) Logout(ctx context.Context, userID uint) error {
+// Logout revokes the access token in claims, ends the session it was issued
+// for and logs the action
+func (s *AuthService) Logout(ctx context.Context, userID uint, claims jwt.MapClaims) error {
+	if tokenID, _ := claims["jti"].(string); tokenID != "" && s.denylist != nil {
+		if err := s.denylist.RevokeToken(ctx, tokenID, time.Until(claimTime(claims, "exp"))); err != nil {
+			return err
+		}
+	}
+	if sessionID, _ := claims["sid"].(string); sessionID != "" {
+		s.revokeSession(ctx, sessionID)
+	}
+
 	// Get user information for logging
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
@@ -251,3 +808,50 @@ func (s *AuthService) Logout(ctx context.Context, userID uint) error {
 
 	return nil
 }
+
+// describeDevice summarizes a user agent as "Browser on OS"
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	var browser string
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"), strings.Contains(userAgent, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	var system string
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		system = "iOS"
+	case strings.Contains(userAgent, "Android"):
+		system = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		system = "Windows"
+	case strings.Contains(userAgent, "Mac OS X"):
+		system = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		system = "Linux"
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	// Other clients such as curl/8.0 name themselves first
+	product, _, _ := strings.Cut(userAgent, "/")
+	return strings.TrimSpace(product)
+}
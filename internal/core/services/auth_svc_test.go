@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app/internal/config"
+	"app/internal/core/models"
+	"app/pkg/denylist"
+	"app/pkg/locker"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepository serves users from memory, the methods the tests do not
+// need are left to the embedded nil interface
+type fakeUserRepository struct {
+	UserRepository
+	users map[uint]*models.User
+}
+
+func (r *fakeUserRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepository) UpdateLastLogin(ctx context.Context, id uint) error {
+	return nil
+}
+
+// fakeSessionRepository keeps refresh tokens in memory
+type fakeSessionRepository struct {
+	sessions []*models.UserSession
+}
+
+func (r *fakeSessionRepository) CreateSession(ctx context.Context, session *models.UserSession) error {
+	session.ID = uint(len(r.sessions) + 1)
+	r.sessions = append(r.sessions, session)
+	return nil
+}
+
+func (r *fakeSessionRepository) FindSessionByTokenHash(ctx context.Context, hash string) (*models.UserSession, error) {
+	for _, session := range r.sessions {
+		if session.TokenHash == hash {
+			copied := *session
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSessionRepository) RotateSession(ctx context.Context, current, next *models.UserSession, at time.Time) (bool, error) {
+	stored := r.sessions[current.ID-1]
+	if stored.RotatedAt != nil || stored.RevokedAt != nil {
+		return false, nil
+	}
+	stored.RotatedAt = &at
+	return true, r.CreateSession(ctx, next)
+}
+
+func (r *fakeSessionRepository) RevokeSessionFamily(ctx context.Context, familyID string, at time.Time) error {
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepository) RevokeUserSessions(ctx context.Context, userID uint, at time.Time) error {
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepository) ListActiveSessions(ctx context.Context, userID uint, now time.Time) ([]models.UserSession, error) {
+	var active []models.UserSession
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RotatedAt == nil && session.RevokedAt == nil &&
+			session.ExpiresAt.After(now) && session.IdleExpiresAt.After(now) {
+			active = append(active, *session)
+		}
+	}
+	return active, nil
+}
+
+func (r *fakeSessionRepository) SessionStartTimes(ctx context.Context, familyIDs []string) (map[string]time.Time, error) {
+	startTimes := make(map[string]time.Time)
+	for _, session := range r.sessions {
+		if started, ok := startTimes[session.FamilyID]; !ok || session.CreatedAt.Before(started) {
+			startTimes[session.FamilyID] = session.CreatedAt
+		}
+	}
+	return startTimes, nil
+}
+
+func (r *fakeSessionRepository) TouchSession(ctx context.Context, familyID string, at time.Time) error {
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID && session.RotatedAt == nil && session.RevokedAt == nil {
+			session.LastSeenAt = &at
+		}
+	}
+	return nil
+}
+
+func newTestAuthService() (*AuthService, *fakeUserRepository, *fakeSessionRepository) {
+	users := &fakeUserRepository{users: map[uint]*models.User{
+		1: {ID: 1, Username: "admin", Status: 1},
+	}}
+	sessions := &fakeSessionRepository{}
+	cfg := &config.Config{JWT: config.JWTConfig{
+		Secret:              "test-secret",
+		ExpireTime:          900,
+		RefreshIdleTime:     3600,
+		RefreshAbsoluteTime: 86400,
+	}}
+	return NewAuthService(users, sessions, denylist.NewMemoryDenylist(), nil, cfg), users, sessions
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	svc, _, sessions := newTestAuthService()
+	ctx := context.Background()
+
+	login, err := svc.issueTokens(ctx, &models.User{ID: 1, Username: "admin"}, nil, "127.0.0.1", "test")
+	require.NoError(t, err)
+	assert.NotEmpty(t, login.RefreshToken)
+	assert.Equal(t, 3600, login.RefreshExpiresIn)
+
+	refreshed, err := svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	require.NoError(t, err)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+	assert.NotEmpty(t, refreshed.AccessToken)
+
+	// Both tokens belong to the session named in the access token
+	require.Len(t, sessions.sessions, 2)
+	assert.Equal(t, sessions.sessions[0].FamilyID, sessions.sessions[1].FamilyID)
+	assert.Equal(t, sessions.sessions[0].ExpiresAt, sessions.sessions[1].ExpiresAt)
+	claims, err := svc.ValidateToken(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, sessions.sessions[1].FamilyID, claims["sid"])
+
+	// The new token keeps working
+	_, err = svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: refreshed.RefreshToken})
+	assert.NoError(t, err)
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	svc, _, sessions := newTestAuthService()
+	ctx := context.Background()
+
+	login, err := svc.issueTokens(ctx, &models.User{ID: 1, Username: "admin"}, nil, "", "")
+	require.NoError(t, err)
+	refreshed, err := svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	require.NoError(t, err)
+
+	// Presenting the rotated token again ends the session
+	_, err = svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	for _, session := range sessions.sessions {
+		assert.NotNil(t, session.RevokedAt)
+	}
+
+	// Including for the holder of the newest token
+	_, err = svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: refreshed.RefreshToken})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRefreshRejectsInvalidTokens(t *testing.T) {
+	svc, users, sessions := newTestAuthService()
+	ctx := context.Background()
+
+	_, err := svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: "unknown"})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// Idle expiry
+	login, err := svc.issueTokens(ctx, &models.User{ID: 1}, nil, "", "")
+	require.NoError(t, err)
+	sessions.sessions[0].IdleExpiresAt = time.Now().Add(-time.Second)
+	_, err = svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// Absolute expiry
+	login, err = svc.issueTokens(ctx, &models.User{ID: 1}, nil, "", "")
+	require.NoError(t, err)
+	sessions.sessions[1].ExpiresAt = time.Now().Add(-time.Second)
+	_, err = svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// Disabled user
+	login, err = svc.issueTokens(ctx, &models.User{ID: 1}, nil, "", "")
+	require.NoError(t, err)
+	users.users[1].Status = 0
+	_, err = svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	assert.ErrorIs(t, err, ErrUserInactive)
+	assert.NotNil(t, sessions.sessions[2].RevokedAt)
+}
+
+func TestLogoutRevokesToken(t *testing.T) {
+	svc, _, sessions := newTestAuthService()
+	ctx := context.Background()
+
+	login, err := svc.issueTokens(ctx, &models.User{ID: 1, Username: "admin"}, nil, "", "")
+	require.NoError(t, err)
+	other, err := svc.issueTokens(ctx, &models.User{ID: 1, Username: "admin"}, nil, "", "")
+	require.NoError(t, err)
+
+	claims, err := svc.ValidateToken(login.AccessToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims["jti"])
+	revoked, err := svc.IsTokenRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, svc.Logout(ctx, 1, claims))
+	revoked, err = svc.IsTokenRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NotNil(t, sessions.sessions[0].RevokedAt)
+
+	// Other devices stay signed in
+	otherClaims, err := svc.ValidateToken(other.AccessToken)
+	require.NoError(t, err)
+	revoked, err = svc.IsTokenRevoked(ctx, otherClaims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.Nil(t, sessions.sessions[1].RevokedAt)
+}
+
+func TestRevokeUserTokens(t *testing.T) {
+	svc, _, sessions := newTestAuthService()
+	ctx := context.Background()
+
+	login, err := svc.issueTokens(ctx, &models.User{ID: 1, Username: "admin"}, nil, "", "")
+	require.NoError(t, err)
+	claims, err := svc.ValidateToken(login.AccessToken)
+	require.NoError(t, err)
+
+	// Without ending the sessions the client refreshes its token
+	require.NoError(t, svc.RevokeUserTokens(ctx, 1, false))
+	revoked, err := svc.IsTokenRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.Nil(t, sessions.sessions[0].RevokedAt)
+
+	// The token the refresh issues right after, within the same second, is valid
+	time.Sleep(2 * time.Millisecond)
+	refreshed, err := svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: login.RefreshToken})
+	require.NoError(t, err)
+	refreshedClaims, err := svc.ValidateToken(refreshed.AccessToken)
+	require.NoError(t, err)
+	revoked, err = svc.IsTokenRevoked(ctx, refreshedClaims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Ending them signs the user out
+	require.NoError(t, svc.RevokeUserTokens(ctx, 1, true))
+	_, err = svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: refreshed.RefreshToken})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// Tokens of other users are untouched
+	claims["user_id"] = float64(2)
+	claims["jti"] = "other"
+	claims["iat"] = float64(time.Now().Add(-time.Minute).Unix())
+	revoked, err = svc.IsTokenRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestListAndRevokeSessions(t *testing.T) {
+	svc, _, sessions := newTestAuthService()
+	ctx := context.Background()
+
+	chrome := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	login, err := svc.issueTokens(ctx, &models.User{ID: 1}, nil, "10.0.0.1", chrome)
+	require.NoError(t, err)
+	_, err = svc.issueTokens(ctx, &models.User{ID: 1}, nil, "10.0.0.2", "curl/8.4.0")
+	require.NoError(t, err)
+	sessions.sessions[0].CreatedAt = time.Now().Add(-time.Hour)
+	_, err = svc.Refresh(ctx, &RefreshTokenRequest{RefreshToken: login.RefreshToken, UserAgent: chrome})
+	require.NoError(t, err)
+
+	current := sessions.sessions[0].FamilyID
+	list, err := svc.ListSessions(ctx, 1, current)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	byID := make(map[string]SessionInfo)
+	for _, session := range list {
+		byID[session.ID] = session
+	}
+
+	// A refreshed session is listed once, from when it was signed in
+	assert.Equal(t, "Chrome on Windows", byID[current].Device)
+	assert.True(t, byID[current].Current)
+	assert.Equal(t, sessions.sessions[0].CreatedAt, byID[current].CreatedAt)
+	other := sessions.sessions[1].FamilyID
+	assert.Equal(t, "curl", byID[other].Device)
+	assert.False(t, byID[other].Current)
+
+	// Only the sessions of the user can be revoked
+	assert.ErrorIs(t, svc.RevokeUserSession(ctx, 2, other), ErrSessionNotFound)
+	require.NoError(t, svc.RevokeUserSession(ctx, 1, other))
+	list, err = svc.ListSessions(ctx, 1, current)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, current, list[0].ID)
+
+	// The access tokens of a revoked session are revoked with it
+	revoked, err := svc.IsTokenRevoked(ctx, jwt.MapClaims{"user_id": float64(1), "sid": other, "iat": float64(time.Now().Unix())})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	require.NoError(t, svc.RevokeOtherSessions(ctx, 1, current))
+	list, err = svc.ListSessions(ctx, 1, current)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestTouchSessionIsThrottled(t *testing.T) {
+	svc, _, sessions := newTestAuthService()
+	svc.SetSessionLocker(locker.NewMemoryLocker())
+	ctx := context.Background()
+
+	_, err := svc.issueTokens(ctx, &models.User{ID: 1}, nil, "", "")
+	require.NoError(t, err)
+	session := sessions.sessions[0]
+
+	svc.TouchSession(ctx, session.FamilyID)
+	seen := session.LastSeenAt
+	require.NotNil(t, seen)
+	svc.TouchSession(ctx, session.FamilyID)
+	assert.Same(t, seen, session.LastSeenAt)
+}
+
+func TestDescribeDevice(t *testing.T) {
+	for userAgent, device := range map[string]string{
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15":                  "Safari on macOS",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/119.0 Mobile/15E148 Safari/604.1": "Chrome on iOS",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0":                                                                 "Firefox on Linux",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0":          "Edge on Windows",
+		"PostmanRuntime/7.36.0": "PostmanRuntime",
+		"":                      "Unknown device",
+	} {
+		assert.Equal(t, device, describeDevice(userAgent), userAgent)
+	}
+}
+
+func TestLoginWithTwoFactor(t *testing.T) {
+	svc, users, _ := newTestAuthService()
+	repo := newFakeTwoFactorRepository()
+	svc.SetTwoFactorService(NewTwoFactorService(repo, nil))
+	ctx := context.Background()
+
+	password, err := svc.HashPassword("secret")
+	require.NoError(t, err)
+	users.users[1].Password = password
+	login := &LoginRequest{Username: "admin", Password: "secret"}
+
+	// Without two-factor authentication the password is enough
+	resp, err := svc.Login(ctx, login)
+	require.NoError(t, err)
+	require.NotNil(t, resp.TokenResponse)
+	assert.False(t, resp.MFARequired)
+
+	// A role requiring it makes the user enrol first
+	repo.required[1] = true
+	resp, err = svc.Login(ctx, login)
+	require.NoError(t, err)
+	assert.Nil(t, resp.TokenResponse)
+	assert.True(t, resp.MFASetupRequired)
+	_, err = svc.ValidateToken(resp.MFAToken)
+	assert.Error(t, err, "an MFA token is no access token")
+
+	_, err = svc.SetupTwoFactorLogin(ctx, &TwoFactorSetupRequest{MFAToken: resp.MFAToken})
+	require.NoError(t, err)
+	setup, err := svc.LoginTwoFactor(ctx, &TwoFactorLoginRequest{MFAToken: resp.MFAToken, Code: codeAt(t, repo, 1, time.Now().Add(-totpPeriod*time.Second))})
+	require.NoError(t, err)
+	require.NotNil(t, setup.TokenResponse)
+	assert.Len(t, setup.RecoveryCodes, recoveryCodeCount)
+
+	// The challenge completes a single login
+	_, err = svc.LoginTwoFactor(ctx, &TwoFactorLoginRequest{MFAToken: resp.MFAToken, Code: setup.RecoveryCodes[0]})
+	assert.ErrorIs(t, err, ErrInvalidMFAToken)
+
+	// Enrolled users enter a code
+	resp, err = svc.Login(ctx, login)
+	require.NoError(t, err)
+	assert.True(t, resp.MFARequired)
+	_, err = svc.SetupTwoFactorLogin(ctx, &TwoFactorSetupRequest{MFAToken: resp.MFAToken})
+	assert.ErrorIs(t, err, ErrInvalidMFAToken)
+	_, err = svc.LoginTwoFactor(ctx, &TwoFactorLoginRequest{MFAToken: resp.MFAToken, Code: "wrong"})
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	done, err := svc.LoginTwoFactor(ctx, &TwoFactorLoginRequest{MFAToken: resp.MFAToken, Code: codeAt(t, repo, 1, time.Now())})
+	require.NoError(t, err)
+	assert.NotEmpty(t, done.AccessToken)
+	assert.Empty(t, done.RecoveryCodes)
+
+	_, err = svc.LoginTwoFactor(ctx, &TwoFactorLoginRequest{MFAToken: "forged", Code: "123456"})
+	assert.ErrorIs(t, err, ErrInvalidMFAToken)
+}
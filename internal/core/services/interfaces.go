@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"app/internal/core/models"
 	"app/internal/core/types"
@@ -23,6 +24,31 @@ type UserRepository interface {
 	GetDB() *gorm.DB
 }
 
+// UserSessionRepository defines the interface for refresh token storage
+type UserSessionRepository interface {
+	CreateSession(ctx context.Context, session *models.UserSession) error
+	FindSessionByTokenHash(ctx context.Context, hash string) (*models.UserSession, error)
+	RotateSession(ctx context.Context, current, next *models.UserSession, at time.Time) (bool, error)
+	RevokeSessionFamily(ctx context.Context, familyID string, at time.Time) error
+	RevokeUserSessions(ctx context.Context, userID uint, at time.Time) error
+	ListActiveSessions(ctx context.Context, userID uint, now time.Time) ([]models.UserSession, error)
+	SessionStartTimes(ctx context.Context, familyIDs []string) (map[string]time.Time, error)
+	TouchSession(ctx context.Context, familyID string, at time.Time) error
+}
+
+// TwoFactorRepository defines the interface for TOTP secrets and recovery codes
+type TwoFactorRepository interface {
+	FindTwoFactor(ctx context.Context, userID uint) (*models.UserTwoFactor, error)
+	SaveTwoFactor(ctx context.Context, twoFactor *models.UserTwoFactor) error
+	EnableTwoFactor(ctx context.Context, twoFactor *models.UserTwoFactor, step int64, codeHashes []string, at time.Time) (bool, error)
+	DeleteTwoFactor(ctx context.Context, userID uint) error
+	UseTwoFactorStep(ctx context.Context, id uint, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uint) (int64, error)
+	RequiresTwoFactor(ctx context.Context, userID uint) (bool, error)
+}
+
 // LogRepository defines the interface for log data access
 type LogRepository interface {
 	CreateLoginLog(ctx context.Context, log *models.LoginLog) error
@@ -31,4 +57,27 @@ type LogRepository interface {
 	ListOperationLogs(ctx context.Context, pagination *models.Pagination, query map[string]interface{}) ([]models.OperationLog, error)
 	GetLoginLogsByUserID(ctx context.Context, userID uint, limit int) ([]models.LoginLog, error)
 	GetOperationLogsByUserID(ctx context.Context, userID uint, limit int) ([]models.OperationLog, error)
+	CountLoginLogs(ctx context.Context, column, value string, messages []string, since time.Time) (int64, *time.Time, error)
+	ListLoginLogKeys(ctx context.Context, column string, messages []string, since time.Time, minCount int64) ([]string, error)
+}
+
+// ScheduleRunRepository defines the interface for schedule run history access
+type ScheduleRunRepository interface {
+	CreateRun(ctx context.Context, run *models.ScheduleRun) error
+	FindRunByID(ctx context.Context, id uint) (*models.ScheduleRun, error)
+	ListRuns(ctx context.Context, pagination *models.Pagination, query map[string]interface{}) ([]models.ScheduleRun, error)
+	DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ScheduledTaskRepository defines the interface for scheduled task data access
+type ScheduledTaskRepository interface {
+	CreateTask(ctx context.Context, task *models.ScheduledTask) error
+	UpdateTask(ctx context.Context, task *models.ScheduledTask) error
+	DeleteTask(ctx context.Context, id uint) error
+	FindTaskByID(ctx context.Context, id uint) (*models.ScheduledTask, error)
+	ListTasks(ctx context.Context, pagination *models.Pagination) ([]models.ScheduledTask, error)
+	ListEnabledTasks(ctx context.Context) ([]models.ScheduledTask, error)
+	ListRunRequests(ctx context.Context) ([]models.ScheduledTask, error)
+	SetRunRequest(ctx context.Context, id uint, at time.Time) error
+	ClearRunRequest(ctx context.Context, id uint, at time.Time) (bool, error)
 }
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"app/internal/config"
+	"app/internal/core/models"
+)
+
+// Login lock types, a lock applies to a username or to an IP
+const (
+	LoginLockUsername = "username"
+	LoginLockIP       = "ip"
+)
+
+const (
+	// loginLockedMessage is logged for attempts refused by a lock, they
+	// are not counted as failures
+	loginLockedMessage = "login locked"
+	// lockClearedMessage is logged when an admin clears a lock, failures
+	// are counted from it on
+	lockClearedMessage = "lock cleared"
+)
+
+var (
+	ErrLoginLocked          = errors.New("too many failed login attempts")
+	ErrInvalidLoginLockType = errors.New("invalid login lock type")
+)
+
+// loginFailureMessages are the login log messages counted as failures
+var loginFailureMessages = []string{"user not found", "invalid password", "invalid two-factor code"}
+
+// loginLockResetMessages are the login log messages failures are counted
+// from. A successful login does not reset an IP, or a single valid account
+// would keep the IP guessing at others.
+var loginLockResetMessages = map[string][]string{
+	LoginLockUsername: {"login successful", lockClearedMessage},
+	LoginLockIP:       {lockClearedMessage},
+}
+
+// LoginLockedError is returned while a username or IP is locked
+type LoginLockedError struct {
+	Until time.Time
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// RetryAfter returns the seconds until the lock ends, rounded up
+func (e *LoginLockedError) RetryAfter() int {
+	return int(math.Ceil(time.Until(e.Until).Seconds()))
+}
+
+// LoginLock is a locked username or IP
+type LoginLock struct {
+	Type          string    `json:"type"`
+	Key           string    `json:"key"`
+	Failures      int64     `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+}
+
+// ClearLoginLockRequest selects the lock an admin clears
+type ClearLoginLockRequest struct {
+	Type string `form:"type" binding:"required,oneof=username ip"`
+	Key  string `form:"key" binding:"required"`
+}
+
+// LoginLockService locks usernames and IPs after repeated failed logins.
+// The failures are counted from the login logs, so the locks hold across
+// instances without state of their own.
+type LoginLockService struct {
+	logRepo LogRepository
+	config  *config.Config
+	now     func() time.Time
+}
+
+func NewLoginLockService(logRepo LogRepository, config *config.Config) *LoginLockService {
+	return &LoginLockService{
+		logRepo: logRepo,
+		config:  config,
+		now:     time.Now,
+	}
+}
+
+// Enabled reports whether failed logins lock usernames and IPs
+func (s *LoginLockService) Enabled() bool {
+	return s.config != nil && s.config.Lockout.Enabled
+}
+
+// Check returns a LoginLockedError when the username or the IP is locked,
+// the one locked the longest when both are
+func (s *LoginLockService) Check(ctx context.Context, username, ip string) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	var until time.Time
+	for lockType, key := range map[string]string{LoginLockUsername: username, LoginLockIP: ip} {
+		if key == "" {
+			continue
+		}
+		lock, err := s.find(ctx, lockType, key)
+		if err != nil {
+			return err
+		}
+		if lock != nil && lock.LockedUntil.After(until) {
+			until = lock.LockedUntil
+		}
+	}
+	if until.After(s.now()) {
+		return &LoginLockedError{Until: until}
+	}
+	return nil
+}
+
+// Locks lists the usernames and IPs locked now, the longest locks first
+func (s *LoginLockService) Locks(ctx context.Context) ([]LoginLock, error) {
+	locks := []LoginLock{}
+	if !s.Enabled() {
+		return locks, nil
+	}
+
+	now := s.now()
+	since := now.Add(-s.window())
+	for _, lockType := range []string{LoginLockUsername, LoginLockIP} {
+		// Keys reset since still have to be checked one by one
+		keys, err := s.logRepo.ListLoginLogKeys(ctx, lockType, loginFailureMessages, since, int64(s.maxAttempts(lockType)))
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			lock, err := s.find(ctx, lockType, key)
+			if err != nil {
+				return nil, err
+			}
+			if lock != nil && lock.LockedUntil.After(now) {
+				locks = append(locks, *lock)
+			}
+		}
+	}
+
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].LockedUntil.After(locks[j].LockedUntil)
+	})
+	return locks, nil
+}
+
+// Clear unlocks a username or an IP, the failures before are no longer counted
+func (s *LoginLockService) Clear(ctx context.Context, lockType, key string) error {
+	if _, ok := loginLockResetMessages[lockType]; !ok || key == "" {
+		return ErrInvalidLoginLockType
+	}
+
+	log := &models.LoginLog{
+		Status:    1,
+		Message:   lockClearedMessage,
+		LoginTime: models.CustomTime(s.now()),
+	}
+	if lockType == LoginLockUsername {
+		log.Username = key
+	} else {
+		log.IP = key
+	}
+	return s.logRepo.CreateLoginLog(ctx, log)
+}
+
+// find returns the lock of a username or IP, or nil when it has fewer
+// failures than allowed. The lock may have ended already.
+func (s *LoginLockService) find(ctx context.Context, lockType, key string) (*LoginLock, error) {
+	since := s.now().Add(-s.window())
+	_, reset, err := s.logRepo.CountLoginLogs(ctx, lockType, key, loginLockResetMessages[lockType], since)
+	if err != nil {
+		return nil, err
+	}
+	if reset != nil && reset.After(since) {
+		since = *reset
+	}
+
+	failures, lastFailure, err := s.logRepo.CountLoginLogs(ctx, lockType, key, loginFailureMessages, since)
+	if err != nil {
+		return nil, err
+	}
+	maxAttempts := s.maxAttempts(lockType)
+	if failures < int64(maxAttempts) || lastFailure == nil {
+		return nil, nil
+	}
+
+	return &LoginLock{
+		Type:          lockType,
+		Key:           key,
+		Failures:      failures,
+		LastFailureAt: *lastFailure,
+		LockedUntil:   lastFailure.Add(s.lockoutTime(failures - int64(maxAttempts))),
+	}, nil
+}
+
+// lockoutTime returns how long a lock lasts, doubled by every failure past
+// the allowed attempts up to the maximum
+func (s *LoginLockService) lockoutTime(extraFailures int64) time.Duration {
+	lockout := lockoutSeconds(s.config.Lockout.LockoutTime, 300)
+	maxLockout := lockoutSeconds(s.config.Lockout.MaxLockoutTime, 86400)
+	for i := int64(0); i < extraFailures && lockout < maxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > maxLockout {
+		lockout = maxLockout
+	}
+	return lockout
+}
+
+// window returns how far back failures are counted
+func (s *LoginLockService) window() time.Duration {
+	return lockoutSeconds(s.config.Lockout.Window, 86400)
+}
+
+// maxAttempts returns the failures allowed before a lock
+func (s *LoginLockService) maxAttempts(lockType string) int {
+	attempts, fallback := s.config.Lockout.MaxAttempts, 5
+	if lockType == LoginLockIP {
+		attempts, fallback = s.config.Lockout.IPMaxAttempts, 20
+	}
+	if attempts <= 0 {
+		return fallback
+	}
+	return attempts
+}
+
+// lockoutSeconds returns a configured number of seconds as a duration,
+// the fallback when it is not set
+func lockoutSeconds(seconds, fallback int) time.Duration {
+	if seconds <= 0 {
+		seconds = fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
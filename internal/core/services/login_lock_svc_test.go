@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app/internal/config"
+	"app/internal/core/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLogRepository keeps login logs in memory
+type fakeLogRepository struct {
+	LogRepository
+	logs []models.LoginLog
+}
+
+func (r *fakeLogRepository) CreateLoginLog(ctx context.Context, log *models.LoginLog) error {
+	r.logs = append(r.logs, *log)
+	return nil
+}
+
+func (r *fakeLogRepository) matching(column string, messages []string, since time.Time) []models.LoginLog {
+	var logs []models.LoginLog
+	for _, log := range r.logs {
+		if time.Time(log.LoginTime).Before(since) {
+			continue
+		}
+		for _, message := range messages {
+			if log.Message == message {
+				logs = append(logs, log)
+				break
+			}
+		}
+	}
+	return logs
+}
+
+func loginLogKey(log models.LoginLog, column string) string {
+	if column == LoginLockIP {
+		return log.IP
+	}
+	return log.Username
+}
+
+func (r *fakeLogRepository) CountLoginLogs(ctx context.Context, column, value string, messages []string, since time.Time) (int64, *time.Time, error) {
+	var count int64
+	var latest *time.Time
+	for _, log := range r.matching(column, messages, since) {
+		if loginLogKey(log, column) != value {
+			continue
+		}
+		count++
+		if at := time.Time(log.LoginTime); latest == nil || at.After(*latest) {
+			latest = &at
+		}
+	}
+	return count, latest, nil
+}
+
+func (r *fakeLogRepository) ListLoginLogKeys(ctx context.Context, column string, messages []string, since time.Time, minCount int64) ([]string, error) {
+	counts := make(map[string]int64)
+	for _, log := range r.matching(column, messages, since) {
+		if key := loginLogKey(log, column); key != "" {
+			counts[key]++
+		}
+	}
+	var keys []string
+	for key, count := range counts {
+		if count >= minCount {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeLogRepository) fail(username, ip string, at time.Time) {
+	r.logs = append(r.logs, models.LoginLog{Username: username, IP: ip, Message: "invalid password", LoginTime: models.CustomTime(at)})
+}
+
+func newTestLoginLockService() (*LoginLockService, *fakeLogRepository) {
+	logs := &fakeLogRepository{}
+	cfg := &config.Config{Lockout: config.LockoutConfig{
+		Enabled:        true,
+		MaxAttempts:    3,
+		IPMaxAttempts:  5,
+		LockoutTime:    60,
+		MaxLockoutTime: 300,
+		Window:         3600,
+	}}
+	return NewLoginLockService(logs, cfg), logs
+}
+
+func TestLoginLockBackOff(t *testing.T) {
+	svc, logs := newTestLoginLockService()
+	ctx := context.Background()
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	// Failures up to the limit are allowed
+	logs.fail("admin", "10.0.0.1", now.Add(-10*time.Second))
+	logs.fail("admin", "10.0.0.2", now.Add(-10*time.Second))
+	assert.NoError(t, svc.Check(ctx, "admin", "10.0.0.1"))
+
+	logs.fail("admin", "10.0.0.3", now.Add(-10*time.Second))
+	err := svc.Check(ctx, "admin", "10.0.0.9")
+	var locked *LoginLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.ErrorIs(t, err, ErrLoginLocked)
+	assert.Equal(t, now.Add(50*time.Second), locked.Until)
+	assert.NoError(t, svc.Check(ctx, "other", "10.0.0.9"))
+
+	// Every further failure doubles the lock up to the maximum
+	for i, want := range []time.Duration{120, 240, 300, 300} {
+		at := now.Add(time.Duration(i-5) * time.Second)
+		logs.fail("admin", "10.0.0.1", at)
+		lock, err := svc.find(ctx, LoginLockUsername, "admin")
+		require.NoError(t, err)
+		assert.Equal(t, at.Add(want*time.Second), lock.LockedUntil)
+	}
+
+	// Failures outside the window are forgotten
+	logs.logs = nil
+	for i := 0; i < 3; i++ {
+		logs.fail("admin", "", now.Add(-2*time.Hour))
+	}
+	assert.NoError(t, svc.Check(ctx, "admin", ""))
+}
+
+func TestLoginLockResets(t *testing.T) {
+	svc, logs := newTestLoginLockService()
+	ctx := context.Background()
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		logs.fail("user"+string(rune('a'+i)), "10.0.0.1", now.Add(-10*time.Second))
+	}
+	logs.fail("usera", "10.0.0.1", now.Add(-10*time.Second))
+	logs.fail("usera", "10.0.0.1", now.Add(-10*time.Second))
+
+	locks, err := svc.Locks(ctx)
+	require.NoError(t, err)
+	require.Len(t, locks, 2)
+	assert.Equal(t, LoginLockIP, locks[0].Type, "the IP has the most failures")
+	assert.Equal(t, int64(7), locks[0].Failures)
+	assert.Equal(t, LoginLockUsername, locks[1].Type)
+	assert.Equal(t, "usera", locks[1].Key)
+
+	// A successful login resets the username but not the IP
+	logs.logs = append(logs.logs, models.LoginLog{Username: "usera", IP: "10.0.0.1", Status: 1, Message: "login successful", LoginTime: models.CustomTime(now)})
+	locks, err = svc.Locks(ctx)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, "10.0.0.1", locks[0].Key)
+
+	// Clearing the IP resets it
+	require.NoError(t, svc.Clear(ctx, LoginLockIP, "10.0.0.1"))
+	assert.NoError(t, svc.Check(ctx, "usera", "10.0.0.1"))
+	assert.ErrorIs(t, svc.Clear(ctx, "email", "a@example.com"), ErrInvalidLoginLockType)
+
+	// Nothing is locked when the lockout is off
+	svc.config.Lockout.Enabled = false
+	logs.logs = nil
+	for i := 0; i < 10; i++ {
+		logs.fail("usera", "10.0.0.1", now)
+	}
+	assert.NoError(t, svc.Check(ctx, "usera", "10.0.0.1"))
+}
+
+func TestLoginIsRefusedWhileLocked(t *testing.T) {
+	svc, users, _ := newTestAuthService()
+	lockSvc, logs := newTestLoginLockService()
+	svc.logSvc = NewLogService(logs)
+	svc.SetLoginLockService(lockSvc)
+	ctx := context.Background()
+
+	password, err := svc.HashPassword("secret")
+	require.NoError(t, err)
+	users.users[1].Password = password
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.Login(ctx, &LoginRequest{Username: "admin", Password: "wrong", IP: "10.0.0.1"})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	// The right password is refused too, and the refusal is not counted
+	_, err = svc.Login(ctx, &LoginRequest{Username: "admin", Password: "secret", IP: "10.0.0.2"})
+	var locked *LoginLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.InDelta(t, 60, locked.RetryAfter(), 2)
+	assert.Equal(t, loginLockedMessage, logs.logs[len(logs.logs)-1].Message)
+	lock, err := lockSvc.find(ctx, LoginLockUsername, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), lock.Failures)
+
+	require.NoError(t, lockSvc.Clear(ctx, LoginLockUsername, "admin"))
+	resp, err := svc.Login(ctx, &LoginRequest{Username: "admin", Password: "secret", IP: "10.0.0.2"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.AccessToken)
+}
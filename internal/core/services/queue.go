@@ -3,10 +3,14 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
+	"sort"
 	"sync"
 	"time"
 
+	"app/internal/config"
+	_ "app/internal/core/jobs" // 注册任务类型，供 Worker 还原队列中的任务
+	"app/internal/core/sse"
+	"app/pkg/database"
 	"app/pkg/queue"
 
 	"github.com/spf13/viper"
@@ -14,9 +18,11 @@ import (
 
 // QueueService 队列服务
 type QueueService struct {
-	manager *queue.Manager
-	workers map[string]*queue.Worker
-	mu      sync.RWMutex
+	manager    *queue.Manager
+	supervisor *queue.Supervisor
+	// events 推送队列监控更新的 SSE 管理器
+	events *sse.Manager
+	mu     sync.RWMutex
 }
 
 // NewQueueService 创建队列服务
@@ -45,17 +51,25 @@ func NewQueueService() (*QueueService, error) {
 		}
 
 		config.Options["connection"] = connectionStr
-		config.Options["queue"] = queueName
 
 	case "database", "mysql":
-		// 这里需要传入数据库连接实例
-		// 暂时返回错误，提示需要在外部传入数据库连接
-		return nil, fmt.Errorf("database driver requires external database connection setup")
+		// 使用应用共享的数据库连接
+		db := database.GetDB()
+		if db == nil {
+			return nil, fmt.Errorf("database queue driver requires an initialized database connection")
+		}
+		config.Options["db"] = db
+
+	case "memory", "sync":
+		// 内存和同步驱动无需连接，用于测试和本地开发
 
 	default:
 		return nil, fmt.Errorf("unsupported queue driver: %s", driver)
 	}
 
+	config.Options["queue"] = queueName
+	config.Options["retry_after"] = viper.GetInt("queue.retry_after")
+
 	// 创建队列管理器
 	manager, err := queue.NewManager(config)
 	if err != nil {
@@ -64,69 +78,144 @@ func NewQueueService() (*QueueService, error) {
 
 	return &QueueService{
 		manager: manager,
-		workers: make(map[string]*queue.Worker),
 	}, nil
 }
 
-// Start 启动队列服务
+// Start 按队列配置启动工作进程
 func (s *QueueService) Start() error {
-	// 获取队列配置
-	queues := viper.GetStringMap("queue.queues")
-	if len(queues) == 0 {
-		return fmt.Errorf("no queues configured")
-	}
-
-	// 启动每个队列的工作进程
-	for name, config := range queues {
-		queueConfig := config.(map[string]interface{})
-		processes := int(queueConfig["processes"].(int))
-
-		// 创建队列选项
-		options := queue.WorkerOptions{
-			Sleep:   time.Duration(viper.GetInt("queue.worker.sleep")) * time.Second,
-			MaxJobs: viper.GetInt64("queue.worker.max_jobs"),
-			MaxTime: time.Duration(viper.GetInt("queue.worker.max_time")) * time.Second,
-			Rest:    time.Duration(viper.GetInt("queue.worker.rest")) * time.Second,
-			Memory:  viper.GetInt64("queue.worker.memory"),
-			Tries:   viper.GetInt("queue.worker.tries"),
-			Timeout: time.Duration(viper.GetInt("queue.worker.timeout")) * time.Second,
-		}
+	options, err := workerOptions()
+	if err != nil {
+		return err
+	}
 
-		// 启动指定数量的工作进程
-		for i := 0; i < processes; i++ {
-			worker := queue.NewWorker(s.manager, []string{name}, options)
-			workerName := fmt.Sprintf("%s-%d", name, i+1)
-
-			s.mu.Lock()
-			s.workers[workerName] = worker
-			s.mu.Unlock()
-
-			// 启动工作进程
-			go func(w *queue.Worker, name string) {
-				log.Printf("Starting queue worker: %s", name)
-				w.Start()
-				log.Printf("Queue worker stopped: %s", name)
-
-				s.mu.Lock()
-				delete(s.workers, name)
-				s.mu.Unlock()
-			}(worker, workerName)
-		}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.supervisor != nil {
+		return fmt.Errorf("queue service already started")
 	}
 
+	supervisor := queue.NewSupervisor(s.manager, options)
+	if err := supervisor.Start(); err != nil {
+		return err
+	}
+	s.supervisor = supervisor
+
 	return nil
 }
 
 // Stop 停止队列服务
 func (s *QueueService) Stop() {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	supervisor := s.supervisor
+	s.supervisor = nil
+	s.mu.Unlock()
 
 	// 停止所有工作进程
-	for name, worker := range s.workers {
-		log.Printf("Stopping queue worker: %s", name)
-		worker.Stop()
+	if supervisor != nil {
+		supervisor.Stop()
+	}
+}
+
+// workerOptions 从配置中读取工作进程默认选项及各队列的独立配置
+func workerOptions() (queue.WorkerOptions, error) {
+	options := queue.WorkerOptions{
+		Sleep:   time.Duration(viper.GetInt("queue.worker.sleep")) * time.Second,
+		MaxJobs: viper.GetInt64("queue.worker.max_jobs"),
+		MaxTime: time.Duration(viper.GetInt("queue.worker.max_time")) * time.Second,
+		Rest:    time.Duration(viper.GetInt("queue.worker.rest")) * time.Second,
+		Memory:  viper.GetInt64("queue.worker.memory"),
+		Tries:   viper.GetInt("queue.worker.tries"),
+		Timeout: time.Duration(viper.GetInt("queue.worker.timeout")) * time.Second,
+		Balance: viper.GetString("queue.worker.balance"),
+		Queues:  make(map[string]queue.QueueOptions),
+	}
+
+	var details map[string]config.QueueDetail
+	if err := viper.UnmarshalKey("queue.queues", &details); err != nil {
+		return options, fmt.Errorf("invalid queue configuration: %v", err)
+	}
+
+	for name, detail := range details {
+		backoff := make([]time.Duration, 0, len(detail.Backoff))
+		for _, seconds := range detail.Backoff {
+			backoff = append(backoff, time.Duration(seconds)*time.Second)
+		}
+
+		options.Queues[name] = queue.QueueOptions{
+			Name:       name,
+			Priority:   detail.Priority,
+			Processes:  detail.Processes,
+			Timeout:    time.Duration(detail.Timeout) * time.Second,
+			Tries:      detail.Tries,
+			RetryAfter: time.Duration(detail.RetryAfter) * time.Second,
+			Backoff:    backoff,
+			Throttle: queue.Throttle{
+				Limit:        detail.Throttle.Limit,
+				Per:          time.Duration(detail.Throttle.Per) * time.Second,
+				ReleaseAfter: time.Duration(detail.Throttle.ReleaseAfter) * time.Second,
+			},
+		}
+	}
+
+	return options, nil
+}
+
+// QueueSettings 队列生效的配置
+type QueueSettings struct {
+	Name       string `json:"name"`
+	Priority   int    `json:"priority"`
+	Processes  int    `json:"processes"`
+	Workers    int    `json:"workers"`
+	Timeout    int    `json:"timeout"`
+	Tries      int    `json:"tries"`
+	RetryAfter int    `json:"retry_after"`
+	Backoff    []int  `json:"backoff"`
+	// ThrottleLimit 每个限流周期内允许开始执行的任务数，0表示不限流
+	ThrottleLimit int `json:"throttle_limit"`
+	ThrottlePer   int `json:"throttle_per"`
+}
+
+// Settings 获取各队列生效的配置（时间单位为秒）及正在运行的工作进程数
+func (s *QueueService) Settings() ([]QueueSettings, error) {
+	s.mu.RLock()
+	supervisor := s.supervisor
+	s.mu.RUnlock()
+
+	var workers map[string]int
+	if supervisor == nil {
+		// 未启动时按配置计算，便于在启动前检查
+		options, err := workerOptions()
+		if err != nil {
+			return nil, err
+		}
+		supervisor = queue.NewSupervisor(s.manager, options)
+	} else {
+		workers = supervisor.Workers()
+	}
+
+	settings := make([]QueueSettings, 0)
+	for _, options := range supervisor.Settings() {
+		backoff := make([]int, 0, len(options.Backoff))
+		for _, d := range options.Backoff {
+			backoff = append(backoff, int(d/time.Second))
+		}
+
+		settings = append(settings, QueueSettings{
+			Name:          options.Name,
+			Priority:      options.Priority,
+			Processes:     options.Processes,
+			Workers:       workers[options.Name],
+			Timeout:       int(options.Timeout / time.Second),
+			Tries:         options.Tries,
+			RetryAfter:    int(options.RetryAfter / time.Second),
+			Backoff:       backoff,
+			ThrottleLimit: options.Throttle.Limit,
+			ThrottlePer:   int(options.Throttle.Per / time.Second),
+		})
 	}
+
+	return settings, nil
 }
 
 // Push 推送任务到队列
@@ -144,6 +233,26 @@ func (s *QueueService) Later(ctx context.Context, job queue.JobInterface, delay
 	return s.manager.Later(ctx, job, delay)
 }
 
+// Chain 推送任务链
+func (s *QueueService) Chain(ctx context.Context, jobs ...queue.JobInterface) error {
+	return s.manager.Chain(ctx, jobs...)
+}
+
+// Batch 创建待推送的任务批次
+func (s *QueueService) Batch(jobs ...queue.JobInterface) *queue.PendingBatch {
+	return s.manager.Batch(jobs...)
+}
+
+// Batches 获取所有任务批次
+func (s *QueueService) Batches(ctx context.Context) ([]*queue.Batch, error) {
+	return s.manager.Batches(ctx)
+}
+
+// FindBatch 根据ID获取任务批次
+func (s *QueueService) FindBatch(ctx context.Context, id string) (*queue.Batch, error) {
+	return s.manager.FindBatch(ctx, id)
+}
+
 // Pop 从队列中取出任务
 func (s *QueueService) Pop(ctx context.Context, queue string) (queue.JobInterface, error) {
 	return s.manager.Pop(ctx, queue)
@@ -166,30 +275,84 @@ func (s *QueueService) Release(ctx context.Context, queue string, job queue.JobI
 
 // Clear 清空队列
 func (s *QueueService) Clear(ctx context.Context, queue string) error {
-	return s.manager.Clear(ctx, queue)
+	if err := s.manager.Clear(ctx, queue); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
 }
 
 // GetWorkerCount 获取工作进程数量
 func (s *QueueService) GetWorkerCount() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return len(s.workers)
+
+	if s.supervisor == nil {
+		return 0
+	}
+	return s.supervisor.WorkerCount()
 }
 
-// GetActiveQueues 获取活动队列列表
+// GetActiveQueues 获取有工作进程在运行的队列列表
 func (s *QueueService) GetActiveQueues() []string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	queues := make(map[string]struct{})
-	for name := range s.workers {
-		queues[name] = struct{}{}
+	if s.supervisor == nil {
+		return []string{}
 	}
 
-	result := make([]string, 0, len(queues))
-	for name := range queues {
+	result := make([]string, 0)
+	for name := range s.supervisor.Workers() {
 		result = append(result, name)
 	}
+	sort.Strings(result)
 
 	return result
 }
+
+// FailedJobs 获取所有失败任务
+func (s *QueueService) FailedJobs(ctx context.Context) ([]*queue.FailedJob, error) {
+	return s.manager.FailedJobs(ctx)
+}
+
+// FindFailed 根据ID获取失败任务
+func (s *QueueService) FindFailed(ctx context.Context, id string) (*queue.FailedJob, error) {
+	return s.manager.FindFailed(ctx, id)
+}
+
+// Retry 重试失败任务
+func (s *QueueService) Retry(ctx context.Context, id string) error {
+	if err := s.manager.Retry(ctx, id); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// RetryAll 重试所有失败任务
+func (s *QueueService) RetryAll(ctx context.Context) (int, error) {
+	count, err := s.manager.RetryAll(ctx)
+	if count > 0 {
+		s.notify()
+	}
+	return count, err
+}
+
+// Forget 删除失败任务
+func (s *QueueService) Forget(ctx context.Context, id string) error {
+	if err := s.manager.Forget(ctx, id); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// Flush 清空所有失败任务
+func (s *QueueService) Flush(ctx context.Context) error {
+	if err := s.manager.Flush(ctx); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"app/internal/core/sse"
+	"app/pkg/queue"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// QueueEventGroup 接收队列监控推送的 SSE 组，管理端通过 /sse/join 加入
+	QueueEventGroup = "queues"
+	// queueFeedInterval 队列监控定时推送的间隔
+	queueFeedInterval = 5 * time.Second
+	// DefaultMetricsWindow 默认统计最近一小时的吞吐量和平均耗时
+	DefaultMetricsWindow = time.Hour
+)
+
+// QueueStats 单个队列的监控数据
+type QueueStats struct {
+	Name     string `json:"name"`
+	Pending  int64  `json:"pending"`
+	Delayed  int64  `json:"delayed"`
+	Reserved int64  `json:"reserved"`
+	Failed   int64  `json:"failed"`
+	Paused   bool   `json:"paused"`
+	Workers  int    `json:"workers"`
+	// Processed 统计窗口内的执行次数，FailedRuns 为其中失败的次数
+	Processed  int64 `json:"processed"`
+	FailedRuns int64 `json:"failed_runs"`
+	// Throughput 每分钟执行次数
+	Throughput float64 `json:"throughput"`
+	// AvgRuntime 平均执行耗时(毫秒)
+	AvgRuntime float64 `json:"avg_runtime"`
+}
+
+// QueueOverview 队列监控概览
+type QueueOverview struct {
+	Queues    []QueueStats             `json:"queues"`
+	Workers   []*queue.WorkerHeartbeat `json:"workers"`
+	Window    int                      `json:"window"`
+	UpdatedAt time.Time                `json:"updated_at"`
+}
+
+// Heartbeats 获取所有进程中正在运行的工作进程
+func (s *QueueService) Heartbeats(ctx context.Context) ([]*queue.WorkerHeartbeat, error) {
+	return s.manager.Heartbeats(ctx)
+}
+
+// Overview 获取各队列的任务数、吞吐量、平均耗时和工作进程心跳，window 为统计窗口
+func (s *QueueService) Overview(ctx context.Context, window time.Duration) (*QueueOverview, error) {
+	if window <= 0 {
+		window = DefaultMetricsWindow
+	}
+
+	heartbeats, err := s.manager.Heartbeats(ctx)
+	if err != nil && !errors.Is(err, queue.ErrMonitorUnavailable) {
+		return nil, err
+	}
+	if heartbeats == nil {
+		heartbeats = []*queue.WorkerHeartbeat{}
+	}
+
+	paused, err := s.manager.PausedQueues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make(map[string]int64)
+	failedJobs, err := s.manager.FailedJobs(ctx)
+	if err != nil && !errors.Is(err, queue.ErrFailedStoreUnavailable) {
+		return nil, err
+	}
+	for _, job := range failedJobs {
+		failed[job.Queue]++
+	}
+
+	// 汇总配置中的队列以及有工作进程、失败任务或被暂停的队列
+	names := map[string]bool{}
+	if name := viper.GetString("queue.queue"); name != "" {
+		names[name] = true
+	}
+	for name := range viper.GetStringMap("queue.queues") {
+		names[name] = true
+	}
+	workers := make(map[string]int)
+	for _, heartbeat := range heartbeats {
+		for _, name := range heartbeat.Queues {
+			names[name] = true
+			workers[name]++
+		}
+	}
+	for name := range failed {
+		names[name] = true
+	}
+	pausedSet := make(map[string]bool, len(paused))
+	for _, name := range paused {
+		names[name] = true
+		pausedSet[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	since := time.Now().Add(-window)
+	stats := make([]QueueStats, 0, len(sorted))
+	for _, name := range sorted {
+		counts, err := s.manager.Counts(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		metrics, err := s.manager.Metrics(ctx, name, since)
+		if err != nil && !errors.Is(err, queue.ErrMonitorUnavailable) {
+			return nil, err
+		}
+
+		stats = append(stats, QueueStats{
+			Name:       name,
+			Pending:    counts.Pending,
+			Delayed:    counts.Delayed,
+			Reserved:   counts.Reserved,
+			Failed:     failed[name],
+			Paused:     pausedSet[name],
+			Workers:    workers[name],
+			Processed:  metrics.Processed,
+			FailedRuns: metrics.Failed,
+			Throughput: float64(metrics.Processed) / window.Minutes(),
+			AvgRuntime: float64(metrics.AverageRuntime().Microseconds()) / 1000,
+		})
+	}
+
+	return &QueueOverview{
+		Queues:    stats,
+		Workers:   heartbeats,
+		Window:    int(window / time.Second),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// Pause 暂停队列，所有进程中的工作进程都不再从中取任务
+func (s *QueueService) Pause(ctx context.Context, name string) error {
+	if err := s.manager.Pause(ctx, name); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// Resume 恢复队列
+func (s *QueueService) Resume(ctx context.Context, name string) error {
+	if err := s.manager.Resume(ctx, name); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// PublishTo 定时向 QueueEventGroup 组推送队列监控概览，管理操作后立即推送
+func (s *QueueService) PublishTo(events *sse.Manager) {
+	s.mu.Lock()
+	s.events = events
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(queueFeedInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			// 没有订阅者时不查询
+			if events.GroupSize(QueueEventGroup) == 0 {
+				continue
+			}
+			s.publish()
+		}
+	}()
+}
+
+// notify 管理操作后异步推送最新概览
+func (s *QueueService) notify() {
+	s.mu.RLock()
+	events := s.events
+	s.mu.RUnlock()
+
+	if events != nil {
+		go s.publish()
+	}
+}
+
+// publish 推送队列监控概览
+func (s *QueueService) publish() {
+	s.mu.RLock()
+	events := s.events
+	s.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), queueFeedInterval)
+	defer cancel()
+
+	overview, err := s.Overview(ctx, DefaultMetricsWindow)
+	if err != nil {
+		log.Printf("[ERROR] Failed to collect queue overview: %v", err)
+		return
+	}
+	events.SendToGroup(QueueEventGroup, sse.EventTypeQueue, overview)
+}
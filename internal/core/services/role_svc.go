@@ -13,19 +13,21 @@ type RoleService struct {
 }
 
 type CreateRoleRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Code        string `json:"code" binding:"required"`
-	Description string `json:"description"`
-	Status      int    `json:"status"`
-	MenuIDs     []uint `json:"menu_ids"`
+	Name             string `json:"name" binding:"required"`
+	Code             string `json:"code" binding:"required"`
+	Description      string `json:"description"`
+	Status           int    `json:"status"`
+	RequireTwoFactor bool   `json:"require_two_factor"`
+	MenuIDs          []uint `json:"menu_ids"`
 }
 
 type UpdateRoleRequest struct {
-	Name        string `json:"name"`
-	Code        string `json:"code"`
-	Description string `json:"description"`
-	Status      int    `json:"status"`
-	MenuIDs     []uint `json:"menu_ids"`
+	Name             string `json:"name"`
+	Code             string `json:"code"`
+	Description      string `json:"description"`
+	Status           int    `json:"status"`
+	RequireTwoFactor *bool  `json:"require_two_factor"`
+	MenuIDs          []uint `json:"menu_ids"`
 }
 
 type UpdateRoleMenusRequest struct {
@@ -64,10 +66,11 @@ func (s *RoleService) Create(ctx context.Context, req *CreateRoleRequest) (*mode
 	var result *models.Role
 	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		role := &models.Role{
-			Name:        req.Name,
-			Code:        req.Code,
-			Description: req.Description,
-			Status:      req.Status,
+			Name:             req.Name,
+			Code:             req.Code,
+			Description:      req.Description,
+			Status:           req.Status,
+			RequireTwoFactor: req.RequireTwoFactor,
 		}
 
 		// Create role
@@ -124,6 +127,9 @@ func (s *RoleService) Update(ctx context.Context, id uint, req *UpdateRoleReques
 		if req.Status != 0 {
 			role.Status = req.Status
 		}
+		if req.RequireTwoFactor != nil {
+			role.RequireTwoFactor = *req.RequireTwoFactor
+		}
 
 		if err := tx.Save(&role).Error; err != nil {
 			return err
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"app/internal/core/models"
+)
+
+type ScheduleRunService struct {
+	runRepo ScheduleRunRepository
+}
+
+func NewScheduleRunService(runRepo ScheduleRunRepository) *ScheduleRunService {
+	return &ScheduleRunService{
+		runRepo: runRepo,
+	}
+}
+
+type ScheduleRunQuery struct {
+	TaskName  string    `form:"task_name"`
+	Status    string    `form:"status"`
+	Host      string    `form:"host"`
+	StartTime time.Time `form:"start_time" time_format:"2006-01-02 15:04:05"`
+	EndTime   time.Time `form:"end_time" time_format:"2006-01-02 15:04:05"`
+}
+
+// RecordRun stores the result of a scheduled task run
+func (s *ScheduleRunService) RecordRun(ctx context.Context, run *models.ScheduleRun) error {
+	return s.runRepo.CreateRun(ctx, run)
+}
+
+// PruneRuns deletes the runs started before the given time
+func (s *ScheduleRunService) PruneRuns(ctx context.Context, before time.Time) (int64, error) {
+	return s.runRepo.DeleteRunsBefore(ctx, before)
+}
+
+// ListRuns retrieves a paginated list of schedule runs, without their output
+func (s *ScheduleRunService) ListRuns(ctx context.Context, pagination *models.Pagination, query *ScheduleRunQuery) ([]models.ScheduleRun, error) {
+	conditions := make(map[string]interface{})
+
+	if query != nil {
+		if query.TaskName != "" {
+			conditions["task_name = ?"] = query.TaskName
+		}
+		if query.Status != "" {
+			conditions["status = ?"] = query.Status
+		}
+		if query.Host != "" {
+			conditions["host = ?"] = query.Host
+		}
+		if !query.StartTime.IsZero() {
+			conditions["started_at >= ?"] = query.StartTime
+		}
+		if !query.EndTime.IsZero() {
+			conditions["started_at <= ?"] = query.EndTime
+		}
+	}
+
+	return s.runRepo.ListRuns(ctx, pagination, conditions)
+}
+
+// GetRun retrieves a schedule run with its captured output
+func (s *ScheduleRunService) GetRun(ctx context.Context, id uint) (*models.ScheduleRun, error) {
+	return s.runRepo.FindRunByID(ctx, id)
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"app/internal/core/models"
+	"app/internal/schedule"
+	"app/pkg/console"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrScheduledTaskNotFound = errors.New("scheduled task not found")
+	ErrInvalidSchedule       = errors.New("invalid schedule")
+)
+
+type ScheduledTaskService struct {
+	taskRepo ScheduledTaskRepository
+	commands *console.Manager
+}
+
+func NewScheduledTaskService(taskRepo ScheduledTaskRepository) *ScheduledTaskService {
+	return &ScheduledTaskService{
+		taskRepo: taskRepo,
+	}
+}
+
+// SetCommands sets the console commands tasks can run, the command and
+// arguments of a task are checked against them when it is saved
+func (s *ScheduledTaskService) SetCommands(commands *console.Manager) {
+	s.commands = commands
+}
+
+type CreateScheduledTaskRequest struct {
+	Command     string   `json:"command" binding:"required,max=100"`
+	Args        []string `json:"args"`
+	Expression  string   `json:"expression" binding:"required,max=100"`
+	Timezone    string   `json:"timezone" binding:"max=64"`
+	Enabled     *bool    `json:"enabled"`
+	Unique      bool     `json:"unique"`
+	Description string   `json:"description" binding:"max=255"`
+}
+
+type UpdateScheduledTaskRequest struct {
+	Command     string   `json:"command" binding:"max=100"`
+	Args        []string `json:"args"`
+	Expression  string   `json:"expression" binding:"max=100"`
+	Timezone    *string  `json:"timezone" binding:"omitempty,max=64"`
+	Enabled     *bool    `json:"enabled"`
+	Unique      *bool    `json:"unique"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+}
+
+// ListTasks retrieves a paginated list of scheduled tasks
+func (s *ScheduledTaskService) ListTasks(ctx context.Context, pagination *models.Pagination) ([]models.ScheduledTask, error) {
+	return s.taskRepo.ListTasks(ctx, pagination)
+}
+
+// GetTask retrieves a scheduled task by ID
+func (s *ScheduledTaskService) GetTask(ctx context.Context, id uint) (*models.ScheduledTask, error) {
+	task, err := s.taskRepo.FindTaskByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrScheduledTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// CreateTask creates a scheduled task, enabled unless stated otherwise
+func (s *ScheduledTaskService) CreateTask(ctx context.Context, req *CreateScheduledTaskRequest) (*models.ScheduledTask, error) {
+	task := &models.ScheduledTask{
+		Command:     strings.TrimSpace(req.Command),
+		Args:        req.Args,
+		Expression:  strings.TrimSpace(req.Expression),
+		Timezone:    strings.TrimSpace(req.Timezone),
+		Enabled:     true,
+		Unique:      req.Unique,
+		Description: req.Description,
+	}
+	if req.Enabled != nil {
+		task.Enabled = *req.Enabled
+	}
+
+	if err := s.validateScheduledTask(task); err != nil {
+		return nil, err
+	}
+	if err := s.taskRepo.CreateTask(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// UpdateTask updates a scheduled task, the schedulers pick the change up on their next sync
+func (s *ScheduledTaskService) UpdateTask(ctx context.Context, id uint, req *UpdateScheduledTaskRequest) (*models.ScheduledTask, error) {
+	task, err := s.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Command != "" {
+		task.Command = strings.TrimSpace(req.Command)
+	}
+	if req.Args != nil {
+		task.Args = req.Args
+	}
+	if req.Expression != "" {
+		task.Expression = strings.TrimSpace(req.Expression)
+	}
+	if req.Timezone != nil {
+		task.Timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if req.Enabled != nil {
+		task.Enabled = *req.Enabled
+	}
+	if req.Unique != nil {
+		task.Unique = *req.Unique
+	}
+	if req.Description != nil {
+		task.Description = *req.Description
+	}
+
+	if err := s.validateScheduledTask(task); err != nil {
+		return nil, err
+	}
+	if err := s.taskRepo.UpdateTask(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// DeleteTask deletes a scheduled task
+func (s *ScheduledTaskService) DeleteTask(ctx context.Context, id uint) error {
+	if _, err := s.GetTask(ctx, id); err != nil {
+		return err
+	}
+	return s.taskRepo.DeleteTask(ctx, id)
+}
+
+// RequestRun asks the schedulers to run a task now. The first scheduler to
+// sync claims the request and runs the task with the same locking as its
+// scheduled runs, disabled tasks can be run this way too.
+func (s *ScheduledTaskService) RequestRun(ctx context.Context, id uint) error {
+	if _, err := s.GetTask(ctx, id); err != nil {
+		return err
+	}
+	return s.taskRepo.SetRunRequest(ctx, id, time.Now().Truncate(time.Second))
+}
+
+// EnabledTasks returns the tasks to schedule, it implements schedule.TaskStore
+func (s *ScheduledTaskService) EnabledTasks(ctx context.Context) ([]models.ScheduledTask, error) {
+	return s.taskRepo.ListEnabledTasks(ctx)
+}
+
+// ClaimRunRequests returns the pending run requests this process won, it implements schedule.TaskStore
+func (s *ScheduledTaskService) ClaimRunRequests(ctx context.Context) ([]models.ScheduledTask, error) {
+	tasks, err := s.taskRepo.ListRunRequests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := tasks[:0]
+	for _, task := range tasks {
+		ok, err := s.taskRepo.ClearRunRequest(ctx, task.ID, *task.RunRequestedAt)
+		if err != nil {
+			return claimed, err
+		}
+		if ok {
+			claimed = append(claimed, task)
+		}
+	}
+	return claimed, nil
+}
+
+// validateScheduledTask checks the command, arguments, expression and timezone
+// of a task, so a task the schedulers would skip is never saved
+func (s *ScheduledTaskService) validateScheduledTask(task *models.ScheduledTask) error {
+	if task.Command == "" {
+		return fmt.Errorf("%w: command is required", ErrInvalidSchedule)
+	}
+	if s.commands != nil {
+		if _, err := s.commands.Parse(task.Command, task.Args); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+	}
+	if err := schedule.ValidateExpression(task.Expression, task.Timezone); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	return nil
+}
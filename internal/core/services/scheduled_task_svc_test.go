@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"app/internal/core/models"
+	"app/pkg/console"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScheduledTaskRepository keeps scheduled tasks in memory
+type fakeScheduledTaskRepository struct {
+	ScheduledTaskRepository
+	tasks map[uint]models.ScheduledTask
+}
+
+func (r *fakeScheduledTaskRepository) CreateTask(ctx context.Context, task *models.ScheduledTask) error {
+	task.ID = uint(len(r.tasks) + 1)
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *fakeScheduledTaskRepository) UpdateTask(ctx context.Context, task *models.ScheduledTask) error {
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *fakeScheduledTaskRepository) FindTaskByID(ctx context.Context, id uint) (*models.ScheduledTask, error) {
+	task := r.tasks[id]
+	return &task, nil
+}
+
+// pruneCommand is a schedulable command with a required argument
+type pruneCommand struct {
+	*console.BaseCommand
+}
+
+func (c *pruneCommand) Configure(config *console.CommandConfig) {
+	config.Name = "logs:prune"
+	config.Arguments = []console.Argument{{Name: "table", Required: true}}
+	config.Options = []console.Option{{Name: "days", Default: "7"}}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *pruneCommand) Handle(ctx context.Context) error {
+	return nil
+}
+
+func newTestScheduledTaskService() *ScheduledTaskService {
+	commands := console.NewManager()
+	commands.Register(&pruneCommand{BaseCommand: console.NewCommand("logs:prune", "")})
+
+	svc := NewScheduledTaskService(&fakeScheduledTaskRepository{tasks: make(map[uint]models.ScheduledTask)})
+	svc.SetCommands(commands)
+	return svc
+}
+
+func TestCreateTaskValidatesCommandAndArgs(t *testing.T) {
+	svc := newTestScheduledTaskService()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		wantErr bool
+	}{
+		{name: "valid", command: "logs:prune", args: []string{"login_logs", "--days=30"}},
+		{name: "unknown command", command: "logs:purge", args: []string{"login_logs"}, wantErr: true},
+		{name: "missing argument", command: "logs:prune", wantErr: true},
+		{name: "unknown option", command: "logs:prune", args: []string{"login_logs", "--weeks=1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateTask(ctx, &CreateScheduledTaskRequest{
+				Command:    tt.command,
+				Args:       tt.args,
+				Expression: "@daily",
+			})
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSchedule)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUpdateTaskValidatesArgs(t *testing.T) {
+	svc := newTestScheduledTaskService()
+	ctx := context.Background()
+
+	task, err := svc.CreateTask(ctx, &CreateScheduledTaskRequest{
+		Command:    "logs:prune",
+		Args:       []string{"login_logs"},
+		Expression: "@daily",
+	})
+	require.NoError(t, err)
+
+	_, err = svc.UpdateTask(ctx, task.ID, &UpdateScheduledTaskRequest{Args: []string{"login_logs", "--days"}})
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	updated, err := svc.UpdateTask(ctx, task.ID, &UpdateScheduledTaskRequest{Args: []string{"login_logs", "--days=30"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"login_logs", "--days=30"}, updated.Args)
+}
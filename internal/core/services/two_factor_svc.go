@@ -0,0 +1,309 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"image/png"
+	"strings"
+	"time"
+
+	"app/internal/config"
+	"app/internal/core/models"
+
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled = errors.New("two-factor enrolment has not been started")
+	ErrTwoFactorRequired    = errors.New("two-factor authentication is required for the roles of the user")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+)
+
+const (
+	// totpPeriod is the lifetime of a TOTP code, the RFC 6238 default
+	totpPeriod = 30
+	// totpSkew is how many periods before and after now a code is accepted
+	totpSkew = 1
+	// recoveryCodeCount is how many recovery codes a user gets
+	recoveryCodeCount = 10
+	// qrCodeSize is the width and height of the enrolment QR code in pixels
+	qrCodeSize = 200
+)
+
+type TwoFactorService struct {
+	repo   TwoFactorRepository
+	config *config.Config
+}
+
+func NewTwoFactorService(repo TwoFactorRepository, config *config.Config) *TwoFactorService {
+	return &TwoFactorService{
+		repo:   repo,
+		config: config,
+	}
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorStatus describes the two-factor authentication of a user
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"`
+	ConfirmedAt       *time.Time `json:"confirmed_at"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrolment is what an authenticator app needs to add the account
+type TwoFactorEnrolment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"` // PNG data URI of the otpauth URI
+}
+
+// State reports whether a user turned two-factor authentication on and
+// whether a role of the user requires it
+func (s *TwoFactorService) State(ctx context.Context, userID uint) (enabled, required bool, err error) {
+	twoFactor, err := s.findTwoFactor(ctx, userID)
+	if err != nil {
+		return false, false, err
+	}
+	required, err = s.repo.RequiresTwoFactor(ctx, userID)
+	if err != nil {
+		return false, false, err
+	}
+	return twoFactor != nil && twoFactor.Enabled(), required, nil
+}
+
+// Status returns the two-factor authentication status of a user
+func (s *TwoFactorService) Status(ctx context.Context, userID uint) (*TwoFactorStatus, error) {
+	twoFactor, err := s.findTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.repo.RequiresTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TwoFactorStatus{Required: required}
+	if twoFactor != nil && twoFactor.Enabled() {
+		status.Enabled = true
+		status.ConfirmedAt = twoFactor.ConfirmedAt
+		if status.RecoveryCodesLeft, err = s.repo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// Enrol generates a new secret for a user. Two-factor authentication is on
+// once a code of the secret is confirmed, enrolling again replaces an
+// unconfirmed secret.
+func (s *TwoFactorService) Enrol(ctx context.Context, user *models.User) (*TwoFactorEnrolment, error) {
+	twoFactor, err := s.findTwoFactor(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor != nil && twoFactor.Enabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.issuer(),
+		AccountName: user.Username,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return nil, err
+	}
+	var qrCode bytes.Buffer
+	if err := png.Encode(&qrCode, image); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveTwoFactor(ctx, &models.UserTwoFactor{UserID: user.ID, Secret: key.Secret()}); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrolment{
+		Secret:     key.Secret(),
+		OTPAuthURI: key.URL(),
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode.Bytes()),
+	}, nil
+}
+
+// Confirm turns two-factor authentication on with a first code of the
+// enrolled secret and returns the recovery codes, they are only shown once
+func (s *TwoFactorService) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	twoFactor, err := s.findTwoFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if twoFactor.Enabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	now := time.Now()
+	step, ok := matchTOTP(twoFactor.Secret, code, now)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := s.repo.EnableTwoFactor(ctx, twoFactor, step, hashes, now)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrTwoFactorEnabled
+	}
+	return codes, nil
+}
+
+// Verify checks a TOTP code or a recovery code of a user, each code is
+// accepted once
+func (s *TwoFactorService) Verify(ctx context.Context, userID uint, code string) error {
+	twoFactor, err := s.findTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if twoFactor == nil || !twoFactor.Enabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	now := time.Now()
+	if step, ok := matchTOTP(twoFactor.Secret, code, now); ok {
+		used, err := s.repo.UseTwoFactorStep(ctx, twoFactor.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			// The code or a later one was accepted already
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code), now)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// Disable turns two-factor authentication off after checking a code, unless
+// a role of the user requires it
+func (s *TwoFactorService) Disable(ctx context.Context, userID uint, code string) error {
+	required, err := s.repo.RequiresTwoFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequired
+	}
+
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+	return s.repo.DeleteTwoFactor(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user after
+// checking a code
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// findTwoFactor returns the secret of a user, nil when there is none
+func (s *TwoFactorService) findTwoFactor(ctx context.Context, userID uint) (*models.UserTwoFactor, error) {
+	twoFactor, err := s.repo.FindTwoFactor(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return twoFactor, err
+}
+
+// issuer names the account in authenticator apps
+func (s *TwoFactorService) issuer() string {
+	if s.config != nil && s.config.App.Name != "" {
+		return s.config.App.Name
+	}
+	return "Admin"
+}
+
+// matchTOTP checks a code against the periods around now and returns the
+// time step of the matching period
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+		expected, err := totp.GenerateCode(secret, at)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return at.Unix() / totpPeriod, true
+		}
+	}
+	return 0, false
+}
+
+// newRecoveryCodes generates recovery codes formatted as xxxxx-xxxxx and their hashes
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))[:10]
+		code = code[:5] + "-" + code[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode returns the hash stored in place of a recovery code,
+// ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app/internal/core/models"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeTwoFactorRepository keeps secrets and recovery codes in memory
+type fakeTwoFactorRepository struct {
+	twoFactors    map[uint]*models.UserTwoFactor
+	recoveryCodes map[uint]map[string]bool // hash => used
+	required      map[uint]bool
+}
+
+func newFakeTwoFactorRepository() *fakeTwoFactorRepository {
+	return &fakeTwoFactorRepository{
+		twoFactors:    make(map[uint]*models.UserTwoFactor),
+		recoveryCodes: make(map[uint]map[string]bool),
+		required:      make(map[uint]bool),
+	}
+}
+
+func (r *fakeTwoFactorRepository) FindTwoFactor(ctx context.Context, userID uint) (*models.UserTwoFactor, error) {
+	twoFactor, ok := r.twoFactors[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *twoFactor
+	return &copied, nil
+}
+
+func (r *fakeTwoFactorRepository) SaveTwoFactor(ctx context.Context, twoFactor *models.UserTwoFactor) error {
+	twoFactor.ID = twoFactor.UserID
+	r.twoFactors[twoFactor.UserID] = twoFactor
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) EnableTwoFactor(ctx context.Context, twoFactor *models.UserTwoFactor, step int64, codeHashes []string, at time.Time) (bool, error) {
+	stored := r.twoFactors[twoFactor.UserID]
+	if stored.ConfirmedAt != nil {
+		return false, nil
+	}
+	stored.ConfirmedAt = &at
+	stored.LastUsedStep = step
+	return true, r.ReplaceRecoveryCodes(ctx, twoFactor.UserID, codeHashes)
+}
+
+func (r *fakeTwoFactorRepository) DeleteTwoFactor(ctx context.Context, userID uint) error {
+	delete(r.twoFactors, userID)
+	delete(r.recoveryCodes, userID)
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) UseTwoFactorStep(ctx context.Context, id uint, step int64) (bool, error) {
+	stored := r.twoFactors[id]
+	if stored.LastUsedStep >= step {
+		return false, nil
+	}
+	stored.LastUsedStep = step
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	r.recoveryCodes[userID] = make(map[string]bool)
+	for _, hash := range codeHashes {
+		r.recoveryCodes[userID][hash] = false
+	}
+	return nil
+}
+
+func (r *fakeTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error) {
+	used, ok := r.recoveryCodes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	for _, used := range r.recoveryCodes[userID] {
+		if !used {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeTwoFactorRepository) RequiresTwoFactor(ctx context.Context, userID uint) (bool, error) {
+	return r.required[userID], nil
+}
+
+// codeAt returns the TOTP code of the enrolled secret of a user
+func codeAt(t *testing.T, repo *fakeTwoFactorRepository, userID uint, at time.Time) string {
+	code, err := totp.GenerateCode(repo.twoFactors[userID].Secret, at)
+	require.NoError(t, err)
+	return code
+}
+
+func TestTwoFactorEnrolment(t *testing.T) {
+	repo := newFakeTwoFactorRepository()
+	svc := NewTwoFactorService(repo, nil)
+	ctx := context.Background()
+	user := &models.User{ID: 1, Username: "admin"}
+
+	_, err := svc.Confirm(ctx, 1, "123456")
+	assert.ErrorIs(t, err, ErrTwoFactorNotEnrolled)
+
+	enrolment, err := svc.Enrol(ctx, user)
+	require.NoError(t, err)
+	assert.Contains(t, enrolment.OTPAuthURI, "otpauth://totp/Admin:admin?")
+	assert.Contains(t, enrolment.OTPAuthURI, "secret="+enrolment.Secret)
+	assert.Contains(t, enrolment.QRCode, "data:image/png;base64,")
+
+	// Not on before a code confirms the enrolment
+	enabled, _, err := svc.State(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	_, err = svc.Confirm(ctx, 1, "000000x")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	now := time.Now()
+	codes, err := svc.Confirm(ctx, 1, codeAt(t, repo, 1, now))
+	require.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, codes[0])
+
+	status, err := svc.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, int64(recoveryCodeCount), status.RecoveryCodesLeft)
+
+	_, err = svc.Enrol(ctx, user)
+	assert.ErrorIs(t, err, ErrTwoFactorEnabled)
+}
+
+func TestTwoFactorVerify(t *testing.T) {
+	repo := newFakeTwoFactorRepository()
+	svc := NewTwoFactorService(repo, nil)
+	ctx := context.Background()
+
+	_, err := svc.Enrol(ctx, &models.User{ID: 1, Username: "admin"})
+	require.NoError(t, err)
+	now := time.Now()
+	codes, err := svc.Confirm(ctx, 1, codeAt(t, repo, 1, now.Add(-totpPeriod*time.Second)))
+	require.NoError(t, err)
+
+	// Each code is accepted once, as are codes of earlier periods
+	next := codeAt(t, repo, 1, now)
+	require.NoError(t, svc.Verify(ctx, 1, next))
+	assert.ErrorIs(t, svc.Verify(ctx, 1, next), ErrInvalidTwoFactorCode)
+
+	// Recovery codes are single use and forgiving about their format
+	require.NoError(t, svc.Verify(ctx, 1, " "+codes[0]+" "))
+	assert.ErrorIs(t, svc.Verify(ctx, 1, codes[0]), ErrInvalidTwoFactorCode)
+	status, err := svc.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(recoveryCodeCount-1), status.RecoveryCodesLeft)
+
+	// A role requiring two-factor authentication keeps it on
+	repo.required[1] = true
+	assert.ErrorIs(t, svc.Disable(ctx, 1, codes[1]), ErrTwoFactorRequired)
+	repo.required[1] = false
+	require.NoError(t, svc.Disable(ctx, 1, codes[1]))
+	assert.ErrorIs(t, svc.Verify(ctx, 1, codes[2]), ErrTwoFactorNotEnabled)
+}
@@ -22,6 +22,7 @@ var (
 	ErrInvalidUserStatus = errors.New("invalid user status")
 	ErrSuperAdminModify  = errors.New("super admin account cannot be modified")
 	ErrSuperAdminDelete  = errors.New("super admin account cannot be deleted")
+	ErrIncorrectPassword = errors.New("old password is incorrect")
 )
 
 type LogServiceInterface interface {
@@ -33,6 +34,7 @@ type LogServiceInterface interface {
 
 type AuthServiceInterface interface {
 	IsSuperAdmin(userID uint) bool
+	RevokeUserTokens(ctx context.Context, userID uint, endSessions bool) error
 }
 
 type UserService struct {
@@ -341,7 +343,7 @@ func (s *UserService) ChangePassword(ctx context.Context, id uint, req *ChangePa
 
 	// Verify old password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
-		return errors.New("old password is incorrect")
+		return ErrIncorrectPassword
 	}
 
 	// Hash new password
@@ -355,6 +357,9 @@ func (s *UserService) ChangePassword(ctx context.Context, id uint, req *ChangePa
 		return err
 	}
 
+	// Sign out every device that signed in with the old password
+	s.revokeTokens(ctx, user.ID, true)
+
 	// Record operation log
 	if s.logSvc != nil {
 		s.logSvc.RecordOperationLog(ctx, &models.OperationLog{
@@ -394,6 +399,11 @@ func (s *UserService) UpdateStatus(ctx context.Context, id uint, status int) err
 		return err
 	}
 
+	// A disabled user is signed out at once
+	if status == 0 {
+		s.revokeTokens(ctx, user.ID, true)
+	}
+
 	// Record operation log
 	if s.logSvc != nil {
 		s.logSvc.RecordOperationLog(ctx, &models.OperationLog{
@@ -475,7 +485,7 @@ func (s *UserService) UpdateUserRoles(ctx context.Context, userID uint, roleIDs
 		return ErrSuperAdminModify
 	}
 
-	return s.userRepo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	err := s.userRepo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Check if user exists
 		user, err := s.userRepo.FindByID(ctx, userID)
 		if err != nil {
@@ -528,4 +538,56 @@ func (s *UserService) UpdateUserRoles(ctx context.Context, userID uint, roleIDs
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Access tokens issued for the old roles are refreshed
+	s.revokeTokens(ctx, userID, false)
+	return nil
+}
+
+// ForceLogout signs a user out of every device
+func (s *UserService) ForceLogout(ctx context.Context, id uint) error {
+	if s.IsSuperAdmin(id) {
+		return ErrSuperAdminModify
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if s.authSvc != nil {
+		if err := s.authSvc.RevokeUserTokens(ctx, user.ID, true); err != nil {
+			return err
+		}
+	}
+
+	// Record operation log
+	if s.logSvc != nil {
+		s.logSvc.RecordOperationLog(ctx, &models.OperationLog{
+			UserID:       user.ID,
+			Username:     user.Username,
+			Action:       "force_logout",
+			Module:       "user",
+			BusinessID:   strconv.FormatUint(uint64(user.ID), 10),
+			BusinessType: "user",
+			Status:       1,
+			ErrorMessage: "",
+		})
+	}
+
+	return nil
+}
+
+// revokeTokens revokes the tokens of a user after a change to the account,
+// failures are only logged as the change itself succeeded
+func (s *UserService) revokeTokens(ctx context.Context, userID uint, endSessions bool) {
+	if s.authSvc == nil {
+		return
+	}
+	if err := s.authSvc.RevokeUserTokens(ctx, userID, endSessions); err != nil {
+		log.Printf("[ERROR] Failed to revoke the tokens of user %d: %v", userID, err)
+	}
 }
@@ -12,6 +12,7 @@ const (
 	EventTypeNotification = "notification"
 	EventTypeAlert        = "alert"
 	EventTypeUpdate       = "update"
+	EventTypeQueue        = "queue"
 )
 
 // Event represents a server-sent event
@@ -198,6 +199,14 @@ func (m *Manager) LeaveGroup(groupID, userID string) {
 	}
 }
 
+// GroupSize returns the number of members in a group
+func (m *Manager) GroupSize(groupID string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.groups[groupID])
+}
+
 // SendEvent sends an event to the specified target(s)
 func (m *Manager) SendEvent(event *Event) {
 	if event.ID == "" {
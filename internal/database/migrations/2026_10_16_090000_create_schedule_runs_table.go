@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	up := func(tx *gorm.DB) error {
+		type ScheduleRun struct {
+			ID         uint      `gorm:"primarykey"`
+			TaskName   string    `gorm:"size:100;index;comment:'任务名称'"`
+			Host       string    `gorm:"size:100;comment:'执行主机'"`
+			Status     string    `gorm:"size:20;index;comment:'状态：success-成功，failed-失败'"`
+			Error      string    `gorm:"type:text;comment:'错误信息'"`
+			Output     string    `gorm:"type:mediumtext;comment:'命令输出'"`
+			StartedAt  time.Time `gorm:"type:timestamp;index;not null;comment:'开始时间'"`
+			FinishedAt time.Time `gorm:"type:timestamp;not null;comment:'结束时间'"`
+			Duration   int64     `gorm:"comment:'执行时长(毫秒)'"`
+		}
+
+		return tx.Table("schedule_runs").AutoMigrate(&ScheduleRun{})
+	}
+
+	down := func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("schedule_runs")
+	}
+
+	Register("create_schedule_runs_table", NewMigration("2026_10_16_090000_create_schedule_runs_table.go", up, down))
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	up := func(tx *gorm.DB) error {
+		type ScheduledTask struct {
+			ID             uint           `gorm:"primarykey"`
+			Command        string         `gorm:"size:100;not null;comment:'命令名称'"`
+			Args           string         `gorm:"type:text;comment:'命令参数(JSON数组)'"`
+			Expression     string         `gorm:"size:100;not null;comment:'Cron表达式'"`
+			Timezone       string         `gorm:"size:64;comment:'时区'"`
+			Enabled        bool           `gorm:"not null;index;comment:'是否启用'"`
+			Unique         bool           `gorm:"not null;comment:'是否只在一台服务器上运行'"`
+			Description    string         `gorm:"size:255;comment:'描述'"`
+			RunRequestedAt *time.Time     `gorm:"type:timestamp;index;comment:'请求立即执行的时间'"`
+			CreatedAt      time.Time      `gorm:"type:timestamp"`
+			UpdatedAt      time.Time      `gorm:"type:timestamp"`
+			DeletedAt      gorm.DeletedAt `gorm:"index;type:timestamp"`
+		}
+
+		return tx.Table("scheduled_tasks").AutoMigrate(&ScheduledTask{})
+	}
+
+	down := func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("scheduled_tasks")
+	}
+
+	Register("create_scheduled_tasks_table", NewMigration("2026_10_16_120000_create_scheduled_tasks_table.go", up, down))
+}
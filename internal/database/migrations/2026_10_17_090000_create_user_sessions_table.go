@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	up := func(tx *gorm.DB) error {
+		type UserSession struct {
+			ID            uint       `gorm:"primarykey"`
+			UserID        uint       `gorm:"index;not null;comment:'用户ID'"`
+			FamilyID      string     `gorm:"size:36;index;not null;comment:'会话ID，轮换后的刷新令牌属于同一会话'"`
+			TokenHash     string     `gorm:"size:64;uniqueIndex;not null;comment:'刷新令牌SHA-256哈希'"`
+			IP            string     `gorm:"size:45;comment:'登录IP'"`
+			UserAgent     string     `gorm:"size:255;comment:'客户端标识'"`
+			ExpiresAt     time.Time  `gorm:"type:timestamp;not null;comment:'会话绝对过期时间'"`
+			IdleExpiresAt time.Time  `gorm:"type:timestamp;not null;comment:'令牌空闲过期时间'"`
+			RotatedAt     *time.Time `gorm:"type:timestamp;comment:'令牌被轮换的时间'"`
+			RevokedAt     *time.Time `gorm:"type:timestamp;index;comment:'令牌被吊销的时间'"`
+			CreatedAt     time.Time  `gorm:"type:timestamp"`
+		}
+
+		return tx.Table("user_sessions").AutoMigrate(&UserSession{})
+	}
+
+	down := func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("user_sessions")
+	}
+
+	Register("create_user_sessions_table", NewMigration("2026_10_17_090000_create_user_sessions_table.go", up, down))
+}
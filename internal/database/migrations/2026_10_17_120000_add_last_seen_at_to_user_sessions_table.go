@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	up := func(tx *gorm.DB) error {
+		type UserSession struct {
+			LastSeenAt *time.Time `gorm:"type:timestamp;comment:'最近活跃时间'"`
+		}
+
+		return tx.Table("user_sessions").AutoMigrate(&UserSession{})
+	}
+
+	down := func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn("user_sessions", "last_seen_at")
+	}
+
+	Register("add_last_seen_at_to_user_sessions_table", NewMigration("2026_10_17_120000_add_last_seen_at_to_user_sessions_table.go", up, down))
+}
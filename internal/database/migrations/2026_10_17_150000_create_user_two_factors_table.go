@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	up := func(tx *gorm.DB) error {
+		type UserTwoFactor struct {
+			ID           uint       `gorm:"primarykey"`
+			UserID       uint       `gorm:"uniqueIndex;not null;comment:'用户ID'"`
+			Secret       string     `gorm:"size:64;not null;comment:'TOTP密钥'"`
+			ConfirmedAt  *time.Time `gorm:"type:timestamp;comment:'启用时间，为空表示尚未确认'"`
+			LastUsedStep int64      `gorm:"not null;default:0;comment:'最近一次通过验证的时间步，防止验证码重放'"`
+			CreatedAt    time.Time  `gorm:"type:timestamp"`
+			UpdatedAt    time.Time  `gorm:"type:timestamp"`
+		}
+
+		type UserRecoveryCode struct {
+			ID        uint       `gorm:"primarykey"`
+			UserID    uint       `gorm:"index;not null;comment:'用户ID'"`
+			CodeHash  string     `gorm:"size:64;not null;comment:'恢复码SHA-256哈希'"`
+			UsedAt    *time.Time `gorm:"type:timestamp;comment:'使用时间'"`
+			CreatedAt time.Time  `gorm:"type:timestamp"`
+		}
+
+		if err := tx.Table("user_two_factors").AutoMigrate(&UserTwoFactor{}); err != nil {
+			return err
+		}
+		return tx.Table("user_recovery_codes").AutoMigrate(&UserRecoveryCode{})
+	}
+
+	down := func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropTable("user_recovery_codes"); err != nil {
+			return err
+		}
+		return tx.Migrator().DropTable("user_two_factors")
+	}
+
+	Register("create_user_two_factors_table", NewMigration("2026_10_17_150000_create_user_two_factors_table.go", up, down))
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+func init() {
+	up := func(tx *gorm.DB) error {
+		type Role struct {
+			RequireTwoFactor bool `gorm:"not null;default:false;comment:'该角色的用户必须启用两步验证'"`
+		}
+
+		return tx.Table("roles").AutoMigrate(&Role{})
+	}
+
+	down := func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn("roles", "require_two_factor")
+	}
+
+	Register("add_require_two_factor_to_roles_table", NewMigration("2026_10_17_150100_add_require_two_factor_to_roles_table.go", up, down))
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// The login lockout counts the recent failures of a username or an IP
+var loginLogLockoutIndexes = map[string]string{
+	"idx_login_logs_username_login_time": "CREATE INDEX idx_login_logs_username_login_time ON login_logs(username, login_time)",
+	"idx_login_logs_ip_login_time":       "CREATE INDEX idx_login_logs_ip_login_time ON login_logs(ip, login_time)",
+}
+
+func init() {
+	up := func(tx *gorm.DB) error {
+		for name, statement := range loginLogLockoutIndexes {
+			if tx.Migrator().HasIndex("login_logs", name) {
+				continue
+			}
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	down := func(tx *gorm.DB) error {
+		for name := range loginLogLockoutIndexes {
+			if !tx.Migrator().HasIndex("login_logs", name) {
+				continue
+			}
+			if err := tx.Migrator().DropIndex("login_logs", name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	Register("add_lockout_indexes_to_login_logs_table", NewMigration("2026_10_17_170000_add_lockout_indexes_to_login_logs_table.go", up, down))
+}
@@ -2,6 +2,7 @@ package migrations
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -182,54 +183,45 @@ func (m *Migrator) Rollback() error {
 		return nil
 	}
 
+	return m.RollbackBatch(lastBatch)
+}
+
+// RollbackBatch rolls back the migrations of the given batch
+func (m *Migrator) RollbackBatch(batch int) error {
 	var migrations []MigrationRecord
-	if err := m.db.Where("batch = ?", lastBatch).Order("id DESC").Find(&migrations).Error; err != nil {
+	if err := m.db.Where("batch = ?", batch).Order("id DESC").Find(&migrations).Error; err != nil {
 		return err
 	}
 
 	if len(migrations) == 0 {
-		fmt.Printf("ℹ️  \033[33mNo migrations found for batch %d\033[0m\n", lastBatch)
+		fmt.Printf("ℹ️  \033[33mNo migrations found for batch %d\033[0m\n", batch)
 		return nil
 	}
 
-	fmt.Printf("🔄 \033[34mRolling back %d migration(s) from batch %d...\033[0m\n", len(migrations), lastBatch)
-	fmt.Println("────────────────────────────────────────")
-
-	for i, migration := range migrations {
-		fmt.Printf("📄 \033[33m[%d/%d]\033[0m %s", i+1, len(migrations), migration.Name)
-
-		// Find the migration definition
-		var def Migration
-		for _, m := range m.migrations {
-			if m.Name == migration.Name {
-				def = m.Definition
-				break
-			}
-		}
-
-		if def != nil {
-			err := m.db.Transaction(func(tx *gorm.DB) error {
-				// Run down migration
-				if err := def.Down(tx); err != nil {
-					return err
-				}
-
-				// Remove migration record
-				return tx.Delete(&migration).Error
-			})
+	fmt.Printf("🔄 \033[34mRolling back %d migration(s) from batch %d...\033[0m\n", len(migrations), batch)
+	if err := m.rollback(migrations, "ROLLED BACK"); err != nil {
+		return err
+	}
+	fmt.Printf("🎉 \033[32mRollback completed successfully!\033[0m\n")
+	return nil
+}
 
-			if err != nil {
-				fmt.Printf(" \033[31m✗ FAILED\033[0m\n")
-				return fmt.Errorf("failed to rollback migration %s: %w", migration.Name, err)
-			}
+// RollbackSteps rolls back the last steps migrations, across batches
+func (m *Migrator) RollbackSteps(steps int) error {
+	var migrations []MigrationRecord
+	if err := m.db.Order("batch DESC").Order("id DESC").Limit(steps).Find(&migrations).Error; err != nil {
+		return err
+	}
 
-			fmt.Printf(" \033[32m✓ ROLLED BACK\033[0m\n")
-		} else {
-			fmt.Printf(" \033[33m⚠ SKIPPED (definition not found)\033[0m\n")
-		}
+	if len(migrations) == 0 {
+		fmt.Printf("ℹ️  \033[33mNo migrations to rollback\033[0m\n")
+		return nil
 	}
 
-	fmt.Println("────────────────────────────────────────")
+	fmt.Printf("🔄 \033[34mRolling back the last %d migration(s)...\033[0m\n", len(migrations))
+	if err := m.rollback(migrations, "ROLLED BACK"); err != nil {
+		return err
+	}
 	fmt.Printf("🎉 \033[32mRollback completed successfully!\033[0m\n")
 	return nil
 }
@@ -237,7 +229,7 @@ func (m *Migrator) Rollback() error {
 // Reset rolls back all migrations
 func (m *Migrator) Reset() error {
 	var migrations []MigrationRecord
-	if err := m.db.Order("id DESC").Find(&migrations).Error; err != nil {
+	if err := m.db.Order("batch DESC").Order("id DESC").Find(&migrations).Error; err != nil {
 		return err
 	}
 
@@ -247,6 +239,15 @@ func (m *Migrator) Reset() error {
 	}
 
 	fmt.Printf("🔥 \033[34mResetting %d migration(s)...\033[0m\n", len(migrations))
+	if err := m.rollback(migrations, "RESET"); err != nil {
+		return err
+	}
+	fmt.Printf("🎉 \033[32mReset completed successfully!\033[0m\n")
+	return nil
+}
+
+// rollback runs the down migration of each record in order and removes the record
+func (m *Migrator) rollback(migrations []MigrationRecord, done string) error {
 	fmt.Println("────────────────────────────────────────")
 
 	for i, migration := range migrations {
@@ -263,25 +264,27 @@ func (m *Migrator) Reset() error {
 
 		if def != nil {
 			err := m.db.Transaction(func(tx *gorm.DB) error {
+				// Run down migration
 				if err := def.Down(tx); err != nil {
 					return err
 				}
+
+				// Remove migration record
 				return tx.Delete(&migration).Error
 			})
 
 			if err != nil {
 				fmt.Printf(" \033[31m✗ FAILED\033[0m\n")
-				return fmt.Errorf("failed to reset migration %s: %w", migration.Name, err)
+				return fmt.Errorf("failed to rollback migration %s: %w", migration.Name, err)
 			}
 
-			fmt.Printf(" \033[32m✓ RESET\033[0m\n")
+			fmt.Printf(" \033[32m✓ %s\033[0m\n", done)
 		} else {
 			fmt.Printf(" \033[33m⚠ SKIPPED (definition not found)\033[0m\n")
 		}
 	}
 
 	fmt.Println("────────────────────────────────────────")
-	fmt.Printf("🎉 \033[32mReset completed successfully!\033[0m\n")
 	return nil
 }
 
@@ -299,11 +302,42 @@ func (m *Migrator) Refresh() error {
 	return m.RunPending()
 }
 
+// Fresh drops all tables, including the ones not created by migrations, and runs all migrations
+func (m *Migrator) Fresh() error {
+	all, err := m.db.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+
+	// SQLite lists its internal tables too, they cannot be dropped
+	tables := all[:0]
+	for _, table := range all {
+		if !strings.HasPrefix(table, "sqlite_") {
+			tables = append(tables, table)
+		}
+	}
+
+	fmt.Printf("🔥 \033[34mDropping %d table(s)...\033[0m\n", len(tables))
+	if len(tables) > 0 {
+		values := make([]interface{}, len(tables))
+		for i, table := range tables {
+			values[i] = table
+		}
+		if err := m.db.Migrator().DropTable(values...); err != nil {
+			return fmt.Errorf("failed to drop tables: %w", err)
+		}
+	}
+
+	return m.RunPending()
+}
+
 // Status returns the status of all migrations
 func (m *Migrator) Status() ([]map[string]interface{}, error) {
 	var executed []MigrationRecord
-	if err := m.db.Find(&executed).Error; err != nil {
-		return nil, err
+	if m.db.Migrator().HasTable(&MigrationRecord{}) {
+		if err := m.db.Order("id").Find(&executed).Error; err != nil {
+			return nil, err
+		}
 	}
 
 	executedMap := make(map[string]MigrationRecord)
@@ -330,5 +364,33 @@ func (m *Migrator) Status() ([]map[string]interface{}, error) {
 		}
 	}
 
+	// Migrations that ran but are no longer registered cannot be rolled back
+	registered := make(map[string]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		registered[migration.Name] = true
+	}
+	for _, record := range executed {
+		if !registered[record.Name] {
+			status = append(status, map[string]interface{}{
+				"name":       record.Name,
+				"batch":      record.Batch,
+				"created_at": record.CreatedAt,
+				"status":     "Missing",
+			})
+		}
+	}
+
 	return status, nil
 }
+
+// Transaction runs fn in a single transaction when the database can roll back
+// schema changes. MySQL commits implicitly on DDL statements, there each
+// migration still runs in its own transaction.
+func Transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	switch db.Dialector.Name() {
+	case "postgres", "sqlite", "sqlserver":
+		return db.Transaction(fn)
+	default:
+		return fn(db)
+	}
+}
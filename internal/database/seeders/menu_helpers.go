@@ -0,0 +1,103 @@
+package seeders
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// permissionMenu is a menu, or a button when it has a parent, granting a permission
+type permissionMenu struct {
+	Name       string
+	Title      string
+	Icon       string
+	Path       string
+	Component  string
+	ParentID   *uint
+	Sort       int
+	Permission string
+}
+
+// upsertMenu creates the menu of a permission or updates the existing one,
+// it returns the menu ID so seeders can run again safely
+func upsertMenu(tx *gorm.DB, menu permissionMenu) (uint, error) {
+	menuType := 1
+	if menu.ParentID != nil && menu.Path == "" {
+		menuType = 2
+	}
+
+	meta, err := json.Marshal(map[string]interface{}{
+		"title":      menu.Title,
+		"icon":       menu.Icon,
+		"breadcrumb": true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	values := map[string]interface{}{
+		"name":       menu.Name,
+		"title":      menu.Title,
+		"icon":       menu.Icon,
+		"path":       menu.Path,
+		"component":  menu.Component,
+		"parent_id":  menu.ParentID,
+		"sort":       menu.Sort,
+		"type":       menuType,
+		"visible":    1,
+		"status":     1,
+		"keep_alive": false,
+		"external":   false,
+		"permission": menu.Permission,
+		"meta":       string(meta),
+		"updated_at": time.Now(),
+	}
+
+	var existing struct{ ID uint }
+	err = tx.Table("menus").Select("id").
+		Where("permission = ? AND deleted_at IS NULL", menu.Permission).
+		First(&existing).Error
+	switch {
+	case err == nil:
+		return existing.ID, tx.Table("menus").Where("id = ?", existing.ID).Updates(values).Error
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return 0, err
+	}
+
+	values["created_at"] = time.Now()
+	if err := tx.Table("menus").Create(values).Error; err != nil {
+		return 0, err
+	}
+	err = tx.Table("menus").Select("id").
+		Where("permission = ? AND deleted_at IS NULL", menu.Permission).
+		First(&existing).Error
+	return existing.ID, err
+}
+
+// grantMenus gives a role access to menus it does not have yet, roles that
+// do not exist are skipped
+func grantMenus(tx *gorm.DB, roleCode string, menuIDs ...uint) error {
+	var role struct{ ID uint }
+	if err := tx.Table("roles").Where("code = ?", roleCode).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	for _, menuID := range menuIDs {
+		var count int64
+		if err := tx.Table("role_menus").Where("role_id = ? AND menu_id = ?", role.ID, menuID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := tx.Table("role_menus").Create(map[string]interface{}{"role_id": role.ID, "menu_id": menuID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
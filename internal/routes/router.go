@@ -9,6 +9,7 @@ import (
 	corehandlers "app/internal/core/handlers"
 	coremiddleware "app/internal/core/middleware"
 	"app/internal/core/storage"
+	"app/pkg/console"
 	"app/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -32,12 +33,16 @@ func (w *responseWriter) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
-// SetupRoutes configures all the routes for the application
-func SetupRoutes(r *gin.Engine, cfg *config.Config) {
+// SetupRoutes configures all the routes for the application, commands are the
+// console commands the scheduled tasks managed from the admin panel can run
+func SetupRoutes(r *gin.Engine, cfg *config.Config, commands *console.Manager) {
+	// The SSE manager is shared with services that push live updates
+	sseHandler := handlers.NewSSEHandler()
+
 	// Global middleware
-	r.Use(middleware.Trace())               // Add trace middleware globally
-	r.Use(middleware.I18n())                // Add i18n middleware globally
-	r.Use(middleware.ServiceInjection(cfg)) // Add service injection middleware globally
+	r.Use(middleware.Trace())                                               // Add trace middleware globally
+	r.Use(middleware.I18n())                                                // Add i18n middleware globally
+	r.Use(middleware.ServiceInjection(cfg, sseHandler.Manager(), commands)) // Add service injection middleware globally
 
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -53,8 +58,10 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config) {
 		{
 			auth.GET("/captcha", wrapHandler(adminv1.GetCaptcha))
 			auth.POST("/login", wrapHandler(adminv1.Login))
+			auth.POST("/login/two-factor", wrapHandler(adminv1.LoginTwoFactor))            // Authenticated by the MFA token in the body
+			auth.POST("/login/two-factor/setup", wrapHandler(adminv1.SetupTwoFactorLogin)) // Authenticated by the MFA token in the body
 			auth.POST("/logout", middleware.JWT(), wrapHandler(adminv1.Logout))
-			auth.POST("/refresh", middleware.JWT(), wrapHandler(adminv1.RefreshToken))
+			auth.POST("/refresh", wrapHandler(adminv1.RefreshToken)) // Authenticated by the refresh token in the body
 		}
 
 		// WebSocket routes (no JWT middleware needed, token passed via query params)
@@ -65,7 +72,6 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config) {
 		adminV1.POST("/ws/send", middleware.JWT(), wrapHandler(wsHandler.SendMessage))
 
 		// SSE routes
-		sseHandler := handlers.NewSSEHandler()
 		adminV1.GET("/sse", wrapHandler(sseHandler.HandleSSE))
 		adminV1.POST("/sse/notify", middleware.JWT(), wrapHandler(sseHandler.SendNotification))
 		adminV1.POST("/sse/join", middleware.JWT(), wrapHandler(sseHandler.JoinGroup))
@@ -88,6 +94,10 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config) {
 			users.PUT("/:id/status", middleware.RBAC("user:edit"), wrapHandler(adminv1.UpdateUserStatus))
 			users.GET("/:id/logs", middleware.RBAC("log:view"), wrapHandler(adminv1.GetUserLogs))
 			users.PUT("/:id/roles", middleware.RBAC("user:edit"), wrapHandler(adminv1.UpdateUserRoles))
+			users.POST("/:id/logout", middleware.RBAC("user:edit"), wrapHandler(adminv1.ForceLogoutUser))
+			users.GET("/:id/sessions", middleware.RBAC("user:view"), wrapHandler(adminv1.ListUserSessions))
+			users.DELETE("/:id/sessions", middleware.RBAC("user:edit"), wrapHandler(adminv1.ForceLogoutUser))
+			users.DELETE("/:id/sessions/:session_id", middleware.RBAC("user:edit"), wrapHandler(adminv1.RevokeUserSession))
 		}
 
 		// Role routes
@@ -123,6 +133,13 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config) {
 			logs.GET("/operation", wrapHandler(adminv1.ListOperationLogs))
 		}
 
+		// Login lock routes
+		loginLocks := adminV1Protected.Group("/login-locks")
+		{
+			loginLocks.GET("", middleware.RBAC("log:view"), wrapHandler(adminv1.ListLoginLocks))
+			loginLocks.DELETE("", middleware.RBAC("user:edit"), wrapHandler(adminv1.ClearLoginLock))
+		}
+
 		// I18n routes
 		i18n := adminV1Protected.Group("/i18n")
 		{
@@ -142,6 +159,15 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config) {
 		{
 			profile.GET("", wrapHandler(adminv1.GetCurrentUser))
 			profile.PUT("", wrapHandler(adminv1.UpdateCurrentUser))
+			profile.PUT("/password", wrapHandler(adminv1.ChangeCurrentUserPassword))
+			profile.GET("/sessions", wrapHandler(adminv1.ListCurrentUserSessions))
+			profile.DELETE("/sessions", wrapHandler(adminv1.RevokeOtherCurrentUserSessions))
+			profile.DELETE("/sessions/:id", wrapHandler(adminv1.RevokeCurrentUserSession))
+			profile.GET("/two-factor", wrapHandler(adminv1.GetTwoFactorStatus))
+			profile.POST("/two-factor", wrapHandler(adminv1.EnrolTwoFactor))
+			profile.POST("/two-factor/confirm", wrapHandler(adminv1.ConfirmTwoFactor))
+			profile.DELETE("/two-factor", wrapHandler(adminv1.DisableTwoFactor))
+			profile.POST("/two-factor/recovery-codes", wrapHandler(adminv1.RegenerateRecoveryCodes))
 		}
 
 		// 创建存储实例
@@ -184,6 +210,40 @@ func SetupRoutes(r *gin.Engine, cfg *config.Config) {
 			todos.DELETE("/:id", middleware.RBAC("todo:delete"), wrapHandler(adminv1.DeleteTodo))
 		}
 
+		// Resources generated by make:crud
+		// make:crud:routes
+
+		// Schedule routes
+		schedule := adminV1Protected.Group("/schedule")
+		{
+			schedule.GET("/tasks", middleware.RBAC("schedule:view"), wrapHandler(adminv1.ListScheduledTasks))
+			schedule.POST("/tasks", middleware.RBAC("schedule:create"), wrapHandler(adminv1.CreateScheduledTask))
+			schedule.GET("/tasks/:id", middleware.RBAC("schedule:view"), wrapHandler(adminv1.GetScheduledTask))
+			schedule.PUT("/tasks/:id", middleware.RBAC("schedule:edit"), wrapHandler(adminv1.UpdateScheduledTask))
+			schedule.DELETE("/tasks/:id", middleware.RBAC("schedule:delete"), wrapHandler(adminv1.DeleteScheduledTask))
+			schedule.POST("/tasks/:id/run", middleware.RBAC("schedule:run"), wrapHandler(adminv1.RunScheduledTask))
+			schedule.GET("/runs", middleware.RBAC("schedule:view"), wrapHandler(adminv1.ListScheduleRuns))
+			schedule.GET("/runs/:id", middleware.RBAC("schedule:view"), wrapHandler(adminv1.GetScheduleRun))
+		}
+
+		// Queue routes
+		queues := adminV1Protected.Group("/queues")
+		{
+			queues.GET("", middleware.RBAC("queue:view"), wrapHandler(adminv1.GetQueueOverview))
+			queues.GET("/workers", middleware.RBAC("queue:view"), wrapHandler(adminv1.ListQueueWorkers))
+			queues.GET("/settings", middleware.RBAC("queue:view"), wrapHandler(adminv1.GetQueueSettings))
+			queues.GET("/failed", middleware.RBAC("queue:view"), wrapHandler(adminv1.ListFailedJobs))
+			queues.DELETE("/failed", middleware.RBAC("queue:delete"), wrapHandler(adminv1.FlushFailedJobs))
+			queues.GET("/failed/:id", middleware.RBAC("queue:view"), wrapHandler(adminv1.GetFailedJob))
+			queues.POST("/failed/:id/retry", middleware.RBAC("queue:edit"), wrapHandler(adminv1.RetryFailedJob))
+			queues.DELETE("/failed/:id", middleware.RBAC("queue:delete"), wrapHandler(adminv1.ForgetFailedJob))
+			queues.GET("/batches", middleware.RBAC("queue:view"), wrapHandler(adminv1.ListBatches))
+			queues.GET("/batches/:id", middleware.RBAC("queue:view"), wrapHandler(adminv1.GetBatch))
+			queues.POST("/:name/pause", middleware.RBAC("queue:edit"), wrapHandler(adminv1.PauseQueue))
+			queues.POST("/:name/resume", middleware.RBAC("queue:edit"), wrapHandler(adminv1.ResumeQueue))
+			queues.DELETE("/:name", middleware.RBAC("queue:delete"), wrapHandler(adminv1.ClearQueue))
+		}
+
 	}
 
 	// Open API routes (v1)
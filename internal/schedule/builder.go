@@ -0,0 +1,353 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron expression fields kept by the builder, seconds come first
+const (
+	fieldSecond = iota
+	fieldMinute
+	fieldHour
+	fieldDayOfMonth
+	fieldMonth
+	fieldDayOfWeek
+)
+
+// TaskBuilder helps build a task with fluent interface
+//
+// Frequencies set the cron fields they are about, so they combine:
+// Hourly().Weekdays() runs at the start of every hour from Monday to Friday.
+// The first error is kept and returned by Register.
+type TaskBuilder struct {
+	scheduler *Scheduler
+	task      Task
+	// fields is nil when the schedule is a descriptor such as "@every 1h"
+	fields []string
+	err    error
+}
+
+// newTaskBuilder creates a builder for a task running every minute
+func newTaskBuilder(s *Scheduler, task Task) *TaskBuilder {
+	b := &TaskBuilder{scheduler: s, task: task}
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "*", fieldHour: "*", fieldDayOfMonth: "*", fieldMonth: "*", fieldDayOfWeek: "*"})
+}
+
+// fail keeps the first builder error
+func (b *TaskBuilder) fail(err error) *TaskBuilder {
+	if b.err != nil {
+		return b
+	}
+	if b.task.Name != "" {
+		err = fmt.Errorf("task %s: %w", b.task.Name, err)
+	}
+	b.err = err
+	return b
+}
+
+// setFields replaces the given cron fields
+func (b *TaskBuilder) setFields(values map[int]string) *TaskBuilder {
+	if b.fields == nil {
+		if b.task.Schedule != "" {
+			return b.fail(fmt.Errorf("cannot combine %q with other frequencies", b.task.Schedule))
+		}
+		b.fields = make([]string, 6)
+	}
+	for field, value := range values {
+		b.fields[field] = value
+	}
+	b.task.Schedule = strings.Join(b.fields, " ")
+	return b
+}
+
+// Unique marks the task as unique (should only run on one server)
+func (b *TaskBuilder) Unique() *TaskBuilder {
+	b.task.Unique = true
+	return b
+}
+
+// WithoutOverlapping skips a run while the previous run is still going. The lock
+// expires after expiry (DefaultLockExpiry when 0) if the process dies, it is
+// refreshed for as long as the task runs. Combined with Unique the lock is shared
+// by all servers, otherwise each host only guards against itself.
+func (b *TaskBuilder) WithoutOverlapping(expiry time.Duration) *TaskBuilder {
+	b.task.WithoutOverlapping = true
+	b.task.LockExpiry = expiry
+	return b
+}
+
+// Timeout cancels the context passed to the command once the task has run for d
+func (b *TaskBuilder) Timeout(d time.Duration) *TaskBuilder {
+	b.task.Timeout = d
+	return b
+}
+
+// Timezone evaluates the schedule and the Between window in the given IANA timezone
+func (b *TaskBuilder) Timezone(tz string) *TaskBuilder {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return b.fail(fmt.Errorf("invalid timezone %q: %v", tz, err))
+	}
+	b.task.Location = loc
+	return b
+}
+
+// Cron sets a custom cron schedule, with 5 fields, 6 fields starting with
+// seconds, or a descriptor such as "@every 90s"
+func (b *TaskBuilder) Cron(schedule string) *TaskBuilder {
+	if strings.HasPrefix(schedule, "@") {
+		b.fields = nil
+		b.task.Schedule = schedule
+		return b
+	}
+
+	fields := strings.Fields(schedule)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return b.fail(fmt.Errorf("invalid cron expression %q", schedule))
+	}
+
+	b.fields = fields
+	b.task.Schedule = strings.Join(fields, " ")
+	return b
+}
+
+// EverySecond sets the task to run every second
+func (b *TaskBuilder) EverySecond() *TaskBuilder {
+	return b.everySeconds(1)
+}
+
+// EveryFiveSeconds sets the task to run every five seconds
+func (b *TaskBuilder) EveryFiveSeconds() *TaskBuilder {
+	return b.everySeconds(5)
+}
+
+// EveryTenSeconds sets the task to run every ten seconds
+func (b *TaskBuilder) EveryTenSeconds() *TaskBuilder {
+	return b.everySeconds(10)
+}
+
+// EveryThirtySeconds sets the task to run every thirty seconds
+func (b *TaskBuilder) EveryThirtySeconds() *TaskBuilder {
+	return b.everySeconds(30)
+}
+
+// everySeconds sets the task to run every n seconds
+func (b *TaskBuilder) everySeconds(n int) *TaskBuilder {
+	second := "*"
+	if n > 1 {
+		second = fmt.Sprintf("*/%d", n)
+	}
+	return b.setFields(map[int]string{fieldSecond: second, fieldMinute: "*", fieldHour: "*"})
+}
+
+// EveryMinute sets the task to run every minute
+func (b *TaskBuilder) EveryMinute() *TaskBuilder {
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "*", fieldHour: "*"})
+}
+
+// EveryFiveMinutes sets the task to run every five minutes
+func (b *TaskBuilder) EveryFiveMinutes() *TaskBuilder {
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "*/5", fieldHour: "*"})
+}
+
+// EveryTenMinutes sets the task to run every ten minutes
+func (b *TaskBuilder) EveryTenMinutes() *TaskBuilder {
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "*/10", fieldHour: "*"})
+}
+
+// EveryThirtyMinutes sets the task to run every thirty minutes
+func (b *TaskBuilder) EveryThirtyMinutes() *TaskBuilder {
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "*/30", fieldHour: "*"})
+}
+
+// Hourly sets the task to run hourly
+func (b *TaskBuilder) Hourly() *TaskBuilder {
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "0", fieldHour: "*"})
+}
+
+// Daily sets the task to run daily
+func (b *TaskBuilder) Daily() *TaskBuilder {
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "0", fieldHour: "0"})
+}
+
+// TwiceDaily sets the task to run daily at the start of two hours
+func (b *TaskBuilder) TwiceDaily(first, second int) *TaskBuilder {
+	if !validHour(first) || !validHour(second) {
+		return b.fail(fmt.Errorf("invalid hours %d and %d", first, second))
+	}
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "0", fieldHour: fmt.Sprintf("%d,%d", first, second)})
+}
+
+// Weekly sets the task to run weekly
+func (b *TaskBuilder) Weekly() *TaskBuilder {
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "0", fieldHour: "0", fieldDayOfWeek: "0"})
+}
+
+// Monthly sets the task to run monthly
+func (b *TaskBuilder) Monthly() *TaskBuilder {
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "0", fieldHour: "0", fieldDayOfMonth: "1"})
+}
+
+// Quarterly sets the task to run on the first day of each quarter
+func (b *TaskBuilder) Quarterly() *TaskBuilder {
+	return b.setFields(map[int]string{fieldSecond: "0", fieldMinute: "0", fieldHour: "0", fieldDayOfMonth: "1", fieldMonth: "1-12/3"})
+}
+
+// Weekdays limits the task to Monday to Friday
+func (b *TaskBuilder) Weekdays() *TaskBuilder {
+	return b.setFields(map[int]string{fieldDayOfWeek: "1-5"})
+}
+
+// Weekends limits the task to Saturday and Sunday
+func (b *TaskBuilder) Weekends() *TaskBuilder {
+	return b.setFields(map[int]string{fieldDayOfWeek: "0,6"})
+}
+
+// Mondays limits the task to Mondays
+func (b *TaskBuilder) Mondays() *TaskBuilder {
+	return b.Days(time.Monday)
+}
+
+// Days limits the task to the given days of the week
+func (b *TaskBuilder) Days(days ...time.Weekday) *TaskBuilder {
+	values := make([]string, len(days))
+	for i, day := range days {
+		values[i] = strconv.Itoa(int(day))
+	}
+	return b.setFields(map[int]string{fieldDayOfWeek: strings.Join(values, ",")})
+}
+
+// At sets the time of day, as HH:mm or HH:mm:ss, for daily and less frequent tasks
+func (b *TaskBuilder) At(at string) *TaskBuilder {
+	t, err := parseClock(at)
+	if err != nil {
+		return b.fail(err)
+	}
+	return b.setFields(map[int]string{
+		fieldSecond: strconv.Itoa(t.Second()),
+		fieldMinute: strconv.Itoa(t.Minute()),
+		fieldHour:   strconv.Itoa(t.Hour()),
+	})
+}
+
+// Between only runs the task when the time of day is within start and end,
+// given as HH:mm. The end minute is included and a window ending before it
+// starts spans midnight.
+func (b *TaskBuilder) Between(start, end string) *TaskBuilder {
+	from, err := parseClock(start)
+	if err != nil {
+		return b.fail(err)
+	}
+	to, err := parseClock(end)
+	if err != nil {
+		return b.fail(err)
+	}
+
+	fromOffset, toOffset := clockOffset(from), clockOffset(to)
+	if to.Second() == 0 {
+		toOffset += time.Minute - time.Second
+	}
+	return b.filter(func(now time.Time) bool {
+		offset := clockOffset(now)
+		if fromOffset <= toOffset {
+			return offset >= fromOffset && offset <= toOffset
+		}
+		return offset >= fromOffset || offset <= toOffset
+	})
+}
+
+// When only runs the task when the condition returns true
+func (b *TaskBuilder) When(condition func() bool) *TaskBuilder {
+	return b.filter(func(time.Time) bool { return condition() })
+}
+
+// Skip skips the task when the condition returns true
+func (b *TaskBuilder) Skip(condition func() bool) *TaskBuilder {
+	return b.filter(func(time.Time) bool { return !condition() })
+}
+
+// Environments only runs the task when the application environment (app.env) is one of envs
+func (b *TaskBuilder) Environments(envs ...string) *TaskBuilder {
+	s := b.scheduler
+	return b.filter(func(time.Time) bool {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return slices.Contains(envs, s.environment)
+	})
+}
+
+// filter adds a condition checked each time the task is due
+func (b *TaskBuilder) filter(filter func(now time.Time) bool) *TaskBuilder {
+	b.task.filters = append(b.task.filters, filter)
+	return b
+}
+
+// Register validates the task and registers it with the scheduler
+func (b *TaskBuilder) Register() error {
+	task, err := b.build()
+	if err != nil {
+		return err
+	}
+
+	b.scheduler.AddTask(task)
+	return nil
+}
+
+// build validates the task
+func (b *TaskBuilder) build() (Task, error) {
+	if b.err != nil {
+		return Task{}, b.err
+	}
+	if b.task.Command == nil {
+		return Task{}, fmt.Errorf("task %s: no command", b.task.Name)
+	}
+	if _, err := parser.Parse(b.task.Spec()); err != nil {
+		return Task{}, fmt.Errorf("task %s: invalid schedule %q: %v", b.task.Name, b.task.Schedule, err)
+	}
+	return b.task, nil
+}
+
+// ValidateExpression checks a cron expression and an optional timezone the way Cron and Timezone would
+func ValidateExpression(expression, timezone string) error {
+	b := &TaskBuilder{}
+	b.Cron(expression)
+	if timezone != "" {
+		b.Timezone(timezone)
+	}
+	if b.err != nil {
+		return b.err
+	}
+	if _, err := parser.Parse(b.task.Spec()); err != nil {
+		return fmt.Errorf("invalid cron expression %q: %v", expression, err)
+	}
+	return nil
+}
+
+// parseClock parses a time of day in HH:mm or HH:mm:ss format
+func parseClock(value string) (time.Time, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid time " + strconv.Quote(value) + ", expected HH:mm")
+}
+
+// clockOffset returns the time of day as a duration since midnight
+func clockOffset(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// validHour reports whether h is an hour of the day
+func validHour(h int) bool {
+	return h >= 0 && h <= 23
+}
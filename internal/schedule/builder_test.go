@@ -0,0 +1,124 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"app/pkg/console"
+	"app/pkg/locker"
+)
+
+func newTestScheduler() *Scheduler {
+	return NewScheduler(console.NewManager(), locker.NewMemoryLocker())
+}
+
+func TestBuilderSchedules(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(b *TaskBuilder) *TaskBuilder
+		want  string
+	}{
+		{"default every minute", func(b *TaskBuilder) *TaskBuilder { return b }, "0 * * * * *"},
+		{"every ten seconds", (*TaskBuilder).EveryTenSeconds, "*/10 * * * * *"},
+		{"hourly on weekdays", func(b *TaskBuilder) *TaskBuilder { return b.Hourly().Weekdays() }, "0 0 * * * 1-5"},
+		{"daily at on weekends", func(b *TaskBuilder) *TaskBuilder { return b.Daily().At("13:30").Weekends() }, "0 30 13 * * 0,6"},
+		{"mondays at", func(b *TaskBuilder) *TaskBuilder { return b.Mondays().At("08:15:10") }, "10 15 8 * * 1"},
+		{"twice daily", func(b *TaskBuilder) *TaskBuilder { return b.TwiceDaily(1, 13) }, "0 0 1,13 * * *"},
+		{"quarterly", (*TaskBuilder).Quarterly, "0 0 0 1 1-12/3 *"},
+		{"five field cron", func(b *TaskBuilder) *TaskBuilder { return b.Cron("*/5 9-17 * * *") }, "0 */5 9-17 * * *"},
+		{"descriptor", func(b *TaskBuilder) *TaskBuilder { return b.Cron("@every 90s") }, "@every 90s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.build(newTestScheduler().Task("test", newBlockingCommand()))
+			if b.err != nil {
+				t.Fatalf("unexpected error: %v", b.err)
+			}
+			if b.task.Schedule != tt.want {
+				t.Fatalf("schedule = %q, want %q", b.task.Schedule, tt.want)
+			}
+			if err := b.Register(); err != nil {
+				t.Fatalf("register: %v", err)
+			}
+		})
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(b *TaskBuilder) *TaskBuilder
+	}{
+		{"invalid at", func(b *TaskBuilder) *TaskBuilder { return b.Daily().At("25:99") }},
+		{"invalid timezone", func(b *TaskBuilder) *TaskBuilder { return b.Timezone("Mars/Olympus") }},
+		{"invalid cron", func(b *TaskBuilder) *TaskBuilder { return b.Cron("* * *") }},
+		{"invalid cron value", func(b *TaskBuilder) *TaskBuilder { return b.Cron("0 99 * * *") }},
+		{"descriptor with days", func(b *TaskBuilder) *TaskBuilder { return b.Cron("@hourly").Weekdays() }},
+		{"invalid between", func(b *TaskBuilder) *TaskBuilder { return b.Between("8am", "18:00") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestScheduler()
+			if err := tt.build(s.Task("test", newBlockingCommand())).Register(); err == nil {
+				t.Fatal("expected Register to return an error")
+			}
+			if len(s.tasks) != 0 {
+				t.Fatal("invalid task was registered")
+			}
+		})
+	}
+
+	s := newTestScheduler()
+	if err := s.Command("missing:command").Register(); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected a command not found error, got %v", err)
+	}
+}
+
+func TestBuilderFilters(t *testing.T) {
+	s := newTestScheduler()
+	s.SetEnvironment("staging")
+	at := func(clock string) time.Time {
+		t, _ := time.ParseInLocation("2006-01-02 15:04", "2026-10-16 "+clock, time.Local)
+		return t
+	}
+
+	between := s.Task("test", newBlockingCommand()).Between("08:00", "18:00").task
+	if !between.FiltersPass(at("08:00")) || !between.FiltersPass(at("18:00")) || between.FiltersPass(at("18:01")) {
+		t.Fatal("unexpected Between window")
+	}
+
+	overnight := s.Task("test", newBlockingCommand()).Between("22:00", "06:00").task
+	if !overnight.FiltersPass(at("23:30")) || !overnight.FiltersPass(at("05:59")) || overnight.FiltersPass(at("12:00")) {
+		t.Fatal("unexpected overnight Between window")
+	}
+
+	skip := true
+	conditional := s.Task("test", newBlockingCommand()).When(func() bool { return true }).Skip(func() bool { return skip }).task
+	if conditional.FiltersPass(time.Now()) {
+		t.Fatal("expected Skip to reject the run")
+	}
+	skip = false
+	if !conditional.FiltersPass(time.Now()) {
+		t.Fatal("expected the run to pass once Skip returns false")
+	}
+
+	if s.Task("test", newBlockingCommand()).Environments("production").task.FiltersPass(time.Now()) {
+		t.Fatal("expected the production task to be skipped on staging")
+	}
+	if !s.Task("test", newBlockingCommand()).Environments("staging", "production").task.FiltersPass(time.Now()) {
+		t.Fatal("expected the task to run on staging")
+	}
+}
+
+func TestTimezoneSpec(t *testing.T) {
+	task := newTestScheduler().Task("test", newBlockingCommand()).Daily().At("09:00").Timezone("Asia/Shanghai").task
+	if got, want := task.Spec(), "CRON_TZ=Asia/Shanghai 0 0 9 * * *"; got != want {
+		t.Fatalf("spec = %q, want %q", got, want)
+	}
+	if _, err := parser.Parse(task.Spec()); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"app/internal/core/models"
+)
+
+const (
+	// maxRunOutput caps the output stored for a single run
+	maxRunOutput = 64 << 10
+	// pruneInterval is how often runs older than the retention are deleted
+	pruneInterval = time.Hour
+)
+
+// History stores the runs of scheduled tasks
+type History interface {
+	RecordRun(ctx context.Context, run *models.ScheduleRun) error
+	PruneRuns(ctx context.Context, before time.Time) (int64, error)
+}
+
+// outputWriter is implemented by commands whose messages can be redirected,
+// console.BaseCommand implements it
+type outputWriter interface {
+	Output() io.Writer
+	SetOutput(w io.Writer)
+}
+
+// SetHistory records every task run in the history, runs older than the
+// retention are pruned periodically. A retention of 0 keeps all runs.
+func (s *Scheduler) SetHistory(history History, retention time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history = history
+	s.retention = retention
+}
+
+// captureOutput tees the output of a per-run command into a buffer
+func captureOutput(command interface{}) *outputBuffer {
+	buf := &outputBuffer{}
+	if w, ok := command.(outputWriter); ok {
+		w.SetOutput(io.MultiWriter(w.Output(), buf))
+	}
+	return buf
+}
+
+// recordRun stores a finished run, history errors are only logged
+func (s *Scheduler) recordRun(ctx context.Context, task Task, started time.Time, output string, runErr error) {
+	if s.history == nil {
+		return
+	}
+
+	finished := time.Now()
+	run := &models.ScheduleRun{
+		TaskName:   task.Name,
+		Host:       hostname(),
+		Status:     models.ScheduleRunSuccess,
+		Output:     output,
+		StartedAt:  started,
+		FinishedAt: finished,
+		Duration:   finished.Sub(started).Milliseconds(),
+	}
+	if runErr != nil {
+		run.Status = models.ScheduleRunFailed
+		run.Error = runErr.Error()
+	}
+
+	// Record runs that finish while the scheduler is shutting down as well
+	ctx = context.WithoutCancel(ctx)
+	if err := s.history.RecordRun(ctx, run); err != nil {
+		log.Printf("[ERROR] Failed to record run of task %s: %v", task.Name, err)
+	}
+	s.pruneRuns(ctx)
+}
+
+// pruneRuns deletes runs older than the retention, at most once per pruneInterval
+func (s *Scheduler) pruneRuns(ctx context.Context) {
+	if s.retention <= 0 {
+		return
+	}
+
+	s.pruneMu.Lock()
+	if time.Since(s.lastPrune) < pruneInterval {
+		s.pruneMu.Unlock()
+		return
+	}
+	s.lastPrune = time.Now()
+	s.pruneMu.Unlock()
+
+	deleted, err := s.history.PruneRuns(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		log.Printf("[ERROR] Failed to prune schedule runs: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Pruned %d schedule runs older than %s", deleted, s.retention)
+	}
+}
+
+// outputBuffer collects command output up to maxRunOutput bytes
+type outputBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	truncated bool
+}
+
+// Write implements io.Writer, it never fails so the command output is not interrupted
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if remaining := maxRunOutput - b.buf.Len(); len(p) > remaining {
+		b.buf.Write(p[:remaining])
+		b.truncated = true
+	} else {
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+// String returns the collected output
+func (b *outputBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.truncated {
+		return b.buf.String() + "\n... output truncated"
+	}
+	return b.buf.String()
+}
+
+// hostname returns the name of the host running the scheduler
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return name
+}
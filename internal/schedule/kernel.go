@@ -2,12 +2,17 @@ package schedule
 
 import (
 	"context"
+	"errors"
 	"log"
+	"sync"
 )
 
 // Kernel manages the scheduler
 type Kernel struct {
 	scheduler *Scheduler
+	// once registers the tasks, err keeps the result for later calls
+	once sync.Once
+	err  error
 }
 
 // NewKernel creates a new scheduler kernel
@@ -17,18 +22,40 @@ func NewKernel(scheduler *Scheduler) *Kernel {
 	}
 }
 
-// Schedule defines scheduled tasks
-func (k *Kernel) Schedule() {
-	// Add a test task that runs every minute
-	k.scheduler.Command("hello:world").EveryMinute().Register()
+// Schedule defines scheduled tasks, the tasks are only registered once and
+// later calls return the error of the first one
+func (k *Kernel) Schedule() error {
+	k.once.Do(func() {
+		k.err = k.schedule()
+	})
+	return k.err
+}
+
+// schedule registers the scheduled tasks
+func (k *Kernel) schedule() error {
+	errs := []error{
+		// Add a test task that runs every minute
+		k.scheduler.Command("hello:world").EveryMinute().Register(),
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
 
 	log.Println("Scheduled tasks initialized")
+	return nil
+}
+
+// Scheduler returns the scheduler of the kernel
+func (k *Kernel) Scheduler() *Scheduler {
+	return k.scheduler
 }
 
 // Start starts the scheduler
 func (k *Kernel) Start(ctx context.Context) error {
 	log.Println("Starting scheduler...")
-	k.Schedule()
+	if err := k.Schedule(); err != nil {
+		return err
+	}
 	return k.scheduler.Start(ctx)
 }
 
@@ -0,0 +1,27 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+
+	"app/pkg/console"
+	"app/pkg/locker"
+)
+
+func TestKernelScheduleKeepsFirstError(t *testing.T) {
+	// The kernel tasks need hello:world, which this manager does not have
+	kernel := NewKernel(NewScheduler(console.NewManager(), locker.NewMemoryLocker()))
+
+	err := kernel.Schedule()
+	if err == nil || !strings.Contains(err.Error(), "command not found: hello:world") {
+		t.Fatalf("expected the missing command error, got %v", err)
+	}
+
+	// Later calls report the same error instead of a schedule without tasks
+	if again := kernel.Schedule(); again != err {
+		t.Errorf("expected the first error again, got %v", again)
+	}
+	if len(kernel.Scheduler().tasks) != 0 {
+		t.Errorf("expected no registered tasks, got %d", len(kernel.Scheduler().tasks))
+	}
+}
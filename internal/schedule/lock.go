@@ -0,0 +1,85 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// DefaultLockExpiry is how long a task lock lives when the process holding it dies
+	DefaultLockExpiry = 30 * time.Minute
+	// minRefreshInterval keeps very short expiries from hammering the locker
+	minRefreshInterval = time.Second
+)
+
+// taskLock returns the key and TTL of the lock a task must hold while running,
+// or an empty key when the task may run freely.
+//
+// Unique tasks share one lock across all servers. Tasks that only avoid
+// overlapping themselves lock per host, so each server still runs its own copy.
+func taskLock(task Task) (string, time.Duration) {
+	expiry := task.LockExpiry
+	if expiry <= 0 {
+		expiry = DefaultLockExpiry
+	}
+
+	switch {
+	case task.Unique:
+		return fmt.Sprintf("scheduler:lock:%s", task.Name), expiry
+	case task.WithoutOverlapping:
+		return fmt.Sprintf("scheduler:overlap:%s:%s", hostname(), task.Name), expiry
+	default:
+		return "", 0
+	}
+}
+
+// holdLock keeps the lock alive until the returned function is called, which
+// also releases it. The lock is refreshed every third of its TTL so a task
+// running longer than the TTL keeps it, while a crashed process loses it.
+// Refreshing stops once the lock has been lost to another owner.
+func (s *Scheduler) holdLock(ctx context.Context, task Task, key, owner string, ttl time.Duration) func() {
+	// Release the lock even when the scheduler is shutting down
+	ctx = context.WithoutCancel(ctx)
+
+	// Refreshing must still happen before the lock expires
+	interval := ttl / 3
+	if interval < minRefreshInterval {
+		interval = min(minRefreshInterval, ttl/2)
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				held, err := s.locker.RefreshLockAs(ctx, key, owner, ttl)
+				if err != nil {
+					log.Printf("[WARN] Failed to refresh lock for task %s: %v", task.Name, err)
+					continue
+				}
+				if !held {
+					log.Printf("[WARN] Task %s lost its lock, another run may overlap", task.Name)
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		if err := s.locker.UnlockAs(ctx, key, owner); err != nil {
+			log.Printf("Failed to release lock for task %s: %v", task.Name, err)
+		}
+	}
+}
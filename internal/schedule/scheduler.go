@@ -2,14 +2,18 @@ package schedule
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"app/pkg/console"
 	"app/pkg/locker"
 
+	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
 )
 
@@ -20,7 +24,24 @@ type Scheduler struct {
 	manager  *console.Manager
 	mu       sync.RWMutex
 	location *time.Location
-	locker   *locker.RedisLocker
+	locker   locker.Locker
+	// environment is compared against the task Environments, see SetEnvironment
+	environment string
+	// verbose receives a line every minute and for every due task, see SetVerbose
+	verbose io.Writer
+
+	// history records task runs, see SetHistory
+	history   History
+	retention time.Duration
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+
+	// store holds the tasks managed from the admin panel, see SetTaskStore
+	store        TaskStore
+	syncInterval time.Duration
+	syncMu       sync.Mutex
+	stored       map[uint]storedEntry
+	stopSync     context.CancelFunc
 }
 
 // Task represents a scheduled task
@@ -28,14 +49,51 @@ type Task struct {
 	Name     string
 	Schedule string
 	Command  console.Command
-	Unique   bool // Whether the task should run on only one server
+	// Input holds the parsed arguments of tasks created with Scheduler.Command
+	Input  *console.Input
+	Unique bool // Whether the task should run on only one server
+	// WithoutOverlapping skips a run while the previous one is still going on this host
+	WithoutOverlapping bool
+	// LockExpiry is the TTL of the task lock, it is refreshed while the task runs
+	LockExpiry time.Duration
+	// Timeout cancels the context passed to the command, 0 means no timeout
+	Timeout time.Duration
+	// Location is the timezone of the schedule, the scheduler location when nil
+	Location *time.Location
+
+	// filters must all pass for a due task to run
+	filters []func(now time.Time) bool
+}
+
+// parser accepts standard 5 field expressions, 6 fields with leading seconds and descriptors
+var parser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Spec returns the cron spec of the task including its timezone
+func (t Task) Spec() string {
+	if t.Location != nil {
+		return fmt.Sprintf("CRON_TZ=%s %s", t.Location, t.Schedule)
+	}
+	return t.Schedule
+}
+
+// FiltersPass reports whether the When, Skip, Between and Environments conditions allow the task to run now
+func (t Task) FiltersPass(now time.Time) bool {
+	if t.Location != nil {
+		now = now.In(t.Location)
+	}
+	for _, filter := range t.filters {
+		if !filter(now) {
+			return false
+		}
+	}
+	return true
 }
 
 // NewScheduler creates a new scheduler instance
-func NewScheduler(manager *console.Manager, locker *locker.RedisLocker) *Scheduler {
+func NewScheduler(manager *console.Manager, locker locker.Locker) *Scheduler {
 	loc, _ := time.LoadLocation("Local")
 	return &Scheduler{
-		cron:     cron.New(cron.WithLocation(loc)),
+		cron:     cron.New(cron.WithLocation(loc), cron.WithParser(parser)),
 		tasks:    make([]Task, 0),
 		manager:  manager,
 		location: loc,
@@ -43,25 +101,37 @@ func NewScheduler(manager *console.Manager, locker *locker.RedisLocker) *Schedul
 	}
 }
 
+// SetEnvironment sets the application environment matched by TaskBuilder.Environments
+func (s *Scheduler) SetEnvironment(env string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.environment = env
+}
+
 // Task creates a new task
 func (s *Scheduler) Task(name string, command console.Command) *TaskBuilder {
-	return &TaskBuilder{
-		scheduler: s,
-		task: Task{
-			Name:    name,
-			Command: command,
-			Unique:  false, // Default to non-unique
-		},
-	}
+	return newTaskBuilder(s, Task{
+		Name:    name,
+		Command: command,
+		Unique:  false, // Default to non-unique
+	})
 }
 
-// Command creates a new task from command name
+// Command creates a new task from command name. The arguments are parsed like
+// on the command line, e.g. Command("logs:prune", "--days=30")
 func (s *Scheduler) Command(name string, args ...string) *TaskBuilder {
+	taskName := strings.Join(append([]string{name}, args...), " ")
+
 	cmd := s.manager.FindCommand(name)
+	builder := s.Task(taskName, cmd)
 	if cmd == nil {
-		return nil
+		builder.err = fmt.Errorf("command not found: %s", name)
+		return builder
 	}
-	return s.Task(name, cmd)
+
+	builder.task.Input, builder.err = s.manager.Parse(name, args)
+	return builder
 }
 
 // Start starts the scheduler
@@ -70,52 +140,164 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	defer s.mu.Unlock()
 
 	for _, task := range s.tasks {
-		task := task // Create new variable for closure
-		_, err := s.cron.AddFunc(task.Schedule, func() {
-			if err := s.runTask(ctx, task); err != nil {
-				log.Printf("Error running task %s: %v\n", task.Name, err)
-			}
-		})
-		if err != nil {
+		if _, err := s.cron.AddFunc(task.Spec(), s.cronJob(ctx, task)); err != nil {
 			return fmt.Errorf("failed to add task %s: %v", task.Name, err)
 		}
 	}
 
+	// The verbose output reports every minute, also when no task is due
+	if s.verbose != nil {
+		if _, err := s.cron.AddFunc("* * * * *", func() { s.tick(ctx, time.Now()) }); err != nil {
+			return fmt.Errorf("failed to add the verbose tick: %v", err)
+		}
+	}
+
+	// Load the tasks managed from the admin panel and keep them in sync
+	if s.store != nil {
+		syncCtx, cancel := context.WithCancel(ctx)
+		s.stopSync = cancel
+		s.syncTasks(syncCtx)
+		go s.syncLoop(syncCtx)
+	}
+
 	s.cron.Start()
 	return nil
 }
 
-// runTask runs a single task with distributed lock if needed
-func (s *Scheduler) runTask(ctx context.Context, task Task) error {
-	if !task.Unique {
-		// For non-unique tasks, run directly
-		return task.Command.Handle(ctx)
+// cronJob returns the function cron calls each time the task is due
+func (s *Scheduler) cronJob(ctx context.Context, task Task) func() {
+	return func() {
+		if !task.FiltersPass(time.Now()) {
+			s.verbosef("Skipping %s, its conditions are not met", task.Name)
+			return
+		}
+		s.dispatch(ctx, task)
+	}
+}
+
+// dispatch runs a due task and logs the outcome
+func (s *Scheduler) dispatch(ctx context.Context, task Task) {
+	s.verbosef("Running %s", task.Name)
+	started := time.Now()
+	if err := s.runTask(ctx, task); err != nil {
+		log.Printf("Error running task %s: %v\n", task.Name, err)
+		s.verbosef("Failed %s after %s", task.Name, time.Since(started).Round(time.Millisecond))
+		return
 	}
+	s.verbosef("Finished %s in %s", task.Name, time.Since(started).Round(time.Millisecond))
+}
+
+// SetVerbose writes a timestamped line to w every minute, listing the tasks due
+// in it, and each time a task runs. It must be set before Start, nil turns it off.
+func (s *Scheduler) SetVerbose(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.verbose = w
+}
 
-	// For unique tasks, try to acquire a lock first
-	lockKey := fmt.Sprintf("scheduler:lock:%s", task.Name)
-	// Set TTL to slightly longer than the expected task duration
-	// Adjust the TTL based on your needs
-	ttl := 30 * time.Minute
+// verbosef writes a line to the verbose writer when one is set
+func (s *Scheduler) verbosef(format string, args ...interface{}) {
+	s.mu.RLock()
+	w := s.verbose
+	s.mu.RUnlock()
 
-	acquired, err := s.locker.TryLock(ctx, lockKey, ttl)
+	if w != nil {
+		fmt.Fprintf(w, "%s %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+	}
+}
+
+// tick writes the tasks due in the minute of now to the verbose output
+func (s *Scheduler) tick(ctx context.Context, now time.Time) {
+	tasks, err := s.Tasks(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to acquire lock for task %s: %v", task.Name, err)
+		s.verbosef("%v", err)
+	}
+
+	minute := now.Truncate(time.Minute)
+	var due []string
+	for _, task := range tasks {
+		if at := s.NextRun(task, minute.Add(-time.Second)); !at.IsZero() && at.Before(minute.Add(time.Minute)) {
+			due = append(due, task.Name)
+		}
+	}
+
+	if len(due) == 0 {
+		s.verbosef("No tasks due")
+		return
+	}
+	s.verbosef("%d task(s) due: %s", len(due), strings.Join(due, ", "))
+}
+
+// runTask runs a single task, holding its lock if it must not overlap
+func (s *Scheduler) runTask(ctx context.Context, task Task) error {
+	key, ttl := taskLock(task)
+	if key == "" {
+		// Tasks without a lock run directly
+		return s.execute(ctx, task)
+	}
+
+	// A random owner per run, so a run whose lock expired cannot touch the
+	// lock another run has taken since
+	owner := uuid.New().String()
+	started := time.Now()
+	acquired, err := s.locker.TryLockAs(ctx, key, owner, ttl)
+	if err != nil {
+		err = fmt.Errorf("failed to acquire lock for task %s: %v", task.Name, err)
+		s.recordRun(ctx, task, started, "", err)
+		return err
 	}
 
 	if !acquired {
-		// Another instance is running this task
-		log.Printf("Task %s is already running on another instance", task.Name)
+		// The previous run is still going, here or on another instance
+		log.Printf("Task %s is already running, skipping", task.Name)
 		return nil
 	}
 
-	// Run the task and ensure we release the lock afterward
+	// Keep the lock while the task runs and release it afterward
+	release := s.holdLock(ctx, task, key, owner, ttl)
+	defer release()
+
+	return s.execute(ctx, task)
+}
+
+// execute runs the task command and records the run with its output
+func (s *Scheduler) execute(ctx context.Context, task Task) error {
+	// Each run gets its own copy of the command, overlapping runs of the same
+	// command keep their own input and output
+	task.Command = console.Instance(task.Command)
+	if s.history == nil {
+		return handle(ctx, task)
+	}
+
+	output := captureOutput(task.Command)
+	started := time.Now()
+	err := handle(ctx, task)
+
+	s.recordRun(ctx, task, started, output.String(), err)
+	return err
+}
+
+// handle runs the command within the task timeout, turning a panic into an
+// error so it is recorded instead of crashing the scheduler
+func handle(ctx context.Context, task Task) (err error) {
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.Timeout)
+		defer cancel()
+	}
+	if task.Input != nil {
+		ctx = console.Bind(ctx, task.Command, task.Input)
+	}
+
 	defer func() {
-		if err := s.locker.Unlock(ctx, lockKey); err != nil {
-			log.Printf("Failed to release lock for task %s: %v", task.Name, err)
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task %s panicked: %v", task.Name, r)
+		}
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("task %s timed out after %s: %w", task.Name, task.Timeout, err)
 		}
 	}()
-
 	return task.Command.Handle(ctx)
 }
 
@@ -124,6 +306,9 @@ func (s *Scheduler) Stop() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.stopSync != nil {
+		s.stopSync()
+	}
 	if s.cron != nil {
 		s.cron.Stop()
 	}
@@ -137,80 +322,60 @@ func (s *Scheduler) AddTask(task Task) {
 	s.tasks = append(s.tasks, task)
 }
 
-// TaskBuilder helps build a task with fluent interface
-type TaskBuilder struct {
-	scheduler *Scheduler
-	task      Task
-}
-
-// Unique marks the task as unique (should only run on one server)
-func (b *TaskBuilder) Unique() *TaskBuilder {
-	b.task.Unique = true
-	return b
-}
-
-// Cron sets a custom cron schedule
-func (b *TaskBuilder) Cron(schedule string) *TaskBuilder {
-	b.task.Schedule = schedule
-	return b
-}
+// Tasks returns the registered tasks followed by the enabled tasks of the
+// store, invalid stored tasks are left out
+func (s *Scheduler) Tasks(ctx context.Context) ([]Task, error) {
+	s.mu.RLock()
+	tasks := append([]Task(nil), s.tasks...)
+	store := s.store
+	s.mu.RUnlock()
 
-// EveryMinute sets the task to run every minute
-func (b *TaskBuilder) EveryMinute() *TaskBuilder {
-	return b.Cron("* * * * *")
-}
-
-// EveryFiveMinutes sets the task to run every five minutes
-func (b *TaskBuilder) EveryFiveMinutes() *TaskBuilder {
-	return b.Cron("*/5 * * * *")
-}
-
-// EveryTenMinutes sets the task to run every ten minutes
-func (b *TaskBuilder) EveryTenMinutes() *TaskBuilder {
-	return b.Cron("*/10 * * * *")
-}
-
-// EveryThirtyMinutes sets the task to run every thirty minutes
-func (b *TaskBuilder) EveryThirtyMinutes() *TaskBuilder {
-	return b.Cron("*/30 * * * *")
-}
-
-// Hourly sets the task to run hourly
-func (b *TaskBuilder) Hourly() *TaskBuilder {
-	return b.Cron("0 * * * *")
-}
+	if store == nil {
+		return tasks, nil
+	}
 
-// Daily sets the task to run daily
-func (b *TaskBuilder) Daily() *TaskBuilder {
-	return b.Cron("0 0 * * *")
+	records, err := store.EnabledTasks(ctx)
+	if err != nil {
+		return tasks, fmt.Errorf("failed to load scheduled tasks: %w", err)
+	}
+	for _, record := range records {
+		task, err := s.storedTask(record)
+		if err != nil {
+			log.Printf("[WARN] Scheduled task %d is invalid: %v", record.ID, err)
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
 }
 
-// Weekly sets the task to run weekly
-func (b *TaskBuilder) Weekly() *TaskBuilder {
-	return b.Cron("0 0 * * 0")
+// NextRun returns when the task is next due after the given time, ignoring
+// its conditions. It returns the zero time when the schedule is invalid.
+func (s *Scheduler) NextRun(task Task, after time.Time) time.Time {
+	schedule, err := parser.Parse(task.Spec())
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.Next(after.In(s.location))
 }
 
-// Monthly sets the task to run monthly
-func (b *TaskBuilder) Monthly() *TaskBuilder {
-	return b.Cron("0 0 1 * *")
+// Location returns the timezone of tasks without their own
+func (s *Scheduler) Location() *time.Location {
+	return s.location
 }
 
-// At sets a specific time for daily tasks
-func (b *TaskBuilder) At(time string) *TaskBuilder {
-	t, err := b.scheduler.parseTime(time)
+// RunTask runs the task with the given name now, with the same locking and
+// history as a scheduled run. Its conditions are not checked.
+func (s *Scheduler) RunTask(ctx context.Context, name string) error {
+	tasks, err := s.Tasks(ctx)
 	if err != nil {
-		return b
+		return err
 	}
-	b.task.Schedule = fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour())
-	return b
-}
-
-// Register registers the task with the scheduler
-func (b *TaskBuilder) Register() {
-	b.scheduler.AddTask(b.task)
-}
 
-// parseTime parses time string in HH:mm format
-func (s *Scheduler) parseTime(timeStr string) (time.Time, error) {
-	return time.ParseInLocation("15:04", timeStr, s.location)
+	for _, task := range tasks {
+		if task.Name == name {
+			return s.runTask(ctx, task)
+		}
+	}
+	return fmt.Errorf("task not found: %s", name)
 }
@@ -0,0 +1,314 @@
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"app/internal/core/models"
+	"app/pkg/console"
+	"app/pkg/locker"
+)
+
+// blockingCommand runs until released or until its context is done, runs is
+// a pointer as every run gets its own copy of the command
+type blockingCommand struct {
+	*console.BaseCommand
+	started chan struct{}
+	release chan struct{}
+	runs    *atomic.Int32
+}
+
+func newBlockingCommand() *blockingCommand {
+	return &blockingCommand{
+		BaseCommand: console.NewCommand("test:block", "Blocks until released"),
+		started:     make(chan struct{}, 10),
+		release:     make(chan struct{}),
+		runs:        &atomic.Int32{},
+	}
+}
+
+func (c *blockingCommand) Configure(config *console.CommandConfig) {
+	config.Name = "test:block"
+	config.Description = "Blocks until released"
+	c.BaseCommand.Configure(config)
+}
+
+func (c *blockingCommand) Handle(ctx context.Context) error {
+	c.runs.Add(1)
+	c.started <- struct{}{}
+	select {
+	case <-c.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// refreshCountingLocker counts lock refreshes
+type refreshCountingLocker struct {
+	*locker.MemoryLocker
+	refreshes atomic.Int32
+}
+
+func (l *refreshCountingLocker) RefreshLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	l.refreshes.Add(1)
+	return l.MemoryLocker.RefreshLockAs(ctx, key, owner, ttl)
+}
+
+// failingRefreshLocker never manages to refresh a lock
+type failingRefreshLocker struct {
+	*locker.MemoryLocker
+}
+
+func (l *failingRefreshLocker) RefreshLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return false, errors.New("locker unavailable")
+}
+
+func TestWithoutOverlappingSkipsRunningTask(t *testing.T) {
+	s := NewScheduler(console.NewManager(), locker.NewMemoryLocker())
+	cmd := newBlockingCommand()
+	task := s.Task("test:block", cmd).WithoutOverlapping(time.Minute).task
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := s.runTask(context.Background(), task); err != nil {
+			t.Errorf("first run: %v", err)
+		}
+	}()
+	<-cmd.started
+
+	// The overlapping run is skipped without running the command
+	if err := s.runTask(context.Background(), task); err != nil {
+		t.Fatalf("overlapping run: %v", err)
+	}
+	if got := cmd.runs.Load(); got != 1 {
+		t.Fatalf("expected 1 run while the first is going, got %d", got)
+	}
+
+	close(cmd.release)
+	wg.Wait()
+
+	// Once finished the lock is released
+	if err := s.runTask(context.Background(), task); err != nil {
+		t.Fatalf("next run: %v", err)
+	}
+	if got := cmd.runs.Load(); got != 2 {
+		t.Fatalf("expected 2 runs, got %d", got)
+	}
+}
+
+func TestTimeoutCancelsContext(t *testing.T) {
+	s := NewScheduler(console.NewManager(), locker.NewMemoryLocker())
+	cmd := newBlockingCommand()
+	task := s.Task("test:block", cmd).Timeout(20 * time.Millisecond).task
+
+	err := s.runTask(context.Background(), task)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestLockIsRefreshedWhileTaskRuns(t *testing.T) {
+	l := &refreshCountingLocker{MemoryLocker: locker.NewMemoryLocker()}
+	s := NewScheduler(console.NewManager(), l)
+	cmd := newBlockingCommand()
+	task := s.Task("test:block", cmd).Unique().WithoutOverlapping(time.Second).task
+
+	done := make(chan error, 1)
+	go func() { done <- s.runTask(context.Background(), task) }()
+	<-cmd.started
+
+	// Outlive the lock expiry, the refresh keeps the lock held
+	time.Sleep(1500 * time.Millisecond)
+	if acquired, _ := l.TryLock(context.Background(), "scheduler:lock:test:block", time.Second); acquired {
+		t.Fatal("lock expired while the task was running")
+	}
+
+	close(cmd.release)
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if l.refreshes.Load() == 0 {
+		t.Fatal("expected the lock to be refreshed")
+	}
+	if acquired, _ := l.TryLock(context.Background(), "scheduler:lock:test:block", time.Second); !acquired {
+		t.Fatal("expected the lock to be released after the run")
+	}
+}
+
+func TestRunTaskByName(t *testing.T) {
+	manager := console.NewManager()
+	cmd := newBlockingCommand()
+	manager.Register(cmd)
+	s := NewScheduler(manager, locker.NewMemoryLocker())
+	if err := s.Command("test:block").Daily().Register(); err != nil {
+		t.Fatal(err)
+	}
+
+	close(cmd.release)
+	if err := s.RunTask(context.Background(), "test:block"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := cmd.runs.Load(); got != 1 {
+		t.Fatalf("expected 1 run, got %d", got)
+	}
+	if err := s.RunTask(context.Background(), "missing"); err == nil {
+		t.Fatal("expected an error for an unknown task")
+	}
+}
+
+// echoCommand prints its label option once both runs have started
+type echoCommand struct {
+	console.BaseCommand
+	barrier *sync.WaitGroup
+}
+
+func (c *echoCommand) Configure(config *console.CommandConfig) {
+	config.Name = "test:echo"
+	config.Options = []console.Option{{Name: "label"}}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *echoCommand) Handle(ctx context.Context) error {
+	c.barrier.Done()
+	c.barrier.Wait()
+	c.Line("label=%s", c.GetOption("label"))
+	return nil
+}
+
+// memoryHistory keeps the recorded runs
+type memoryHistory struct {
+	mu   sync.Mutex
+	runs []models.ScheduleRun
+}
+
+func (h *memoryHistory) RecordRun(ctx context.Context, run *models.ScheduleRun) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.runs = append(h.runs, *run)
+	return nil
+}
+
+func (h *memoryHistory) PruneRuns(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestConcurrentRunsKeepTheirOwnInput(t *testing.T) {
+	var barrier sync.WaitGroup
+	barrier.Add(2)
+	manager := console.NewManager()
+	manager.Register(&echoCommand{barrier: &barrier})
+
+	history := &memoryHistory{}
+	s := NewScheduler(manager, locker.NewMemoryLocker())
+	s.SetHistory(history, 0)
+
+	first, err := s.Command("test:echo", "--label=first").Daily().build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Command("test:echo", "--label=second").Daily().build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, task := range []Task{first, second} {
+		wg.Add(1)
+		go func(task Task) {
+			defer wg.Done()
+			if err := s.runTask(context.Background(), task); err != nil {
+				t.Errorf("run %s: %v", task.Name, err)
+			}
+		}(task)
+	}
+	wg.Wait()
+
+	if len(history.runs) != 2 {
+		t.Fatalf("expected 2 recorded runs, got %d", len(history.runs))
+	}
+	sort.Slice(history.runs, func(i, j int) bool { return history.runs[i].TaskName < history.runs[j].TaskName })
+	for i, want := range []string{"first", "second"} {
+		run := history.runs[i]
+		if run.Output != "label="+want+"\n" {
+			t.Errorf("run %s recorded output %q, want label=%s", run.TaskName, run.Output, want)
+		}
+	}
+}
+
+func TestExpiredRunDoesNotReleaseLockTakenByAnotherRun(t *testing.T) {
+	l := &failingRefreshLocker{MemoryLocker: locker.NewMemoryLocker()}
+	s := NewScheduler(console.NewManager(), l)
+	cmd := newBlockingCommand()
+	task := s.Task("test:block", cmd).Unique().WithoutOverlapping(time.Second).task
+
+	done := make(chan error, 1)
+	go func() { done <- s.runTask(context.Background(), task) }()
+	<-cmd.started
+
+	// The refresh fails, the lock expires and another host takes it
+	time.Sleep(1200 * time.Millisecond)
+	ctx := context.Background()
+	key := "scheduler:lock:test:block"
+	acquired, err := l.TryLockAs(ctx, key, "other-host", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("expected the expired lock to be taken over, acquired=%v err=%v", acquired, err)
+	}
+
+	// Finishing the old run must leave the new holder's lock in place
+	close(cmd.release)
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if acquired, _ := l.TryLock(ctx, key, time.Minute); acquired {
+		t.Fatal("the expired run released a lock held by another owner")
+	}
+	if held, _ := l.MemoryLocker.RefreshLockAs(ctx, key, "other-host", time.Minute); !held {
+		t.Fatal("expected the new owner to still hold the lock")
+	}
+}
+
+func TestVerboseReportsEveryMinute(t *testing.T) {
+	s := NewScheduler(console.NewManager(), locker.NewMemoryLocker())
+	var out bytes.Buffer
+	s.SetVerbose(&out)
+
+	if err := s.Task("test:minutely", newBlockingCommand()).EveryMinute().Register(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Task("test:daily", newBlockingCommand()).Daily().Register(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A minute with only the every-minute task due
+	s.tick(context.Background(), time.Date(2026, 1, 2, 15, 4, 30, 0, s.Location()))
+	if got := out.String(); !strings.Contains(got, "1 task(s) due: test:minutely\n") {
+		t.Fatalf("unexpected tick output %q", got)
+	}
+
+	// Midnight, both are due
+	out.Reset()
+	s.tick(context.Background(), time.Date(2026, 1, 3, 0, 0, 0, 0, s.Location()))
+	if got := out.String(); !strings.Contains(got, "2 task(s) due: test:minutely, test:daily\n") {
+		t.Fatalf("unexpected tick output %q", got)
+	}
+
+	// Ticks without due tasks are reported too
+	s = NewScheduler(console.NewManager(), locker.NewMemoryLocker())
+	s.SetVerbose(&out)
+	out.Reset()
+	s.tick(context.Background(), time.Now())
+	if got := out.String(); !strings.Contains(got, "No tasks due\n") {
+		t.Fatalf("unexpected tick output %q", got)
+	}
+}
@@ -0,0 +1,152 @@
+package schedule
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"app/internal/core/models"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DefaultSyncInterval is how often the scheduler reloads the tasks managed from the admin panel
+const DefaultSyncInterval = 10 * time.Second
+
+// TaskStore provides the scheduled tasks managed from the admin panel
+type TaskStore interface {
+	// EnabledTasks returns the tasks that should be scheduled
+	EnabledTasks(ctx context.Context) ([]models.ScheduledTask, error)
+	// ClaimRunRequests returns the tasks an admin asked to run now and clears
+	// the requests, each request is only returned to one scheduler. The tasks
+	// claimed before an error are returned with it.
+	ClaimRunRequests(ctx context.Context) ([]models.ScheduledTask, error)
+}
+
+// storedEntry tracks the cron entry of a stored task
+type storedEntry struct {
+	// id is 0 when the task is invalid and was not scheduled
+	id        cron.EntryID
+	updatedAt time.Time
+}
+
+// SetTaskStore schedules the tasks of the store next to the ones defined in
+// the Kernel. Changes are picked up every interval (DefaultSyncInterval when 0)
+// without a restart.
+func (s *Scheduler) SetTaskStore(store TaskStore, interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if interval <= 0 {
+		interval = DefaultSyncInterval
+	}
+	s.store = store
+	s.syncInterval = interval
+	s.stored = make(map[uint]storedEntry)
+}
+
+// syncLoop reloads the stored tasks until the context is cancelled
+func (s *Scheduler) syncLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.syncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncTasks(ctx)
+		}
+	}
+}
+
+// syncTasks reschedules the stored tasks that changed and runs the requested ones
+func (s *Scheduler) syncTasks(ctx context.Context) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	records, err := s.store.EnabledTasks(ctx)
+	if err != nil {
+		log.Printf("[ERROR] Failed to load scheduled tasks: %v", err)
+		return
+	}
+
+	seen := make(map[uint]bool, len(records))
+	for _, record := range records {
+		seen[record.ID] = true
+		if entry, ok := s.stored[record.ID]; ok && entry.updatedAt.Equal(record.UpdatedAt) {
+			continue
+		}
+
+		s.unscheduleStored(record.ID)
+		entry := storedEntry{updatedAt: record.UpdatedAt}
+
+		task, err := s.storedTask(record)
+		if err != nil {
+			log.Printf("[ERROR] Scheduled task %d is invalid: %v", record.ID, err)
+		} else if entry.id, err = s.cron.AddFunc(task.Spec(), s.cronJob(ctx, task)); err != nil {
+			log.Printf("[ERROR] Failed to schedule task %s: %v", task.Name, err)
+		}
+		s.stored[record.ID] = entry
+	}
+
+	// Disabled and deleted tasks
+	for id := range s.stored {
+		if !seen[id] {
+			s.unscheduleStored(id)
+		}
+	}
+
+	s.runRequested(ctx)
+}
+
+// unscheduleStored removes the cron entry of a stored task
+func (s *Scheduler) unscheduleStored(id uint) {
+	if entry, ok := s.stored[id]; ok && entry.id != 0 {
+		s.cron.Remove(entry.id)
+	}
+	delete(s.stored, id)
+}
+
+// runRequested runs the tasks an admin asked to run now, through the same
+// locking as scheduled runs
+func (s *Scheduler) runRequested(ctx context.Context) {
+	// The requests claimed before an error are still run
+	records, err := s.store.ClaimRunRequests(ctx)
+	if err != nil {
+		log.Printf("[ERROR] Failed to claim run requests: %v", err)
+	}
+
+	for _, record := range records {
+		task, err := s.storedTask(record)
+		if err != nil {
+			// Keep a trace of the request in the history
+			s.recordRun(ctx, Task{Name: storedTaskName(record)}, time.Now(), "", err)
+			continue
+		}
+
+		log.Printf("Running task %s on request", task.Name)
+		go s.dispatch(ctx, task)
+	}
+}
+
+// storedTask builds the task of a stored record
+func (s *Scheduler) storedTask(record models.ScheduledTask) (Task, error) {
+	b := s.Command(record.Command, record.Args...).Cron(record.Expression)
+	if record.Timezone != "" {
+		b.Timezone(record.Timezone)
+	}
+	if record.Unique {
+		b.Unique()
+	}
+	return b.build()
+}
+
+// storedTaskName returns the task name used for a stored record
+func storedTaskName(record models.ScheduledTask) string {
+	name := record.Command
+	for _, arg := range record.Args {
+		name += " " + arg
+	}
+	return name
+}
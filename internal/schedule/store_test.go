@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"app/internal/core/models"
+	"app/pkg/console"
+	"app/pkg/locker"
+)
+
+// fakeTaskStore serves tasks from memory
+type fakeTaskStore struct {
+	mu       sync.Mutex
+	tasks    []models.ScheduledTask
+	requests []models.ScheduledTask
+}
+
+func (f *fakeTaskStore) EnabledTasks(ctx context.Context) ([]models.ScheduledTask, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]models.ScheduledTask(nil), f.tasks...), nil
+}
+
+func (f *fakeTaskStore) ClaimRunRequests(ctx context.Context) ([]models.ScheduledTask, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := f.requests
+	f.requests = nil
+	return requests, nil
+}
+
+func storedRecord(id uint, expression string, updatedAt time.Time) models.ScheduledTask {
+	record := models.ScheduledTask{Command: "test:block", Expression: expression}
+	record.ID = id
+	record.UpdatedAt = updatedAt
+	return record
+}
+
+func TestSyncTasksFollowsStore(t *testing.T) {
+	manager := console.NewManager()
+	manager.Register(newBlockingCommand())
+	s := NewScheduler(manager, locker.NewMemoryLocker())
+
+	now := time.Now()
+	store := &fakeTaskStore{tasks: []models.ScheduledTask{
+		storedRecord(1, "*/5 * * * *", now),
+		storedRecord(2, "not a cron", now),
+	}}
+	s.SetTaskStore(store, time.Minute)
+
+	s.syncTasks(context.Background())
+	if got := len(s.cron.Entries()); got != 1 {
+		t.Fatalf("expected 1 entry, got %d", got)
+	}
+	first := s.stored[1].id
+
+	// Unchanged tasks keep their entry
+	s.syncTasks(context.Background())
+	if s.stored[1].id != first {
+		t.Fatalf("unchanged task was rescheduled")
+	}
+
+	// Updated tasks are rescheduled, removed ones unscheduled
+	store.tasks = []models.ScheduledTask{storedRecord(1, "@hourly", now.Add(time.Second))}
+	s.syncTasks(context.Background())
+	entries := s.cron.Entries()
+	if len(entries) != 1 || entries[0].ID == first {
+		t.Fatalf("expected the task to be rescheduled, got %+v", entries)
+	}
+	if _, ok := s.stored[2]; ok {
+		t.Fatalf("removed task is still tracked")
+	}
+
+	store.tasks = nil
+	s.syncTasks(context.Background())
+	if got := len(s.cron.Entries()); got != 0 {
+		t.Fatalf("expected no entries, got %d", got)
+	}
+}
+
+func TestSyncTasksRunsRequestedTasks(t *testing.T) {
+	manager := console.NewManager()
+	cmd := newBlockingCommand()
+	manager.Register(cmd)
+	s := NewScheduler(manager, locker.NewMemoryLocker())
+
+	store := &fakeTaskStore{requests: []models.ScheduledTask{storedRecord(1, "@daily", time.Now())}}
+	s.SetTaskStore(store, time.Minute)
+	s.syncTasks(context.Background())
+
+	select {
+	case <-cmd.started:
+	case <-time.After(time.Second):
+		t.Fatal("requested task did not run")
+	}
+	close(cmd.release)
+}
@@ -3,6 +3,9 @@ package console
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"reflect"
 )
 
 // Command represents a console command interface
@@ -24,6 +27,8 @@ type BaseCommand struct {
 	Arguments   map[string]string
 	Options     []Option
 	values      map[string]string
+	input       *Input
+	output      io.Writer
 }
 
 func NewCommand(name, description string) *BaseCommand {
@@ -49,9 +54,57 @@ func (c *BaseCommand) AddArgument(name, description string) {
 }
 
 func (c *BaseCommand) GetArgument(name string) string {
+	if c.input != nil {
+		if value := c.input.Argument(name); value != "" {
+			return value
+		}
+	}
 	return c.values[name]
 }
 
+// GetArguments returns all values of an array argument
+func (c *BaseCommand) GetArguments(name string) []string {
+	if c.input != nil {
+		return c.input.Arguments(name)
+	}
+	if value, ok := c.values[name]; ok {
+		return []string{value}
+	}
+	return nil
+}
+
+// SetInput sets the parsed arguments and options of the current invocation
+func (c *BaseCommand) SetInput(input *Input) {
+	c.input = input
+}
+
+// Instance returns a shallow copy of the command with its own BaseCommand, so
+// the input and output of one run do not leak into a concurrent run. State the
+// command keeps in its own fields is copied as well, state shared between runs
+// must sit behind pointers. Commands that are not struct pointers are returned as is.
+func Instance(cmd Command) Command {
+	v := reflect.ValueOf(cmd)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return cmd
+	}
+
+	instance := reflect.New(v.Elem().Type())
+	instance.Elem().Set(v.Elem())
+
+	// Commands embedding *BaseCommand would still share it
+	if base := instance.Elem().FieldByName("BaseCommand"); base.IsValid() && base.CanSet() {
+		if original, ok := base.Interface().(*BaseCommand); ok && original != nil {
+			copied := *original
+			base.Set(reflect.ValueOf(&copied))
+		}
+	}
+
+	if copied, ok := instance.Interface().(Command); ok {
+		return copied
+	}
+	return cmd
+}
+
 func (c *BaseCommand) SetValue(name, value string) {
 	c.values[name] = value
 }
@@ -75,6 +128,9 @@ type Argument struct {
 	Description string
 	Required    bool
 	Value       string
+	Default     string
+	// Array takes all remaining positional values, only allowed on the last argument
+	Array bool
 }
 
 // Option represents a command option
@@ -84,6 +140,9 @@ type Option struct {
 	Description string
 	Required    bool
 	Value       string
+	Default     string
+	// Flag options take no value, they are "true" when given
+	Flag bool
 }
 
 // GetUsage returns the command usage
@@ -104,9 +163,15 @@ func (c *BaseCommand) Configure(config *CommandConfig) {
 
 // GetOption returns the value of an option
 func (c *BaseCommand) GetOption(name string) string {
+	if c.input != nil {
+		return c.input.Option(name)
+	}
 	for _, opt := range c.Options {
 		if opt.Name == name {
-			return opt.Value
+			if opt.Value != "" {
+				return opt.Value
+			}
+			return opt.Default
 		}
 	}
 	return ""
@@ -117,9 +182,22 @@ func (c *BaseCommand) HasOption(name string) bool {
 	return c.GetOption(name) != ""
 }
 
+// SetOutput sets where the command writes its messages, nil restores stdout
+func (c *BaseCommand) SetOutput(w io.Writer) {
+	c.output = w
+}
+
+// Output returns where the command writes its messages
+func (c *BaseCommand) Output() io.Writer {
+	if c.output == nil {
+		return os.Stdout
+	}
+	return c.output
+}
+
 // Line writes a line to the output
 func (c *BaseCommand) Line(format string, args ...interface{}) {
-	fmt.Printf(format+"\n", args...)
+	fmt.Fprintf(c.Output(), format+"\n", args...)
 }
 
 // Info writes an info message
@@ -0,0 +1,121 @@
+package console
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// nonIdentifier matches the characters not allowed in shell function names
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// completionWords returns the words completed after a command: its options,
+// the command names for help and the shells for completion
+func (m *Manager) completionWords(name string) []string {
+	switch name {
+	case "help":
+		return m.sortedNames()
+	case "completion":
+		return []string{"bash", "zsh"}
+	}
+
+	config := m.configs[name]
+	words := make([]string, 0, len(config.Options)+1)
+	for _, option := range append(append([]Option(nil), config.Options...), helpOption(config)) {
+		words = append(words, "--"+option.Name)
+	}
+	return words
+}
+
+// writeBashCompletion writes a bash completion script for the registered commands
+//
+// The script is generated from the commands registered when it is dumped,
+// it has to be dumped again after adding commands.
+func (m *Manager) writeBashCompletion(w io.Writer) error {
+	function := "_" + nonIdentifier.ReplaceAllString(m.name, "_") + "_completion"
+
+	var cases strings.Builder
+	for _, name := range m.sortedNames() {
+		fmt.Fprintf(&cases, "        %s) candidates=%q ;;\n", name, strings.Join(m.completionWords(name), " "))
+	}
+
+	_, err := fmt.Fprintf(w, `# bash completion for %[1]s, load it with:
+#   source <(%[1]s completion bash)
+%[2]s() {
+    local cur words cword
+    # Command names contain colons, which bash splits words on
+    if declare -F _get_comp_words_by_ref >/dev/null; then
+        _get_comp_words_by_ref -n : cur words cword
+    else
+        cur="${COMP_WORDS[COMP_CWORD]}"
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+    fi
+
+    local candidates
+    if [[ $cword -eq 1 ]]; then
+        candidates=%[3]q
+    else
+        case "${words[1]}" in
+%[4]s        esac
+    fi
+
+    COMPREPLY=($(compgen -W "$candidates" -- "$cur"))
+    if declare -F __ltrim_colon_completions >/dev/null; then
+        __ltrim_colon_completions "$cur"
+    fi
+}
+complete -F %[2]s %[1]s
+`, m.name, function, strings.Join(m.sortedNames(), " "), cases.String())
+	return err
+}
+
+// writeZshCompletion writes a zsh completion script for the registered commands
+func (m *Manager) writeZshCompletion(w io.Writer) error {
+	function := "_" + nonIdentifier.ReplaceAllString(m.name, "_")
+
+	var commands strings.Builder
+	for _, name := range m.sortedNames() {
+		fmt.Fprintf(&commands, "        %s\n", zshQuote(strings.ReplaceAll(name, ":", `\:`)+":"+m.configs[name].Description))
+	}
+
+	var cases strings.Builder
+	for _, name := range m.sortedNames() {
+		words := make([]string, 0)
+		for _, word := range m.completionWords(name) {
+			words = append(words, zshQuote(word))
+		}
+		fmt.Fprintf(&cases, "        %s) compadd -- %s ;;\n", zshQuote(name), strings.Join(words, " "))
+	}
+
+	_, err := fmt.Fprintf(w, `#compdef %[1]s
+# zsh completion for %[1]s, load it with:
+#   source <(%[1]s completion zsh)
+%[2]s() {
+    local -a commands
+    commands=(
+%[3]s    )
+
+    if (( CURRENT == 2 )); then
+        _describe -t commands 'command' commands
+        return
+    fi
+
+    case "$words[2]" in
+%[4]s    esac
+}
+
+if [[ "$funcstack[1]" == "%[2]s" ]]; then
+    %[2]s "$@"
+else
+    compdef %[2]s %[1]s
+fi
+`, m.name, function, commands.String(), cases.String())
+	return err
+}
+
+// zshQuote quotes a word for zsh with single quotes
+func zshQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
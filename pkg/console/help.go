@@ -0,0 +1,324 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is how many commands "did you mean" proposes at most
+const maxSuggestions = 5
+
+// namespace returns the part of a command name before the colon, "" for global commands
+func namespace(name string) string {
+	if ns, _, ok := strings.Cut(name, ":"); ok {
+		return ns
+	}
+	return ""
+}
+
+// sortedNames returns the registered command names in alphabetical order
+func (m *Manager) sortedNames() []string {
+	names := make([]string, 0, len(m.configs))
+	for name := range m.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeList writes the commands grouped by namespace, global commands first.
+// A non empty ns only lists the commands of that namespace.
+func (m *Manager) writeList(w io.Writer, ns string) error {
+	names := m.sortedNames()
+	if ns != "" {
+		filtered := names[:0]
+		for _, name := range names {
+			if namespace(name) == ns {
+				filtered = append(filtered, name)
+			}
+		}
+		if len(filtered) == 0 {
+			return fmt.Errorf("there are no commands in the %q namespace", ns)
+		}
+		names = filtered
+	}
+
+	// Global commands sort before the namespaced ones
+	sort.SliceStable(names, func(i, j int) bool {
+		return namespace(names[i]) == "" && namespace(names[j]) != ""
+	})
+
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintf(w, "  %s <command> [arguments] [options]\n\n", m.name)
+	fmt.Fprintln(w, "Available commands:")
+
+	current := ""
+	for _, name := range names {
+		if ns := namespace(name); ns != current {
+			current = ns
+			fmt.Fprintf(w, " %s\n", ns)
+		}
+		fmt.Fprintf(w, "  %-*s  %s\n", width, name, m.configs[name].Description)
+	}
+
+	fmt.Fprintf(w, "\nRun \"%s help <command>\" for the arguments and options of a command.\n", m.name)
+	return nil
+}
+
+// writeHelp writes the help of a command generated from its configuration
+func (m *Manager) writeHelp(w io.Writer, name string) error {
+	config, ok := m.configs[name]
+	if !ok {
+		return m.notFound(name)
+	}
+
+	if config.Description != "" {
+		fmt.Fprintln(w, "Description:")
+		fmt.Fprintf(w, "  %s\n\n", config.Description)
+	}
+
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintf(w, "  %s\n", synopsis(config))
+	if config.Usage != "" && config.Usage != config.Name && config.Usage != synopsis(config) {
+		fmt.Fprintf(w, "  %s\n", config.Usage)
+	}
+
+	if len(config.Arguments) > 0 {
+		width := 0
+		for _, argument := range config.Arguments {
+			width = max(width, len(argument.Name))
+		}
+
+		fmt.Fprintln(w, "\nArguments:")
+		for _, argument := range config.Arguments {
+			fmt.Fprintf(w, "  %-*s  %s%s\n", width, argument.Name, argument.Description, defaultNote(argument.Default))
+		}
+	}
+
+	options := append(append([]Option(nil), config.Options...), helpOption(config))
+	labels := make([]string, len(options))
+	width := 0
+	for i, option := range options {
+		labels[i] = optionLabel(option)
+		width = max(width, len(labels[i]))
+	}
+
+	fmt.Fprintln(w, "\nOptions:")
+	for i, option := range options {
+		fmt.Fprintf(w, "  %-*s  %s%s\n", width, labels[i], option.Description, defaultNote(option.Default))
+	}
+	return nil
+}
+
+// synopsis builds the usage line of a command from its arguments
+func synopsis(config *CommandConfig) string {
+	parts := []string{config.Name, "[options]"}
+	for _, argument := range config.Arguments {
+		part := "<" + argument.Name + ">"
+		if argument.Array {
+			part += "..."
+		}
+		if !argument.Required {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// optionLabel formats an option as "-s, --name=NAME"
+func optionLabel(option Option) string {
+	label := "    --" + option.Name
+	if option.Shortcut != "" {
+		label = "-" + option.Shortcut + ", --" + option.Name
+	}
+	if !option.Flag {
+		label += "=" + strings.ToUpper(strings.ReplaceAll(option.Name, "-", "_"))
+	}
+	return label
+}
+
+// defaultNote describes a default value in the help
+func defaultNote(value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [default: %q]", value)
+}
+
+// helpOption is the --help flag every command accepts, -h unless the command uses it
+func helpOption(config *CommandConfig) Option {
+	option := Option{Name: "help", Shortcut: "h", Description: "Display help for the command", Flag: true}
+	for _, declared := range config.Options {
+		if declared.Shortcut == "h" {
+			option.Shortcut = ""
+		}
+	}
+	return option
+}
+
+// helpRequested reports whether the arguments ask for the help of the command,
+// commands declaring their own help option handle it themselves
+func helpRequested(config *CommandConfig, args []string) bool {
+	for _, declared := range config.Options {
+		if declared.Name == "help" {
+			return false
+		}
+	}
+
+	short := helpOption(config).Shortcut
+	for _, arg := range args {
+		switch {
+		case arg == "--":
+			return false
+		case arg == "--help", short != "" && arg == "-"+short:
+			return true
+		}
+	}
+	return false
+}
+
+// notFound returns the error for an unknown command with the closest command names
+func (m *Manager) notFound(name string) error {
+	suggestions := m.suggest(name)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("command not found: %s", name)
+	}
+	return fmt.Errorf("command not found: %s\n\nDid you mean one of these?\n    %s", name, strings.Join(suggestions, "\n    "))
+}
+
+// suggest returns the registered commands that look like name: the commands
+// of the namespace it names or contains, and the names within a few typos
+func (m *Manager) suggest(name string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	threshold := max(1, len(name)/3)
+	var candidates []candidate
+	for _, command := range m.sortedNames() {
+		distance := levenshtein(name, command)
+		switch {
+		case distance <= threshold:
+		case namespace(command) != "" && (namespace(command) == name || namespace(command) == namespace(name)):
+			distance = threshold + 1
+		case len(name) > 2 && strings.Contains(command, name):
+			distance = threshold + 2
+		default:
+			continue
+		}
+		candidates = append(candidates, candidate{command, distance})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, c := range candidates {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, c.name)
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// listCommand lists the registered commands
+type listCommand struct {
+	*BaseCommand
+	manager *Manager
+}
+
+func (c *listCommand) Configure(config *CommandConfig) {
+	config.Name = "list"
+	config.Description = "List the commands"
+	config.Usage = "list [namespace]"
+	config.Arguments = []Argument{
+		{Name: "namespace", Description: "Only list the commands of this namespace, e.g. queue"},
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *listCommand) Handle(ctx context.Context) error {
+	return c.manager.writeList(c.Output(), c.GetArgument("namespace"))
+}
+
+// helpCommand shows the help of a command
+type helpCommand struct {
+	*BaseCommand
+	manager *Manager
+}
+
+func (c *helpCommand) Configure(config *CommandConfig) {
+	config.Name = "help"
+	config.Description = "Display help for a command"
+	config.Usage = "help [command]"
+	config.Arguments = []Argument{
+		{Name: "command_name", Description: "The command name", Default: "help"},
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *helpCommand) Handle(ctx context.Context) error {
+	return c.manager.writeHelp(c.Output(), c.GetArgument("command_name"))
+}
+
+// completionCommand prints a shell completion script
+type completionCommand struct {
+	*BaseCommand
+	manager *Manager
+}
+
+func (c *completionCommand) Configure(config *CommandConfig) {
+	config.Name = "completion"
+	config.Description = "Dump the shell completion script"
+	config.Usage = "completion {bash|zsh}"
+	config.Arguments = []Argument{
+		{Name: "shell", Description: "The shell, bash or zsh", Required: true},
+	}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *completionCommand) Handle(ctx context.Context) error {
+	switch shell := c.GetArgument("shell"); shell {
+	case "bash":
+		return c.manager.writeBashCompletion(c.Output())
+	case "zsh":
+		return c.manager.writeZshCompletion(c.Output())
+	default:
+		return fmt.Errorf("unsupported shell %q, use bash or zsh", shell)
+	}
+}
@@ -0,0 +1,90 @@
+package console
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// namedCommand is a command with only a name and a description
+type namedCommand struct {
+	*BaseCommand
+}
+
+func (c *namedCommand) Configure(config *CommandConfig) {
+	config.Name = c.Name
+	config.Description = c.Description
+	c.BaseCommand.Configure(config)
+}
+
+func testManager(names ...string) *Manager {
+	m := NewManager()
+	for _, name := range names {
+		m.Register(&namedCommand{NewCommand(name, "Runs "+name)})
+	}
+	return m
+}
+
+func TestSuggest(t *testing.T) {
+	m := testManager("queue:retry", "queue:failed", "queue:flush", "migrate", "make:model")
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"queue:retyr", []string{"queue:retry", "queue:failed", "queue:flush"}},
+		{"migrat", []string{"migrate"}},
+		{"queue", []string{"queue:failed", "queue:flush", "queue:retry"}},
+		{"model", []string{"make:model"}},
+		{"zzzzzz", []string{}},
+	}
+	for _, tt := range tests {
+		if got := m.suggest(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("suggest(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteListGroupsByNamespace(t *testing.T) {
+	m := testManager("queue:retry", "migrate", "make:model", "queue:failed")
+
+	var out bytes.Buffer
+	if err := m.writeList(&out, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	_, listing, _ := strings.Cut(out.String(), "Available commands:\n")
+	var order []string
+	for _, line := range strings.Split(listing, "\n") {
+		if fields := strings.Fields(line); strings.HasPrefix(line, " ") && len(fields) > 0 {
+			order = append(order, fields[0])
+		}
+	}
+	want := []string{"completion", "help", "list", "migrate", "make", "make:model", "queue", "queue:failed", "queue:retry"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("listing order = %v, want %v", order, want)
+	}
+}
+
+func TestHelpRequested(t *testing.T) {
+	config := testConfig()
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"--help"}, true},
+		{[]string{"users", "-h"}, true},
+		{[]string{"users", "--", "--help"}, false},
+		{[]string{"users"}, false},
+	}
+	for _, tt := range tests {
+		if got := helpRequested(config, tt.args); got != tt.want {
+			t.Errorf("helpRequested(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	if got := synopsis(config); got != "logs:prune [options] <table> [<ids>...]" {
+		t.Errorf("synopsis = %q", got)
+	}
+}
@@ -0,0 +1,176 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// inputContextKey is the context key of the parsed Input
+type inputContextKey struct{}
+
+// Input holds the arguments and options of a command invocation, parsed
+// against the Arguments and Options declared in its CommandConfig
+type Input struct {
+	arguments map[string][]string
+	options   map[string]string
+}
+
+// Argument returns the value of an argument, the first value for array arguments
+func (in *Input) Argument(name string) string {
+	if values := in.arguments[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Arguments returns all values of an argument
+func (in *Input) Arguments(name string) []string {
+	return in.arguments[name]
+}
+
+// Option returns the value of an option, flags are "true" when given
+func (in *Input) Option(name string) string {
+	return in.options[name]
+}
+
+// Parse parses command line tokens against a command configuration.
+//
+// Positional tokens fill the declared arguments in order, an Array argument
+// takes the remaining ones. Options are given as --name=value, --name value,
+// -s value or -s=value, flags take no value. Everything after "--" is positional.
+func Parse(config *CommandConfig, args []string) (*Input, error) {
+	input := &Input{
+		arguments: make(map[string][]string),
+		options:   make(map[string]string),
+	}
+
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		token := args[i]
+
+		if token == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(token) < 2 || token[0] != '-' {
+			positional = append(positional, token)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(token, "-"), "=")
+		option, ok := findOption(config.Options, name, !strings.HasPrefix(token, "--"))
+		if !ok {
+			return nil, fmt.Errorf("unknown option %q", token)
+		}
+
+		switch {
+		case option.Flag && !hasValue:
+			value = "true"
+		case option.Flag:
+			// Allow --flag=false to switch off a flag that defaults to true
+			if value == "false" || value == "0" {
+				value = ""
+			}
+		case !hasValue:
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("option --%s requires a value", option.Name)
+			}
+			i++
+			value = args[i]
+		}
+		input.options[option.Name] = value
+	}
+
+	if err := input.bindArguments(config.Arguments, positional); err != nil {
+		return nil, err
+	}
+
+	for _, option := range config.Options {
+		if _, ok := input.options[option.Name]; ok {
+			continue
+		}
+		if option.Default != "" {
+			input.options[option.Name] = option.Default
+		} else if option.Required {
+			return nil, fmt.Errorf("missing required option --%s", option.Name)
+		}
+	}
+
+	return input, nil
+}
+
+// bindArguments assigns positional tokens to the declared arguments
+func (in *Input) bindArguments(arguments []Argument, positional []string) error {
+	for i, argument := range arguments {
+		var values []string
+		switch {
+		case argument.Array:
+			if i != len(arguments)-1 {
+				return fmt.Errorf("array argument %q must be the last argument", argument.Name)
+			}
+			values = positional
+			positional = nil
+		case len(positional) > 0:
+			values = positional[:1]
+			positional = positional[1:]
+		}
+
+		if len(values) == 0 {
+			if argument.Default != "" {
+				values = []string{argument.Default}
+			} else if argument.Required {
+				return fmt.Errorf("missing required argument %q", argument.Name)
+			} else {
+				continue
+			}
+		}
+		in.arguments[argument.Name] = values
+	}
+
+	if len(positional) > 0 {
+		return fmt.Errorf("too many arguments, unexpected %q", positional[0])
+	}
+	return nil
+}
+
+// findOption looks up an option by name, or by shortcut for single dash tokens
+func findOption(options []Option, name string, short bool) (Option, bool) {
+	for _, option := range options {
+		if short && option.Shortcut != "" && option.Shortcut == name {
+			return option, true
+		}
+		if !short && option.Name == name {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+// inputReceiver is implemented by commands that read their parsed input,
+// BaseCommand implements it
+type inputReceiver interface {
+	SetInput(input *Input)
+}
+
+// Bind hands the parsed input to the command and returns a context carrying it.
+// The input is set on the command itself, commands that may run concurrently
+// with different arguments must be bound through their own Instance.
+func Bind(ctx context.Context, cmd Command, input *Input) context.Context {
+	if receiver, ok := cmd.(inputReceiver); ok {
+		receiver.SetInput(input)
+	} else {
+		for name, values := range input.arguments {
+			if len(values) > 0 {
+				cmd.SetValue(name, values[0])
+			}
+		}
+	}
+	return context.WithValue(ctx, inputContextKey{}, input)
+}
+
+// InputFromContext returns the input bound to the context
+func InputFromContext(ctx context.Context) (*Input, bool) {
+	input, ok := ctx.Value(inputContextKey{}).(*Input)
+	return input, ok
+}
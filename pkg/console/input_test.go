@@ -0,0 +1,166 @@
+package console
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func testConfig() *CommandConfig {
+	return &CommandConfig{
+		Name: "logs:prune",
+		Arguments: []Argument{
+			{Name: "table", Required: true},
+			{Name: "ids", Array: true},
+		},
+		Options: []Option{
+			{Name: "days", Shortcut: "d", Default: "7"},
+			{Name: "force", Shortcut: "f", Flag: true},
+			{Name: "connection"},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		table   string
+		ids     []string
+		options map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "defaults",
+			args:    []string{"login_logs"},
+			table:   "login_logs",
+			options: map[string]string{"days": "7", "force": "", "connection": ""},
+		},
+		{
+			name:    "equals and flag",
+			args:    []string{"login_logs", "--days=30", "--force"},
+			table:   "login_logs",
+			options: map[string]string{"days": "30", "force": "true"},
+		},
+		{
+			name:    "separate value and shortcuts",
+			args:    []string{"-d", "14", "login_logs", "-f", "--connection", "mysql"},
+			table:   "login_logs",
+			options: map[string]string{"days": "14", "force": "true", "connection": "mysql"},
+		},
+		{
+			name:    "array argument",
+			args:    []string{"login_logs", "1", "2", "--", "-3"},
+			table:   "login_logs",
+			ids:     []string{"1", "2", "-3"},
+			options: map[string]string{"days": "7"},
+		},
+		{
+			name:    "flag switched off",
+			args:    []string{"login_logs", "--force=false"},
+			table:   "login_logs",
+			options: map[string]string{"force": ""},
+		},
+		{name: "missing required argument", args: []string{"--days=1"}, wantErr: true},
+		{name: "unknown option", args: []string{"login_logs", "--nope"}, wantErr: true},
+		{name: "missing option value", args: []string{"login_logs", "--days"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := Parse(testConfig(), tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := input.Argument("table"); got != tt.table {
+				t.Errorf("table = %q, want %q", got, tt.table)
+			}
+			if got := input.Arguments("ids"); !reflect.DeepEqual(got, tt.ids) {
+				t.Errorf("ids = %v, want %v", got, tt.ids)
+			}
+			for name, want := range tt.options {
+				if got := input.Option(name); got != want {
+					t.Errorf("option %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRequiredOption(t *testing.T) {
+	config := &CommandConfig{Options: []Option{{Name: "queue", Required: true}}}
+	if _, err := Parse(config, nil); err == nil {
+		t.Fatal("expected an error for the missing required option")
+	}
+	if _, err := Parse(config, []string{"--queue=high"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+type pruneCommand struct {
+	*BaseCommand
+	days string
+}
+
+func (c *pruneCommand) Configure(config *CommandConfig) {
+	config.Name = "logs:prune"
+	config.Options = []Option{{Name: "days", Default: "7"}}
+	c.BaseCommand.Configure(config)
+}
+
+func (c *pruneCommand) Handle(ctx context.Context) error {
+	c.days = c.GetOption("days")
+	return nil
+}
+
+func TestManagerCall(t *testing.T) {
+	manager := NewManager()
+	cmd := &pruneCommand{BaseCommand: NewCommand("logs:prune", "")}
+	manager.Register(cmd)
+
+	if err := manager.Call(context.Background(), "logs:prune", "--days=30"); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if cmd.days != "30" {
+		t.Fatalf("days = %q, want 30", cmd.days)
+	}
+
+	if err := manager.Call(context.Background(), "logs:prune"); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	if cmd.days != "7" {
+		t.Fatalf("days = %q, want the default 7", cmd.days)
+	}
+
+	if err := manager.Call(context.Background(), "logs:prune", "--weeks=1"); err == nil {
+		t.Fatal("expected an error for an unknown option")
+	}
+}
+
+func TestInstanceHasItsOwnInput(t *testing.T) {
+	cmd := &pruneCommand{BaseCommand: NewCommand("logs:prune", "")}
+	cmd.Configure(&CommandConfig{})
+	config := &CommandConfig{Options: []Option{{Name: "days", Default: "7"}}}
+
+	first, second := Instance(cmd), Instance(cmd)
+	firstInput, _ := Parse(config, []string{"--days=30"})
+	secondInput, _ := Parse(config, []string{"--days=90"})
+	Bind(context.Background(), first, firstInput)
+	Bind(context.Background(), second, secondInput)
+
+	if got := first.(*pruneCommand).GetOption("days"); got != "30" {
+		t.Fatalf("first instance days = %q, want 30", got)
+	}
+	if got := second.(*pruneCommand).GetOption("days"); got != "90" {
+		t.Fatalf("second instance days = %q, want 90", got)
+	}
+	if got := cmd.GetOption("days"); got != "7" {
+		t.Fatalf("registered command days = %q, want the untouched default 7", got)
+	}
+}
@@ -4,18 +4,29 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
 // Manager manages console commands
 type Manager struct {
 	commands map[string]Command
+	configs  map[string]*CommandConfig
+	// name is the program name shown in the help and completion scripts
+	name string
 }
 
-// NewManager creates a new command manager
+// NewManager creates a new command manager with the list, help and completion commands
 func NewManager() *Manager {
-	return &Manager{
+	m := &Manager{
 		commands: make(map[string]Command),
+		configs:  make(map[string]*CommandConfig),
+		name:     filepath.Base(os.Args[0]),
 	}
+
+	m.Register(&listCommand{BaseCommand: NewCommand("list", "List the commands"), manager: m})
+	m.Register(&helpCommand{BaseCommand: NewCommand("help", "Display help for a command"), manager: m})
+	m.Register(&completionCommand{BaseCommand: NewCommand("completion", "Dump the shell completion script"), manager: m})
+	return m
 }
 
 // Register registers a command
@@ -23,6 +34,7 @@ func (m *Manager) Register(cmd Command) {
 	config := &CommandConfig{}
 	cmd.Configure(config)
 	m.commands[config.Name] = cmd
+	m.configs[config.Name] = config
 }
 
 // FindCommand finds a command by name
@@ -34,25 +46,46 @@ func (m *Manager) FindCommand(name string) Command {
 func (m *Manager) RunFromArgs() error {
 	args := os.Args[1:]
 	if len(args) == 0 {
-		return m.showAvailableCommands()
+		return m.writeList(os.Stdout, "")
 	}
 
-	cmdName := args[0]
-	cmd := m.FindCommand(cmdName)
-	if cmd == nil {
-		return fmt.Errorf("command not found: %s", cmdName)
+	// "command --help" shows the help instead of running the command
+	if config, ok := m.configs[args[0]]; ok && helpRequested(config, args[1:]) {
+		return m.writeHelp(os.Stdout, args[0])
 	}
 
-	// Create context with arguments
+	// The raw arguments stay available for commands that parse them themselves
 	ctx := context.WithValue(context.Background(), "args", args)
-	return cmd.Handle(ctx)
+	return m.Call(ctx, args[0], args[1:]...)
+}
+
+// Parse parses the arguments and options of a registered command
+func (m *Manager) Parse(name string, args []string) (*Input, error) {
+	config, ok := m.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("command not found: %s", name)
+	}
+
+	input, err := Parse(config, args)
+	if err != nil {
+		if config.Usage != "" {
+			return nil, fmt.Errorf("%s: %v\nUsage: %s\nRun \"%s help %s\" for more information", name, err, config.Usage, m.name, name)
+		}
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return input, nil
 }
 
-// showAvailableCommands shows all available commands
-func (m *Manager) showAvailableCommands() error {
-	fmt.Println("Available commands:")
-	for name, cmd := range m.commands {
-		fmt.Printf("  %s\t%s\n", name, cmd.GetDescription())
+// Call parses the arguments and runs a registered command
+func (m *Manager) Call(ctx context.Context, name string, args ...string) error {
+	cmd := m.FindCommand(name)
+	if cmd == nil {
+		return m.notFound(name)
+	}
+
+	input, err := m.Parse(name, args)
+	if err != nil {
+		return err
 	}
-	return nil
+	return cmd.Handle(Bind(ctx, cmd, input))
 }
@@ -0,0 +1,31 @@
+package denylist
+
+import (
+	"context"
+	"time"
+)
+
+// Denylist records revoked access tokens until they would have expired
+type Denylist interface {
+	// RevokeToken revokes the tokens identified by tokenID, the ID of a
+	// single token or of the session a group of tokens was issued for
+	RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error
+	// RevokeUser revokes every token of a user issued at or before at
+	RevokeUser(ctx context.Context, userID uint, at time.Time, ttl time.Duration) error
+	// IsRevoked reports whether a token of a user was revoked under any of its IDs
+	IsRevoked(ctx context.Context, userID uint, issuedAt time.Time, tokenIDs ...string) (bool, error)
+}
+
+var (
+	_ Denylist = (*RedisDenylist)(nil)
+	_ Denylist = (*MemoryDenylist)(nil)
+	_ Denylist = (*FallbackDenylist)(nil)
+)
+
+// revokedBefore reports whether a token issued at issuedAt falls under a
+// user revocation at the given Unix time in milliseconds. Tokens carry the
+// issue time in milliseconds, so a token issued right after the revocation,
+// such as on the sign-in that follows a password change, stays valid.
+func revokedBefore(issuedAt time.Time, revokedAt int64) bool {
+	return issuedAt.UnixMilli() <= revokedAt
+}
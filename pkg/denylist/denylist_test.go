@@ -0,0 +1,85 @@
+package denylist
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryDenylist(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDenylist()
+	now := time.Now()
+
+	require.NoError(t, d.RevokeToken(ctx, "a", time.Minute))
+	revoked, err := d.IsRevoked(ctx, 1, now, "a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, _ = d.IsRevoked(ctx, 1, now, "b")
+	assert.False(t, revoked)
+	revoked, _ = d.IsRevoked(ctx, 1, now, "b", "a")
+	assert.True(t, revoked)
+
+	// Tokens issued up to the revocation are revoked, later ones are not
+	require.NoError(t, d.RevokeUser(ctx, 2, now, time.Minute))
+	revoked, _ = d.IsRevoked(ctx, 2, now.Add(-time.Minute), "c")
+	assert.True(t, revoked)
+	revoked, _ = d.IsRevoked(ctx, 2, now.Add(time.Second), "d")
+	assert.False(t, revoked)
+	// Within the second of the revocation too, a sign-in right after it stays valid
+	revoked, _ = d.IsRevoked(ctx, 2, now.Add(time.Millisecond), "d")
+	assert.False(t, revoked)
+	revoked, _ = d.IsRevoked(ctx, 2, now, "c")
+	assert.True(t, revoked)
+	revoked, _ = d.IsRevoked(ctx, 3, now.Add(-time.Minute), "e")
+	assert.False(t, revoked)
+
+	// Revocations end with the lifetime of the tokens
+	require.NoError(t, d.RevokeToken(ctx, "f", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	revoked, _ = d.IsRevoked(ctx, 1, now, "f")
+	assert.False(t, revoked)
+}
+
+// unavailableDenylist fails like an unreachable Redis
+type unavailableDenylist struct{}
+
+var errUnavailable = errors.New("connection refused")
+
+func (unavailableDenylist) RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error {
+	return errUnavailable
+}
+
+func (unavailableDenylist) RevokeUser(ctx context.Context, userID uint, at time.Time, ttl time.Duration) error {
+	return errUnavailable
+}
+
+func (unavailableDenylist) IsRevoked(ctx context.Context, userID uint, issuedAt time.Time, tokenIDs ...string) (bool, error) {
+	return false, errUnavailable
+}
+
+func TestFallbackDenylist(t *testing.T) {
+	ctx := context.Background()
+	d := NewFallbackDenylist(unavailableDenylist{}, NewMemoryDenylist())
+
+	require.NoError(t, d.RevokeToken(ctx, "a", time.Minute))
+	revoked, err := d.IsRevoked(ctx, 1, time.Now(), "a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = d.IsRevoked(ctx, 1, time.Now(), "b")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Revocations made elsewhere reach the process through the primary
+	shared := NewMemoryDenylist()
+	require.NoError(t, shared.RevokeUser(ctx, 2, time.Now(), time.Minute))
+	d = NewFallbackDenylist(shared, NewMemoryDenylist())
+	revoked, err = d.IsRevoked(ctx, 2, time.Now().Add(-time.Minute), "c")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
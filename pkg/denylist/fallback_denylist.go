@@ -0,0 +1,60 @@
+package denylist
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// FallbackDenylist writes revocations to a shared primary denylist, usually
+// Redis, and to a local one. When the primary is unreachable the revocations
+// made by this process keep being enforced.
+type FallbackDenylist struct {
+	primary  Denylist
+	fallback Denylist
+}
+
+// NewFallbackDenylist creates a denylist falling back to fallback when primary fails
+func NewFallbackDenylist(primary, fallback Denylist) *FallbackDenylist {
+	return &FallbackDenylist{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// RevokeToken revokes the tokens identified by tokenID
+func (d *FallbackDenylist) RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error {
+	if err := d.fallback.RevokeToken(ctx, tokenID, ttl); err != nil {
+		return err
+	}
+	if err := d.primary.RevokeToken(ctx, tokenID, ttl); err != nil {
+		log.Printf("[WARN] Failed to revoke token %s in the primary denylist: %v", tokenID, err)
+	}
+	return nil
+}
+
+// RevokeUser revokes every token of a user issued at or before at
+func (d *FallbackDenylist) RevokeUser(ctx context.Context, userID uint, at time.Time, ttl time.Duration) error {
+	if err := d.fallback.RevokeUser(ctx, userID, at, ttl); err != nil {
+		return err
+	}
+	if err := d.primary.RevokeUser(ctx, userID, at, ttl); err != nil {
+		log.Printf("[WARN] Failed to revoke the tokens of user %d in the primary denylist: %v", userID, err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether a token was revoked in either denylist
+func (d *FallbackDenylist) IsRevoked(ctx context.Context, userID uint, issuedAt time.Time, tokenIDs ...string) (bool, error) {
+	revoked, err := d.fallback.IsRevoked(ctx, userID, issuedAt, tokenIDs...)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	revoked, err = d.primary.IsRevoked(ctx, userID, issuedAt, tokenIDs...)
+	if err != nil {
+		log.Printf("[WARN] Primary denylist unavailable, using local revocations only: %v", err)
+		return false, nil
+	}
+	return revoked, nil
+}
@@ -0,0 +1,90 @@
+package denylist
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// userRevocation is a revocation of every token of a user
+type userRevocation struct {
+	// at is the Unix time of the revocation in milliseconds
+	at        int64
+	expiresAt time.Time
+}
+
+// MemoryDenylist keeps revocations in process memory, only suitable for a single process
+type MemoryDenylist struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time
+	users  map[uint]userRevocation
+}
+
+// NewMemoryDenylist creates an in-memory denylist
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{
+		tokens: make(map[string]time.Time),
+		users:  make(map[uint]userRevocation),
+	}
+}
+
+// RevokeToken revokes the tokens identified by tokenID
+func (d *MemoryDenylist) RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.purge(now)
+	d.tokens[tokenID] = now.Add(ttl)
+	return nil
+}
+
+// RevokeUser revokes every token of a user issued at or before at
+func (d *MemoryDenylist) RevokeUser(ctx context.Context, userID uint, at time.Time, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.purge(now)
+	d.users[userID] = userRevocation{at: at.UnixMilli(), expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// IsRevoked reports whether a token of a user was revoked under any of its IDs
+func (d *MemoryDenylist) IsRevoked(ctx context.Context, userID uint, issuedAt time.Time, tokenIDs ...string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for _, tokenID := range tokenIDs {
+		if expiresAt, ok := d.tokens[tokenID]; ok && tokenID != "" && expiresAt.After(now) {
+			return true, nil
+		}
+	}
+	if revocation, ok := d.users[userID]; ok && revocation.expiresAt.After(now) {
+		return revokedBefore(issuedAt, revocation.at), nil
+	}
+	return false, nil
+}
+
+// purge drops the revocations of tokens that expired by now
+func (d *MemoryDenylist) purge(now time.Time) {
+	for tokenID, expiresAt := range d.tokens {
+		if !expiresAt.After(now) {
+			delete(d.tokens, tokenID)
+		}
+	}
+	for userID, revocation := range d.users {
+		if !revocation.expiresAt.After(now) {
+			delete(d.users, userID)
+		}
+	}
+}
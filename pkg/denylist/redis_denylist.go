@@ -0,0 +1,73 @@
+package denylist
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "jwt:denylist:"
+
+type RedisDenylist struct {
+	client *redis.Client
+}
+
+func NewRedisDenylist(client *redis.Client) *RedisDenylist {
+	return &RedisDenylist{
+		client: client,
+	}
+}
+
+// RevokeToken revokes the tokens identified by tokenID
+func (d *RedisDenylist) RevokeToken(ctx context.Context, tokenID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return d.client.Set(ctx, tokenKey(tokenID), "1", ttl).Err()
+}
+
+// RevokeUser revokes every token of a user issued at or before at
+func (d *RedisDenylist) RevokeUser(ctx context.Context, userID uint, at time.Time, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return d.client.Set(ctx, userKey(userID), at.UnixMilli(), ttl).Err()
+}
+
+// IsRevoked reports whether a token of a user was revoked under any of its IDs
+func (d *RedisDenylist) IsRevoked(ctx context.Context, userID uint, issuedAt time.Time, tokenIDs ...string) (bool, error) {
+	keys := []string{userKey(userID)}
+	for _, tokenID := range tokenIDs {
+		if tokenID != "" {
+			keys = append(keys, tokenKey(tokenID))
+		}
+	}
+
+	values, err := d.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return false, err
+	}
+	for _, value := range values[1:] {
+		if value != nil {
+			return true, nil
+		}
+	}
+	if value, ok := values[0].(string); ok {
+		revokedAt, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		return revokedBefore(issuedAt, revokedAt), nil
+	}
+	return false, nil
+}
+
+func tokenKey(tokenID string) string {
+	return redisKeyPrefix + "token:" + tokenID
+}
+
+func userKey(userID uint) string {
+	return redisKeyPrefix + "user:" + strconv.FormatUint(uint64(userID), 10)
+}
@@ -0,0 +1,92 @@
+package locker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Lock is a row held in the locks table
+type Lock struct {
+	Name      string    `gorm:"primaryKey;type:varchar(191)"`
+	Owner     string    `gorm:"type:varchar(64);not null;default:''"`
+	ExpiresAt time.Time `gorm:"type:timestamp;index;not null"`
+}
+
+// TableName specifies the table name
+func (Lock) TableName() string {
+	return "locks"
+}
+
+// DatabaseLocker stores locks in the locks table, for deployments without Redis
+type DatabaseLocker struct {
+	db *gorm.DB
+}
+
+// NewDatabaseLocker creates a database locker, migrating the locks table
+func NewDatabaseLocker(db *gorm.DB) (*DatabaseLocker, error) {
+	if err := db.AutoMigrate(&Lock{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate locks table: %v", err)
+	}
+	return &DatabaseLocker{db: db}, nil
+}
+
+// TryLock attempts to acquire a lock with a given key and TTL
+// Returns true if lock is acquired, false otherwise
+func (l *DatabaseLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return l.TryLockAs(ctx, key, "", ttl)
+}
+
+// Unlock releases a lock
+func (l *DatabaseLocker) Unlock(ctx context.Context, key string) error {
+	return l.db.WithContext(ctx).Where("name = ?", key).Delete(&Lock{}).Error
+}
+
+// RefreshLock extends the lock TTL
+func (l *DatabaseLocker) RefreshLock(ctx context.Context, key string, ttl time.Duration) error {
+	return l.db.WithContext(ctx).Model(&Lock{}).
+		Where("name = ?", key).
+		Update("expires_at", time.Now().Add(ttl)).Error
+}
+
+// TryLockAs attempts to acquire a lock for the given owner
+func (l *DatabaseLocker) TryLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	db := l.db.WithContext(ctx)
+
+	// An expired lock can be taken over
+	if err := db.Where("name = ? AND expires_at <= ?", key, now).Delete(&Lock{}).Error; err != nil {
+		return false, err
+	}
+
+	// Insert only when the key is free, the primary key keeps this atomic
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&Lock{
+		Name:      key,
+		Owner:     owner,
+		ExpiresAt: now.Add(ttl),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RefreshLockAs extends the lock TTL if owner still holds the lock
+func (l *DatabaseLocker) RefreshLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	result := l.db.WithContext(ctx).Model(&Lock{}).
+		Where("name = ? AND owner = ? AND expires_at > ?", key, owner, now).
+		Update("expires_at", now.Add(ttl))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UnlockAs releases the lock if owner still holds it
+func (l *DatabaseLocker) UnlockAs(ctx context.Context, key, owner string) error {
+	return l.db.WithContext(ctx).Where("name = ? AND owner = ?", key, owner).Delete(&Lock{}).Error
+}
@@ -0,0 +1,33 @@
+package locker
+
+import (
+	"context"
+	"time"
+)
+
+// Locker is a distributed lock with a TTL
+//
+// Locks taken with TryLockAs belong to their owner, only the owner can refresh
+// or release them. A process whose lock expired and was taken over by another
+// one cannot extend or delete the new holder's lock.
+type Locker interface {
+	// TryLock attempts to acquire a lock with a given key and TTL
+	TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Unlock releases a lock
+	Unlock(ctx context.Context, key string) error
+	// RefreshLock extends the lock TTL
+	RefreshLock(ctx context.Context, key string, ttl time.Duration) error
+
+	// TryLockAs attempts to acquire a lock for the given owner
+	TryLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// RefreshLockAs extends the lock TTL, it returns false when owner no longer holds the lock
+	RefreshLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// UnlockAs releases the lock if owner still holds it
+	UnlockAs(ctx context.Context, key, owner string) error
+}
+
+var (
+	_ Locker = (*RedisLocker)(nil)
+	_ Locker = (*DatabaseLocker)(nil)
+	_ Locker = (*MemoryLocker)(nil)
+)
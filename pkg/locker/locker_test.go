@@ -0,0 +1,76 @@
+package locker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func newTestDatabaseLocker(t *testing.T) *DatabaseLocker {
+	dsn := filepath.Join(t.TempDir(), "locks.db") + "?_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	l, err := NewDatabaseLocker(db)
+	require.NoError(t, err)
+	return l
+}
+
+func TestOwnerOnlyTouchesItsOwnLock(t *testing.T) {
+	lockers := map[string]Locker{
+		"memory":   NewMemoryLocker(),
+		"database": newTestDatabaseLocker(t),
+	}
+
+	for name, l := range lockers {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			ttl := 50 * time.Millisecond
+
+			acquired, err := l.TryLockAs(ctx, "task", "first", ttl)
+			require.NoError(t, err)
+			require.True(t, acquired)
+
+			acquired, err = l.TryLockAs(ctx, "task", "second", ttl)
+			require.NoError(t, err)
+			assert.False(t, acquired, "a held lock must not be acquired twice")
+
+			held, err := l.RefreshLockAs(ctx, "task", "first", ttl)
+			require.NoError(t, err)
+			assert.True(t, held)
+
+			// The first owner's lock expires and the second owner takes it
+			time.Sleep(2 * ttl)
+			acquired, err = l.TryLockAs(ctx, "task", "second", time.Minute)
+			require.NoError(t, err)
+			require.True(t, acquired)
+
+			// The former owner can neither extend nor release it
+			held, err = l.RefreshLockAs(ctx, "task", "first", time.Minute)
+			require.NoError(t, err)
+			assert.False(t, held)
+			require.NoError(t, l.UnlockAs(ctx, "task", "first"))
+
+			held, err = l.RefreshLockAs(ctx, "task", "second", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, held)
+
+			require.NoError(t, l.UnlockAs(ctx, "task", "second"))
+			acquired, err = l.TryLock(ctx, "task", ttl)
+			require.NoError(t, err)
+			assert.True(t, acquired, "the owner's unlock must release the lock")
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package locker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryLock is a lock held in memory
+type memoryLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// MemoryLocker keeps locks in process memory, only suitable for a single process
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+}
+
+// NewMemoryLocker creates an in-memory locker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		locks: make(map[string]memoryLock),
+	}
+}
+
+// TryLock attempts to acquire a lock with a given key and TTL
+// Returns true if lock is acquired, false otherwise
+func (l *MemoryLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return l.TryLockAs(ctx, key, "", ttl)
+}
+
+// Unlock releases a lock
+func (l *MemoryLocker) Unlock(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.locks, key)
+	return nil
+}
+
+// RefreshLock extends the lock TTL
+func (l *MemoryLocker) RefreshLock(ctx context.Context, key string, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if lock, ok := l.locks[key]; ok {
+		lock.expiresAt = time.Now().Add(ttl)
+		l.locks[key] = lock
+	}
+	return nil
+}
+
+// TryLockAs attempts to acquire a lock for the given owner
+func (l *MemoryLocker) TryLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if lock, ok := l.locks[key]; ok && lock.expiresAt.After(now) {
+		return false, nil
+	}
+	l.locks[key] = memoryLock{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// RefreshLockAs extends the lock TTL if owner still holds the lock
+func (l *MemoryLocker) RefreshLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	lock, ok := l.locks[key]
+	if !ok || lock.owner != owner || !lock.expiresAt.After(now) {
+		return false, nil
+	}
+	lock.expiresAt = now.Add(ttl)
+	l.locks[key] = lock
+	return true, nil
+}
+
+// UnlockAs releases the lock if owner still holds it
+func (l *MemoryLocker) UnlockAs(ctx context.Context, key, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if lock, ok := l.locks[key]; ok && lock.owner == owner {
+		delete(l.locks, key)
+	}
+	return nil
+}
@@ -7,6 +7,24 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+var (
+	// refreshScript extends the TTL when the lock still holds the owner value
+	refreshScript = redis.NewScript(`
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('pexpire', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+	// unlockScript deletes the lock when it still holds the owner value
+	unlockScript = redis.NewScript(`
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('del', KEYS[1])
+end
+return 0
+`)
+)
+
 type RedisLocker struct {
 	client *redis.Client
 }
@@ -20,12 +38,7 @@ func NewRedisLocker(client *redis.Client) *RedisLocker {
 // TryLock attempts to acquire a lock with a given key and TTL
 // Returns true if lock is acquired, false otherwise
 func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
-	// Use SET NX to ensure atomic lock acquisition
-	ok, err := l.client.SetNX(ctx, key, "1", ttl).Result()
-	if err != nil {
-		return false, err
-	}
-	return ok, nil
+	return l.TryLockAs(ctx, key, "1", ttl)
 }
 
 // Unlock releases a lock
@@ -37,3 +50,27 @@ func (l *RedisLocker) Unlock(ctx context.Context, key string) error {
 func (l *RedisLocker) RefreshLock(ctx context.Context, key string, ttl time.Duration) error {
 	return l.client.Expire(ctx, key, ttl).Err()
 }
+
+// TryLockAs attempts to acquire a lock for the given owner, the owner is stored as the lock value
+func (l *RedisLocker) TryLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	// Use SET NX to ensure atomic lock acquisition
+	ok, err := l.client.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// RefreshLockAs extends the lock TTL if owner still holds the lock
+func (l *RedisLocker) RefreshLockAs(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	refreshed, err := refreshScript.Run(ctx, l.client, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return refreshed == 1, nil
+}
+
+// UnlockAs releases the lock if owner still holds it
+func (l *RedisLocker) UnlockAs(ctx context.Context, key, owner string) error {
+	return unlockScript.Run(ctx, l.client, []string{key}, owner).Err()
+}
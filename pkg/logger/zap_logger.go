@@ -126,6 +126,16 @@ func WithField(ctx context.Context, key string, value interface{}) context.Conte
 	return context.WithValue(ctx, contextKey(key), value)
 }
 
+// WithTraceID adds a trace ID to the logger context
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey, traceID)
+}
+
+// TraceID returns the trace ID carried by the context
+func TraceID(ctx context.Context) string {
+	return getTraceID(ctx)
+}
+
 // getTraceID gets trace ID from context
 func getTraceID(ctx context.Context) string {
 	if ctx == nil {
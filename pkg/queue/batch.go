@@ -0,0 +1,292 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrBatchNotFound 批次不存在
+	ErrBatchNotFound = errors.New("batch not found")
+	// ErrBatchStoreUnavailable 当前驱动未配置批次存储
+	ErrBatchStoreUnavailable = errors.New("batch store unavailable")
+	// ErrEmptyBatch 批次中没有任务
+	ErrEmptyBatch = errors.New("batch has no jobs")
+)
+
+// Batch 任务批次
+//
+// 批次状态持久化在存储中，工作进程重启后仍可继续统计进度。
+// 回调以任务的形式保存，批次有任务最终失败时推送 Catch（仅一次），
+// 全部任务成功时推送 Then，全部任务结束时推送 Finally。
+type Batch struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Name        string     `json:"name" gorm:"type:varchar(255)"`
+	TotalJobs   int        `json:"total_jobs" gorm:"not null"`
+	PendingJobs int        `json:"pending_jobs" gorm:"not null"`
+	FailedJobs  int        `json:"failed_jobs" gorm:"not null"`
+	Then        string     `json:"-" gorm:"type:longtext"`
+	Catch       string     `json:"-" gorm:"type:longtext"`
+	Finally     string     `json:"-" gorm:"type:longtext"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"type:timestamp;index;not null"`
+	FinishedAt  *time.Time `json:"finished_at" gorm:"type:timestamp"`
+}
+
+// TableName 指定表名
+func (Batch) TableName() string {
+	return "job_batches"
+}
+
+// ProcessedJobs 已结束（成功或最终失败）的任务数
+func (b *Batch) ProcessedJobs() int {
+	return b.TotalJobs - b.PendingJobs
+}
+
+// Progress 完成进度百分比
+func (b *Batch) Progress() int {
+	if b.TotalJobs == 0 {
+		return 100
+	}
+	return b.ProcessedJobs() * 100 / b.TotalJobs
+}
+
+// Finished 批次中的任务是否已全部结束
+func (b *Batch) Finished() bool {
+	return b.FinishedAt != nil
+}
+
+// HasFailures 批次中是否有任务最终失败
+func (b *Batch) HasFailures() bool {
+	return b.FailedJobs > 0
+}
+
+// MarshalJSON 输出时附带进度信息
+func (b *Batch) MarshalJSON() ([]byte, error) {
+	type batch Batch
+	return json.Marshal(struct {
+		*batch
+		ProcessedJobs int  `json:"processed_jobs"`
+		Progress      int  `json:"progress"`
+		Finished      bool `json:"finished"`
+	}{
+		batch:         (*batch)(b),
+		ProcessedJobs: b.ProcessedJobs(),
+		Progress:      b.Progress(),
+		Finished:      b.Finished(),
+	})
+}
+
+// BatchStore 批次存储接口
+type BatchStore interface {
+	// Store 保存新批次
+	Store(ctx context.Context, batch *Batch) error
+	// Find 根据ID获取批次
+	Find(ctx context.Context, id string) (*Batch, error)
+	// All 获取所有批次，按创建时间倒序
+	All(ctx context.Context) ([]*Batch, error)
+	// RecordResult 原子地记录一个任务结束，减少待处理数，失败时增加失败数，返回更新后的批次
+	RecordResult(ctx context.Context, id string, failed bool) (*Batch, error)
+	// Delete 删除批次
+	Delete(ctx context.Context, id string) error
+}
+
+// batchStoreProvider 由驱动实现，提供与驱动共用连接的批次存储
+type batchStoreProvider interface {
+	batchStore() BatchStore
+}
+
+// BatchCallback 批次回调任务可实现此接口，在推送前获取批次的最终状态
+type BatchCallback interface {
+	SetBatch(batch *Batch)
+}
+
+// PendingBatch 待推送的批次
+type PendingBatch struct {
+	manager *Manager
+	name    string
+	jobs    []JobInterface
+	then    JobInterface
+	catch   JobInterface
+	finally JobInterface
+}
+
+// Batch 创建待推送的批次
+func (m *Manager) Batch(jobs ...JobInterface) *PendingBatch {
+	return &PendingBatch{manager: m, jobs: jobs}
+}
+
+// Name 设置批次名称
+func (b *PendingBatch) Name(name string) *PendingBatch {
+	b.name = name
+	return b
+}
+
+// Then 全部任务成功后推送的任务
+func (b *PendingBatch) Then(job JobInterface) *PendingBatch {
+	b.then = job
+	return b
+}
+
+// Catch 第一个任务最终失败时推送的任务
+func (b *PendingBatch) Catch(job JobInterface) *PendingBatch {
+	b.catch = job
+	return b
+}
+
+// Finally 全部任务结束后推送的任务，无论成功与否
+func (b *PendingBatch) Finally(job JobInterface) *PendingBatch {
+	b.finally = job
+	return b
+}
+
+// Dispatch 保存批次并推送其中的任务，推送失败时剩余的任务计为失败
+func (b *PendingBatch) Dispatch(ctx context.Context) (*Batch, error) {
+	m := b.manager
+	if m.batches == nil {
+		return nil, ErrBatchStoreUnavailable
+	}
+	if len(b.jobs) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	batch := &Batch{
+		ID:          uuid.New().String(),
+		Name:        b.name,
+		TotalJobs:   len(b.jobs),
+		PendingJobs: len(b.jobs),
+		CreatedAt:   time.Now(),
+	}
+
+	// 回调任务在推送时即完成编码，未注册的任务类型会在此处报错
+	callbacks := []struct {
+		job    JobInterface
+		target *string
+	}{
+		{b.then, &batch.Then},
+		{b.catch, &batch.Catch},
+		{b.finally, &batch.Finally},
+	}
+	for _, callback := range callbacks {
+		if callback.job == nil {
+			continue
+		}
+		stampTraceID(ctx, callback.job)
+		payload, err := encodeJob(callback.job)
+		if err != nil {
+			return nil, err
+		}
+		*callback.target = string(payload)
+	}
+
+	for _, job := range b.jobs {
+		bj, ok := job.(batchable)
+		if !ok {
+			return nil, fmt.Errorf("job %T does not support batching", job)
+		}
+		bj.SetBatchID(batch.ID)
+	}
+
+	if err := m.batches.Store(ctx, batch); err != nil {
+		return nil, err
+	}
+
+	for i, job := range b.jobs {
+		if err := m.Push(ctx, job); err != nil {
+			err = fmt.Errorf("failed to push job %d of batch %s: %w", i+1, batch.ID, err)
+			// 未推送的任务计为失败，批次仍能结束并推送回调
+			for _, unpushed := range b.jobs[i:] {
+				m.recordBatchResult(ctx, unpushed, err)
+			}
+			return batch, err
+		}
+	}
+
+	return batch, nil
+}
+
+// SetBatchStore 设置批次存储
+func (m *Manager) SetBatchStore(store BatchStore) {
+	m.batches = store
+}
+
+// BatchStore 获取批次存储
+func (m *Manager) BatchStore() BatchStore {
+	return m.batches
+}
+
+// FindBatch 根据ID获取批次
+func (m *Manager) FindBatch(ctx context.Context, id string) (*Batch, error) {
+	if m.batches == nil {
+		return nil, ErrBatchStoreUnavailable
+	}
+	return m.batches.Find(ctx, id)
+}
+
+// Batches 获取所有批次
+func (m *Manager) Batches(ctx context.Context) ([]*Batch, error) {
+	if m.batches == nil {
+		return nil, ErrBatchStoreUnavailable
+	}
+	return m.batches.All(ctx)
+}
+
+// recordBatchResult 记录批次中任务的最终结果，并按批次状态推送回调任务
+//
+// 批次统计失败不影响任务本身的处理，仅记录日志。
+func (m *Manager) recordBatchResult(ctx context.Context, job JobInterface, jobErr error) {
+	bj, ok := job.(batchable)
+	if !ok || bj.GetBatchID() == "" {
+		return
+	}
+	if m.batches == nil {
+		log.Printf("[WARN] Job %s belongs to batch %s but no batch store is configured", job.GetID(), bj.GetBatchID())
+		return
+	}
+
+	failed := jobErr != nil
+	batch, err := m.batches.RecordResult(ctx, bj.GetBatchID(), failed)
+	if err != nil {
+		log.Printf("[ERROR] Failed to record result of job %s in batch %s: %v", job.GetID(), bj.GetBatchID(), err)
+		return
+	}
+
+	// 只在第一个任务失败时推送 Catch
+	if failed && batch.FailedJobs == 1 {
+		m.dispatchBatchCallback(ctx, batch, batch.Catch)
+	}
+
+	// 重复记录导致计数小于0时不再推送回调
+	if batch.PendingJobs != 0 {
+		return
+	}
+	if !batch.HasFailures() {
+		m.dispatchBatchCallback(ctx, batch, batch.Then)
+	}
+	m.dispatchBatchCallback(ctx, batch, batch.Finally)
+}
+
+// dispatchBatchCallback 推送批次回调任务
+func (m *Manager) dispatchBatchCallback(ctx context.Context, batch *Batch, payload string) {
+	if payload == "" {
+		return
+	}
+
+	job := decodeJob([]byte(payload))
+	if unknown, ok := job.(*UnknownJob); ok {
+		log.Printf("[ERROR] Failed to dispatch callback of batch %s: %v", batch.ID, unknown.Handle())
+		return
+	}
+	if callback, ok := job.(BatchCallback); ok {
+		callback.SetBatch(batch)
+	}
+
+	if err := m.Push(ctx, job); err != nil {
+		log.Printf("[ERROR] Failed to dispatch callback of batch %s: %v", batch.ID, err)
+	}
+}
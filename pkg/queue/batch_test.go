@@ -0,0 +1,164 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushRecorder 记录推送的任务，推送次数达到 failAfter 后推送失败
+type pushRecorder struct {
+	recordingQueue
+	pushed    []JobInterface
+	failAfter int
+}
+
+func (q *pushRecorder) Push(ctx context.Context, job JobInterface) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.failAfter > 0 && len(q.pushed) >= q.failAfter {
+		return errors.New("push failed")
+	}
+	q.pushed = append(q.pushed, job)
+	return nil
+}
+
+func newTestManager() (*Manager, *pushRecorder) {
+	driver := &pushRecorder{}
+	return &Manager{driver: driver, batches: NewMemoryBatchStore(), failed: NewMemoryFailedJobStore()}, driver
+}
+
+func TestChainDispatchesNextJobOnSuccess(t *testing.T) {
+	m, driver := newTestManager()
+	ctx := context.Background()
+
+	first := &registryTestJob{Message: "first"}
+	require.NoError(t, m.Chain(ctx, first,
+		&registryTestJob{Message: "second"},
+		&registryTestJob{Message: "third"},
+	))
+	require.Len(t, driver.pushed, 1)
+
+	// 第一个任务完成后推送第二个，剩余的任务链随之传递
+	require.NoError(t, m.Complete(ctx, "default", driver.pushed[0]))
+	require.Len(t, driver.pushed, 2)
+	second := driver.pushed[1].(*registryTestJob)
+	assert.Equal(t, "second", second.Message)
+	assert.Len(t, second.Chained, 1)
+
+	require.NoError(t, m.Complete(ctx, "default", second))
+	require.Len(t, driver.pushed, 3)
+	third := driver.pushed[2].(*registryTestJob)
+	assert.Equal(t, "third", third.Message)
+	assert.Empty(t, third.Chained)
+
+	require.NoError(t, m.Complete(ctx, "default", third))
+	assert.Len(t, driver.pushed, 3)
+}
+
+func TestChainStopsOnFailure(t *testing.T) {
+	m, driver := newTestManager()
+	ctx := context.Background()
+
+	require.NoError(t, m.Chain(ctx, &registryTestJob{Message: "first"}, &registryTestJob{Message: "second"}))
+	require.NoError(t, m.Fail(ctx, "default", driver.pushed[0], errors.New("boom")))
+	assert.Len(t, driver.pushed, 1)
+}
+
+func TestChainDeletesJobBeforeDispatchingNext(t *testing.T) {
+	m, driver := newTestManager()
+	ctx := context.Background()
+
+	require.NoError(t, m.Chain(ctx, &registryTestJob{Message: "first"}, &registryTestJob{Message: "second"}))
+	driver.failAfter = 1
+
+	// 当前任务已成功并移除，推送失败的后续任务记入失败任务存储
+	err := m.Complete(ctx, "default", driver.pushed[0])
+	assert.ErrorContains(t, err, "failed to dispatch chained job")
+	assert.Equal(t, 1, driver.deleted)
+
+	failed, err := m.FailedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "registry_test", failed[0].Job)
+	assert.Contains(t, failed[0].Payload, `"message":"second"`)
+}
+
+func TestBatchDispatchFailureFinishesBatch(t *testing.T) {
+	m, driver := newTestManager()
+	ctx := context.Background()
+	driver.failAfter = 1
+
+	batch, err := m.Batch(&registryTestJob{}, &registryTestJob{}, &registryTestJob{}).
+		Catch(&registryTestJob{Message: "catch"}).
+		Finally(&registryTestJob{Message: "finally"}).
+		Dispatch(ctx)
+	require.Error(t, err)
+	require.NotNil(t, batch)
+
+	// 未推送的两个任务计为失败，已推送的任务结束后批次完成
+	stored, err := m.FindBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.PendingJobs)
+	assert.Equal(t, 2, stored.FailedJobs)
+
+	driver.failAfter = 0
+	require.NoError(t, m.Complete(ctx, "default", driver.pushed[0]))
+	stored, err = m.FindBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Finished())
+	assert.Equal(t, "finally", driver.pushed[len(driver.pushed)-1].(*registryTestJob).Message)
+}
+
+func TestBatchCallbacks(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		callbacks []string
+	}{
+		{"all succeed", 0, []string{"then", "finally"}},
+		{"one fails", 1, []string{"catch", "finally"}},
+		{"all fail", 3, []string{"catch", "finally"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, driver := newTestManager()
+			ctx := context.Background()
+
+			batch, err := m.Batch(&registryTestJob{}, &registryTestJob{}, &registryTestJob{}).
+				Name("import").
+				Then(&registryTestJob{Message: "then"}).
+				Catch(&registryTestJob{Message: "catch"}).
+				Finally(&registryTestJob{Message: "finally"}).
+				Dispatch(ctx)
+			require.NoError(t, err)
+			require.Len(t, driver.pushed, 3)
+
+			jobs := append([]JobInterface(nil), driver.pushed...)
+			for i, job := range jobs {
+				assert.Equal(t, batch.ID, job.(*registryTestJob).BatchID)
+				if i < tt.failures {
+					require.NoError(t, m.Fail(ctx, "default", job, errors.New("boom")))
+				} else {
+					require.NoError(t, m.Complete(ctx, "default", job))
+				}
+			}
+
+			var callbacks []string
+			for _, job := range driver.pushed[3:] {
+				callbacks = append(callbacks, job.(*registryTestJob).Message)
+			}
+			assert.Equal(t, tt.callbacks, callbacks)
+
+			stored, err := m.FindBatch(ctx, batch.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 0, stored.PendingJobs)
+			assert.Equal(t, tt.failures, stored.FailedJobs)
+			assert.Equal(t, 100, stored.Progress())
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrEmptyChain 任务链中没有任务
+var ErrEmptyChain = errors.New("job chain is empty")
+
+// Chain 推送任务链，前一个任务成功后才会推送下一个任务
+//
+// 任一任务最终失败时任务链终止，后续任务不会执行。
+func (m *Manager) Chain(ctx context.Context, jobs ...JobInterface) error {
+	if len(jobs) == 0 {
+		return ErrEmptyChain
+	}
+
+	// 后续任务在推送时即完成编码，未注册的任务类型会在此处报错
+	chain := make([]json.RawMessage, 0, len(jobs)-1)
+	for _, job := range jobs[1:] {
+		stampTraceID(ctx, job)
+		payload, err := encodeJob(job)
+		if err != nil {
+			return err
+		}
+		chain = append(chain, payload)
+	}
+
+	first := jobs[0]
+	c, ok := first.(chainable)
+	if !ok {
+		return fmt.Errorf("job %T does not support chaining", first)
+	}
+	c.setChain(chain)
+
+	return m.Push(ctx, first)
+}
+
+// dispatchNextInChain 推送任务链中的下一个任务，剩余的任务链随之传递
+//
+// 推送失败时下一个任务记入失败任务存储，重试该失败任务即可继续任务链。
+func (m *Manager) dispatchNextInChain(ctx context.Context, queue string, job JobInterface) error {
+	c, ok := job.(chainable)
+	if !ok || len(c.chain()) == 0 {
+		return nil
+	}
+	chain := c.chain()
+
+	next := decodeJob(chain[0])
+	var err error
+	if unknown, ok := next.(*UnknownJob); ok {
+		err = unknown.Handle()
+	} else {
+		if nc, ok := next.(chainable); ok {
+			nc.setChain(chain[1:])
+		}
+		// 沿用当前任务的追踪ID
+		err = m.Push(jobContext(ctx, job), next)
+	}
+	if err == nil {
+		return nil
+	}
+
+	err = fmt.Errorf("failed to dispatch chained job: %w", err)
+	m.recordChainFailure(ctx, queue, next, chain[0], err)
+	return err
+}
+
+// recordChainFailure 将推送失败的后续任务记入失败任务存储
+func (m *Manager) recordChainFailure(ctx context.Context, queue string, next JobInterface, raw []byte, dispatchErr error) {
+	name, _ := JobName(next)
+	log.Printf("[ERROR] Job chain stopped before %s on queue %s: %v", name, queue, dispatchErr)
+	if m.failed == nil {
+		return
+	}
+
+	// 未注册的任务无法编码，保留原始数据
+	payload, err := encodeJob(next)
+	if err != nil {
+		payload = raw
+	}
+	if nextQueue := next.GetQueue(); nextQueue != "" {
+		queue = nextQueue
+	}
+
+	if err := m.failed.Log(ctx, &FailedJob{
+		ID:        uuid.New().String(),
+		Queue:     queue,
+		Job:       name,
+		Payload:   string(payload),
+		Exception: dispatchErr.Error(),
+		FailedAt:  time.Now(),
+	}); err != nil {
+		log.Printf("[ERROR] Failed to log chained job %s: %v", name, err)
+	}
+}
+
+// Complete 任务成功完成，将任务从队列中移除并释放唯一锁、更新批次进度，再推送任务链中的下一个任务
+//
+// 先移除已完成的任务，推送后续任务失败时不会重复执行当前任务。
+func (m *Manager) Complete(ctx context.Context, queue string, job JobInterface) error {
+	if err := m.driver.Delete(ctx, queue, job); err != nil {
+		return err
+	}
+	m.releaseUniqueLock(ctx, job)
+	m.recordBatchResult(ctx, job, nil)
+
+	return m.dispatchNextInChain(ctx, queue, job)
+}
@@ -0,0 +1,53 @@
+package queue
+
+import (
+	"context"
+
+	"app/pkg/logger"
+)
+
+// ContextJob 支持上下文的任务
+//
+// Worker 在任务超时或停止时取消 ctx，任务应及时检查 ctx.Done() 并返回，
+// 否则超时后无法安全地重新执行。
+type ContextJob interface {
+	JobInterface
+	// HandleContext 处理任务
+	HandleContext(ctx context.Context) error
+}
+
+// traceable 可携带追踪ID的任务，嵌入 BaseJob 的任务均已实现
+type traceable interface {
+	GetTraceID() string
+	SetTraceID(traceID string)
+}
+
+// Run 执行任务
+//
+// 实现了 ContextJob 的任务调用 HandleContext，
+// 其余任务兼容旧的 Handle() 签名，此时任务无法感知取消。
+func Run(ctx context.Context, job JobInterface) error {
+	if j, ok := job.(ContextJob); ok {
+		return j.HandleContext(ctx)
+	}
+	return job.Handle()
+}
+
+// stampTraceID 将当前请求的追踪ID写入任务
+func stampTraceID(ctx context.Context, job JobInterface) {
+	t, ok := job.(traceable)
+	if !ok || t.GetTraceID() != "" {
+		return
+	}
+	if traceID := logger.TraceID(ctx); traceID != "" {
+		t.SetTraceID(traceID)
+	}
+}
+
+// jobContext 为任务执行创建上下文，恢复推送时的追踪ID
+func jobContext(ctx context.Context, job JobInterface) context.Context {
+	if t, ok := job.(traceable); ok && t.GetTraceID() != "" {
+		return logger.WithTraceID(ctx, t.GetTraceID())
+	}
+	return ctx
+}
@@ -0,0 +1,103 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DatabaseBatchStore 数据库批次存储
+type DatabaseBatchStore struct {
+	db *gorm.DB
+}
+
+// NewDatabaseBatchStore 创建数据库批次存储
+func NewDatabaseBatchStore(db *gorm.DB) (*DatabaseBatchStore, error) {
+	// 自动迁移表结构
+	if err := db.AutoMigrate(&Batch{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate job_batches table: %v", err)
+	}
+
+	return &DatabaseBatchStore{db: db}, nil
+}
+
+// Store 保存新批次
+func (s *DatabaseBatchStore) Store(ctx context.Context, batch *Batch) error {
+	return s.db.WithContext(ctx).Create(batch).Error
+}
+
+// Find 根据ID获取批次
+func (s *DatabaseBatchStore) Find(ctx context.Context, id string) (*Batch, error) {
+	var batch Batch
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&batch).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// All 获取所有批次，按创建时间倒序
+func (s *DatabaseBatchStore) All(ctx context.Context) ([]*Batch, error) {
+	var batches []*Batch
+	err := s.db.WithContext(ctx).Order("created_at DESC").Find(&batches).Error
+	return batches, err
+}
+
+// RecordResult 原子地记录一个任务结束
+//
+// 计数在同一事务中更新并读取，UPDATE 持有的行锁保证并发结束的任务各自看到一致的结果。
+func (s *DatabaseBatchStore) RecordResult(ctx context.Context, id string, failed bool) (*Batch, error) {
+	var batch Batch
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"pending_jobs": gorm.Expr("pending_jobs - 1"),
+		}
+		if failed {
+			updates["failed_jobs"] = gorm.Expr("failed_jobs + 1")
+		}
+
+		result := tx.Model(&Batch{}).Where("id = ?", id).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrBatchNotFound
+		}
+
+		if err := tx.Where("id = ?", id).First(&batch).Error; err != nil {
+			return err
+		}
+
+		// 全部任务结束时记录结束时间
+		if batch.PendingJobs <= 0 && batch.FinishedAt == nil {
+			now := time.Now()
+			batch.FinishedAt = &now
+			return tx.Model(&Batch{}).Where("id = ?", id).Update("finished_at", now).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+// Delete 删除批次
+func (s *DatabaseBatchStore) Delete(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Where("id = ?", id).Delete(&Batch{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBatchNotFound
+	}
+	return nil
+}
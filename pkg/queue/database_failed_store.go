@@ -0,0 +1,66 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// DatabaseFailedJobStore 数据库失败任务存储
+type DatabaseFailedJobStore struct {
+	db *gorm.DB
+}
+
+// NewDatabaseFailedJobStore 创建数据库失败任务存储
+func NewDatabaseFailedJobStore(db *gorm.DB) (*DatabaseFailedJobStore, error) {
+	// 自动迁移表结构
+	if err := db.AutoMigrate(&FailedJob{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate failed_jobs table: %v", err)
+	}
+
+	return &DatabaseFailedJobStore{db: db}, nil
+}
+
+// Log 记录失败任务
+func (s *DatabaseFailedJobStore) Log(ctx context.Context, job *FailedJob) error {
+	return s.db.WithContext(ctx).Create(job).Error
+}
+
+// All 获取所有失败任务，按失败时间倒序
+func (s *DatabaseFailedJobStore) All(ctx context.Context) ([]*FailedJob, error) {
+	var jobs []*FailedJob
+	err := s.db.WithContext(ctx).Order("failed_at DESC").Find(&jobs).Error
+	return jobs, err
+}
+
+// Find 根据ID获取失败任务
+func (s *DatabaseFailedJobStore) Find(ctx context.Context, id string) (*FailedJob, error) {
+	var job FailedJob
+	err := s.db.WithContext(ctx).Where("id = ?", id).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFailedJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Forget 删除失败任务
+func (s *DatabaseFailedJobStore) Forget(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Where("id = ?", id).Delete(&FailedJob{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFailedJobNotFound
+	}
+	return nil
+}
+
+// Flush 清空所有失败任务
+func (s *DatabaseFailedJobStore) Flush(ctx context.Context) error {
+	return s.db.WithContext(ctx).Where("1 = 1").Delete(&FailedJob{}).Error
+}
@@ -0,0 +1,163 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QueueMetric 队列每分钟的任务处理统计
+type QueueMetric struct {
+	ID        uint      `gorm:"primaryKey"`
+	Queue     string    `gorm:"type:varchar(100);uniqueIndex:idx_queue_metrics_queue_minute;not null"`
+	Minute    time.Time `gorm:"type:timestamp;uniqueIndex:idx_queue_metrics_queue_minute;index;not null"`
+	Processed int64     `gorm:"default:0;not null"`
+	Failed    int64     `gorm:"default:0;not null"`
+	RuntimeUs int64     `gorm:"default:0;not null"`
+}
+
+// TableName 指定表名
+func (QueueMetric) TableName() string {
+	return "queue_metrics"
+}
+
+// PausedQueue 已暂停的队列
+type PausedQueue struct {
+	Queue    string    `gorm:"primaryKey;type:varchar(100)"`
+	PausedAt time.Time `gorm:"type:timestamp;not null"`
+}
+
+// TableName 指定表名
+func (PausedQueue) TableName() string {
+	return "queue_pauses"
+}
+
+// DatabaseMonitorStore 数据库监控存储
+type DatabaseMonitorStore struct {
+	db *gorm.DB
+	// pruneMu 保护上次清理过期统计的时间
+	pruneMu    sync.Mutex
+	lastPruned time.Time
+}
+
+// NewDatabaseMonitorStore 创建数据库监控存储
+func NewDatabaseMonitorStore(db *gorm.DB) (*DatabaseMonitorStore, error) {
+	// 自动迁移表结构
+	if err := db.AutoMigrate(&QueueMetric{}, &WorkerHeartbeat{}, &PausedQueue{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate queue monitor tables: %v", err)
+	}
+
+	return &DatabaseMonitorStore{db: db}, nil
+}
+
+// RecordJob 记录一次任务执行，按分钟累加
+func (s *DatabaseMonitorStore) RecordJob(ctx context.Context, queue string, runtime time.Duration, failed bool) error {
+	metric := &QueueMetric{
+		Queue:     queue,
+		Minute:    time.Now().Truncate(time.Minute),
+		Processed: 1,
+		RuntimeUs: runtime.Microseconds(),
+	}
+	if failed {
+		metric.Failed = 1
+	}
+
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "queue"}, {Name: "minute"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"processed":  gorm.Expr("processed + ?", metric.Processed),
+			"failed":     gorm.Expr("failed + ?", metric.Failed),
+			"runtime_us": gorm.Expr("runtime_us + ?", metric.RuntimeUs),
+		}),
+	}).Create(metric).Error
+	if err != nil {
+		return err
+	}
+
+	return s.prune(ctx)
+}
+
+// prune 每小时最多清理一次过期的统计
+func (s *DatabaseMonitorStore) prune(ctx context.Context) error {
+	s.pruneMu.Lock()
+	if time.Since(s.lastPruned) < time.Hour {
+		s.pruneMu.Unlock()
+		return nil
+	}
+	s.lastPruned = time.Now()
+	s.pruneMu.Unlock()
+
+	return s.db.WithContext(ctx).
+		Where("minute < ?", time.Now().Add(-MetricsRetention)).
+		Delete(&QueueMetric{}).Error
+}
+
+// Metrics 获取队列自 since 以来的任务处理统计
+func (s *DatabaseMonitorStore) Metrics(ctx context.Context, queue string, since time.Time) (JobMetrics, error) {
+	var row struct {
+		Processed int64
+		Failed    int64
+		RuntimeUs int64
+	}
+	err := s.db.WithContext(ctx).Model(&QueueMetric{}).
+		Select("COALESCE(SUM(processed), 0) AS processed, COALESCE(SUM(failed), 0) AS failed, COALESCE(SUM(runtime_us), 0) AS runtime_us").
+		Where("queue = ? AND minute >= ?", queue, since.Truncate(time.Minute)).
+		Scan(&row).Error
+	if err != nil {
+		return JobMetrics{}, err
+	}
+
+	return JobMetrics{
+		Processed: row.Processed,
+		Failed:    row.Failed,
+		Runtime:   time.Duration(row.RuntimeUs) * time.Microsecond,
+	}, nil
+}
+
+// Heartbeat 上报工作进程心跳
+func (s *DatabaseMonitorStore) Heartbeat(ctx context.Context, heartbeat *WorkerHeartbeat) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(heartbeat).Error
+}
+
+// Heartbeats 获取未过期的工作进程心跳，并清理已过期的心跳
+func (s *DatabaseMonitorStore) Heartbeats(ctx context.Context) ([]*WorkerHeartbeat, error) {
+	expired := time.Now().Add(-HeartbeatTTL)
+	db := s.db.WithContext(ctx)
+
+	if err := db.Where("last_seen < ?", expired).Delete(&WorkerHeartbeat{}).Error; err != nil {
+		return nil, err
+	}
+
+	var heartbeats []*WorkerHeartbeat
+	err := db.Order("started_at ASC").Find(&heartbeats).Error
+	return heartbeats, err
+}
+
+// RemoveHeartbeat 移除工作进程心跳
+func (s *DatabaseMonitorStore) RemoveHeartbeat(ctx context.Context, id string) error {
+	return s.db.WithContext(ctx).Where("id = ?", id).Delete(&WorkerHeartbeat{}).Error
+}
+
+// Pause 暂停队列
+func (s *DatabaseMonitorStore) Pause(ctx context.Context, queue string) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&PausedQueue{
+		Queue:    queue,
+		PausedAt: time.Now(),
+	}).Error
+}
+
+// Resume 恢复队列
+func (s *DatabaseMonitorStore) Resume(ctx context.Context, queue string) error {
+	return s.db.WithContext(ctx).Where("queue = ?", queue).Delete(&PausedQueue{}).Error
+}
+
+// PausedQueues 获取已暂停的队列
+func (s *DatabaseMonitorStore) PausedQueues(ctx context.Context) ([]string, error) {
+	var queues []string
+	err := s.db.WithContext(ctx).Model(&PausedQueue{}).Order("queue ASC").Pluck("queue", &queues).Error
+	return queues, err
+}
@@ -3,16 +3,53 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"app/pkg/locker"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+func init() {
+	// database 与 mysql 共用同一个驱动实现
+	Register("database", func(config Config) (QueueInterface, error) {
+		return NewDatabaseQueue(config)
+	})
+	Register("mysql", func(config Config) (QueueInterface, error) {
+		return NewDatabaseQueue(config)
+	})
+}
+
+// reserveCandidates 不支持 SKIP LOCKED 时每次尝试抢占的候选任务数
+const reserveCandidates = 5
+
 // DatabaseQueue 数据库队列驱动
+//
+// 支持 SKIP LOCKED 的数据库（MySQL 8.0+、MariaDB 10.6+、PostgreSQL）使用
+// SELECT ... FOR UPDATE SKIP LOCKED 保留任务；其余数据库通过带条件的 UPDATE
+// 抢占任务，两种方式都保证同一任务只会交给一个工作进程。
+// 保留时间超过 retry_after 的任务视为工作进程已崩溃，会被重新取出并增加重试次数。
 type DatabaseQueue struct {
-	db     *gorm.DB
-	config Config
+	db      *gorm.DB
+	config  Config
+	failed  *DatabaseFailedJobStore
+	batches *DatabaseBatchStore
+	lock    *locker.DatabaseLocker
+	monitor *DatabaseMonitorStore
+	// throttles 限流计数存储
+	throttles *DatabaseThrottleStore
+	// retryAfter 任务被取出后的保留时间
+	retryAfter time.Duration
+
+	skipLockedOnce sync.Once
+	skipLocked     bool
 }
 
 // QueueJob 数据库队列任务表结构
@@ -51,15 +88,78 @@ func NewDatabaseQueue(config Config) (*DatabaseQueue, error) {
 		return nil, fmt.Errorf("failed to migrate queue_jobs table: %v", err)
 	}
 
-	return &DatabaseQueue{
-		db:     db,
-		config: config,
-	}, nil
+	failed, err := NewDatabaseFailedJobStore(db)
+	if err != nil {
+		return nil, err
+	}
+
+	batches, err := NewDatabaseBatchStore(db)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := locker.NewDatabaseLocker(db)
+	if err != nil {
+		return nil, err
+	}
+
+	monitor, err := NewDatabaseMonitorStore(db)
+	if err != nil {
+		return nil, err
+	}
+
+	throttles, err := NewDatabaseThrottleStore(db)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := &DatabaseQueue{
+		db:         db,
+		config:     config,
+		failed:     failed,
+		batches:    batches,
+		lock:       lock,
+		monitor:    monitor,
+		throttles:  throttles,
+		retryAfter: visibilityTimeout(config),
+	}
+
+	// 允许通过配置强制开启或关闭 SKIP LOCKED
+	if v, ok := config.Options["skip_locked"].(bool); ok {
+		queue.skipLockedOnce.Do(func() { queue.skipLocked = v })
+	}
+
+	return queue, nil
+}
+
+// failedJobStore 返回共用连接的失败任务存储
+func (q *DatabaseQueue) failedJobStore() FailedJobStore {
+	return q.failed
+}
+
+// batchStore 返回共用连接的批次存储
+func (q *DatabaseQueue) batchStore() BatchStore {
+	return q.batches
+}
+
+// locker 返回共用连接的锁
+func (q *DatabaseQueue) locker() locker.Locker {
+	return q.lock
+}
+
+// monitorStore 返回共用连接的监控存储
+func (q *DatabaseQueue) monitorStore() MonitorStore {
+	return q.monitor
+}
+
+// throttleStore 返回共用连接的限流存储
+func (q *DatabaseQueue) throttleStore() ThrottleStore {
+	return q.throttles
 }
 
 // Push 推送任务到队列
 func (q *DatabaseQueue) Push(ctx context.Context, job JobInterface) error {
-	payload, err := json.Marshal(job)
+	payload, err := encodeJob(job)
 	if err != nil {
 		return err
 	}
@@ -170,7 +270,7 @@ func (q *DatabaseQueue) PushRaw(ctx context.Context, queue string, payload []byt
 
 // Later 延迟推送任务
 func (q *DatabaseQueue) Later(ctx context.Context, job JobInterface, delay time.Duration) error {
-	payload, err := json.Marshal(job)
+	payload, err := encodeJob(job)
 	if err != nil {
 		return err
 	}
@@ -221,58 +321,162 @@ func (q *DatabaseQueue) Pop(ctx context.Context, queue string) (JobInterface, er
 		}
 	}
 
-	// 开启事务
-	tx := q.db.WithContext(ctx).Begin()
-	if tx.Error != nil {
-		return nil, tx.Error
-	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
-	// 查找并锁定一个可用的任务
-	var queueJob QueueJob
-	err := tx.Where("queue = ? AND reserved_at IS NULL AND available_at <= ?", queue, time.Now()).
-		Order("available_at ASC").
-		First(&queueJob).Error
-
-	if err == gorm.ErrRecordNotFound {
-		tx.Rollback()
-		return nil, ErrQueueEmpty
+	var (
+		queueJob *QueueJob
+		err      error
+	)
+	if q.supportsSkipLocked(ctx) {
+		queueJob, err = q.reserveLocked(ctx, queue)
+	} else {
+		queueJob, err = q.reserveConditional(ctx, queue)
 	}
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	// 标记任务为已保留
-	now := time.Now()
+	// 还原为具体的任务类型
+	job := decodeJob(queueJob.Payload)
+
+	// 以数据库记录为准设置任务ID、重试次数和保留时间
+	job.SetID(fmt.Sprintf("%d", queueJob.ID))
+	job.SetAttempts(queueJob.Attempts)
+	job.SetReservedAt(queueJob.ReservedAt)
+
+	return job, nil
+}
+
+// availableScope 可取出的任务：未保留且已到执行时间，或保留已过期
+func (q *DatabaseQueue) availableScope(queue string, now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("queue = ?", queue).
+			Where(db.Session(&gorm.Session{NewDB: true}).
+				Where("reserved_at IS NULL AND available_at <= ?", now).
+				Or("reserved_at <= ?", now.Add(-q.retryAfter)))
+	}
+}
+
+// reservation 保留任务时需要更新的字段，重新取出保留过期的任务时增加重试次数
+//
+// 时间戳列可能只保存到秒，保留时间按秒截断，删除和释放时才能按它匹配保留的行。
+func reservation(queueJob *QueueJob, now time.Time) map[string]interface{} {
+	now = now.Truncate(time.Second)
+	if queueJob.ReservedAt != nil {
+		queueJob.Attempts++
+	}
 	queueJob.ReservedAt = &now
 	queueJob.UpdatedAt = now
 
-	if err := tx.Save(&queueJob).Error; err != nil {
-		tx.Rollback()
-		return nil, err
+	return map[string]interface{}{
+		"attempts":    queueJob.Attempts,
+		"reserved_at": now,
+		"updated_at":  now,
 	}
+}
+
+// reserveLocked 使用 SELECT ... FOR UPDATE SKIP LOCKED 保留任务
+func (q *DatabaseQueue) reserveLocked(ctx context.Context, queue string) (*QueueJob, error) {
+	var queueJob QueueJob
 
-	// 提交事务
-	if err := tx.Commit().Error; err != nil {
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// 跳过其他事务已锁定的行，多个工作进程不会取到同一个任务
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Scopes(q.availableScope(queue, now)).
+			Order("available_at ASC").
+			Order("id ASC").
+			First(&queueJob).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrQueueEmpty
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&QueueJob{}).
+			Where("id = ?", queueJob.ID).
+			Updates(reservation(&queueJob, now)).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// 转换为Job接口
-	var job BaseJob
-	if err := json.Unmarshal(queueJob.Payload, &job); err != nil {
+	return &queueJob, nil
+}
+
+// reserveConditional 不支持 SKIP LOCKED 时，通过带条件的 UPDATE 抢占任务
+//
+// 只有保留状态与读取时一致的行才会被更新，更新行数为0说明已被其他工作进程抢占。
+func (q *DatabaseQueue) reserveConditional(ctx context.Context, queue string) (*QueueJob, error) {
+	now := time.Now()
+
+	var candidates []QueueJob
+	err := q.db.WithContext(ctx).
+		Scopes(q.availableScope(queue, now)).
+		Order("available_at ASC").
+		Order("id ASC").
+		Limit(reserveCandidates).
+		Find(&candidates).Error
+	if err != nil {
 		return nil, err
 	}
 
-	// 设置任务ID和保留时间
-	job.SetID(fmt.Sprintf("%d", queueJob.ID))
-	job.SetReservedAt(queueJob.ReservedAt)
+	for i := range candidates {
+		queueJob := &candidates[i]
+
+		update := q.db.WithContext(ctx).Model(&QueueJob{}).Where("id = ?", queueJob.ID)
+		if queueJob.ReservedAt == nil {
+			update = update.Where("reserved_at IS NULL")
+		} else {
+			update = update.Where("reserved_at = ?", *queueJob.ReservedAt)
+		}
+
+		result := update.Updates(reservation(queueJob, now))
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return queueJob, nil
+		}
+	}
+
+	return nil, ErrQueueEmpty
+}
+
+// mysqlVersionPattern 解析 MySQL/MariaDB 版本号
+var mysqlVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)`)
+
+// supportsSkipLocked 检测数据库是否支持 SKIP LOCKED，结果会被缓存
+func (q *DatabaseQueue) supportsSkipLocked(ctx context.Context) bool {
+	q.skipLockedOnce.Do(func() {
+		switch q.db.Dialector.Name() {
+		case "postgres":
+			q.skipLocked = true
+		case "mysql":
+			var version string
+			if err := q.db.WithContext(ctx).Raw("SELECT VERSION()").Scan(&version).Error; err != nil {
+				return
+			}
+			q.skipLocked = mysqlSupportsSkipLocked(version)
+		}
+	})
+	return q.skipLocked
+}
 
-	return &job, nil
+// mysqlSupportsSkipLocked MySQL 8.0.1 及 MariaDB 10.6 起支持 SKIP LOCKED
+func mysqlSupportsSkipLocked(version string) bool {
+	matches := mysqlVersionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return false
+	}
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	patch, _ := strconv.Atoi(matches[3])
+
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return major > 10 || (major == 10 && minor >= 6)
+	}
+	return major > 8 || (major == 8 && (minor > 0 || patch >= 1))
 }
 
 // Size 获取队列大小
@@ -293,6 +497,37 @@ func (q *DatabaseQueue) Size(ctx context.Context, queue string) (int64, error) {
 	return count, err
 }
 
+// Counts 按状态统计队列中的任务，保留过期的任务计为待处理
+func (q *DatabaseQueue) Counts(ctx context.Context, queue string) (QueueCounts, error) {
+	if queue == "" {
+		if queueOpt, ok := q.config.Options["queue"].(string); ok {
+			queue = queueOpt
+		} else {
+			return QueueCounts{}, fmt.Errorf("queue name not found in options")
+		}
+	}
+
+	// DELAYED 是 MySQL 保留字，别名统一加后缀
+	var row struct {
+		PendingCount  int64
+		DelayedCount  int64
+		ReservedCount int64
+	}
+	now := time.Now()
+	expired := now.Add(-q.retryAfter)
+	err := q.db.WithContext(ctx).Model(&QueueJob{}).
+		Select(`SUM(CASE WHEN (reserved_at IS NULL AND available_at <= ?) OR reserved_at <= ? THEN 1 ELSE 0 END) AS pending_count,
+			SUM(CASE WHEN reserved_at IS NULL AND available_at > ? THEN 1 ELSE 0 END) AS delayed_count,
+			SUM(CASE WHEN reserved_at > ? THEN 1 ELSE 0 END) AS reserved_count`, now, expired, now, expired).
+		Where("queue = ?", queue).
+		Scan(&row).Error
+	if err != nil {
+		return QueueCounts{}, err
+	}
+
+	return QueueCounts{Pending: row.PendingCount, Delayed: row.DelayedCount, Reserved: row.ReservedCount}, nil
+}
+
 // Delete 删除任务
 func (q *DatabaseQueue) Delete(ctx context.Context, queue string, job JobInterface) error {
 	if queue == "" {
@@ -303,7 +538,25 @@ func (q *DatabaseQueue) Delete(ctx context.Context, queue string, job JobInterfa
 		}
 	}
 
-	return q.db.WithContext(ctx).Where("queue = ? AND id = ?", queue, job.GetID()).Delete(&QueueJob{}).Error
+	result := q.db.WithContext(ctx).Scopes(reservedBy(queue, job)).Delete(&QueueJob{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReservationLost
+	}
+	return nil
+}
+
+// reservedBy 限定为任务本次取出时保留的行，保留过期后被其他工作进程重新取出的行不会匹配
+func reservedBy(queue string, job JobInterface) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("queue = ? AND id = ?", queue, job.GetID())
+		if reservedAt := job.GetReservedAt(); reservedAt != nil {
+			return db.Where("reserved_at = ?", *reservedAt)
+		}
+		return db.Where("reserved_at IS NULL")
+	}
 }
 
 // Release 释放任务回队列
@@ -319,11 +572,6 @@ func (q *DatabaseQueue) Release(ctx context.Context, queue string, job JobInterf
 	// 增加重试次数
 	attempts := job.GetAttempts() + 1
 
-	// 如果超过最大重试次数，直接删除
-	if attempts >= job.GetMaxAttempts() {
-		return q.Delete(ctx, queue, job)
-	}
-
 	// 计算新的可用时间
 	availableAt := time.Now()
 	if delay > 0 {
@@ -344,14 +592,21 @@ func (q *DatabaseQueue) Release(ctx context.Context, queue string, job JobInterf
 	}
 
 	// 更新任务
-	return q.db.WithContext(ctx).Model(&QueueJob{}).
-		Where("queue = ? AND id = ?", queue, job.GetID()).
+	result := q.db.WithContext(ctx).Model(&QueueJob{}).
+		Scopes(reservedBy(queue, job)).
 		Updates(map[string]interface{}{
 			"attempts":     attempts,
 			"reserved_at":  nil,
 			"available_at": availableAt,
 			"updated_at":   time.Now(),
-		}).Error
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReservationLost
+	}
+	return nil
 }
 
 // Clear 清空队列
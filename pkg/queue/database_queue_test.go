@@ -0,0 +1,227 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestMySQLSupportsSkipLocked(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"5.7.44-log", false},
+		{"8.0.0", false},
+		{"8.0.1", true},
+		{"8.4.2", true},
+		{"10.5.22-MariaDB", false},
+		{"10.6.16-MariaDB-1:10.6.16+maria~ubu2004", true},
+		{"11.2.2-MariaDB", true},
+		{"unknown", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, mysqlSupportsSkipLocked(tt.version))
+		})
+	}
+}
+
+// newTestDatabaseQueue 创建使用临时 SQLite 数据库的队列，skipLocked 决定保留任务的方式
+func newTestDatabaseQueue(t *testing.T, skipLocked bool, retryAfter time.Duration) *Manager {
+	// 立即加写锁的事务避免并发升级锁时的死锁，忙时等待而不是报错
+	dsn := filepath.Join(t.TempDir(), "queue.db") + "?_busy_timeout=5000&_txlock=immediate&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	m, err := NewManager(Config{Driver: "database", Options: map[string]interface{}{
+		"db":          db,
+		"queue":       "default",
+		"retry_after": retryAfter,
+		"skip_locked": skipLocked,
+	}})
+	require.NoError(t, err)
+	return m
+}
+
+func TestDatabaseQueueConcurrentPopReservesOnce(t *testing.T) {
+	for _, skipLocked := range []bool{true, false} {
+		t.Run(fmt.Sprintf("skip_locked=%v", skipLocked), func(t *testing.T) {
+			const workers = 8
+			m := newTestDatabaseQueue(t, skipLocked, time.Minute)
+			ctx := context.Background()
+			require.NoError(t, m.Push(ctx, &registryTestJob{Message: "once"}))
+
+			if !skipLocked {
+				// 所有工作进程读到同一候选任务后才开始抢占
+				var (
+					arrived sync.WaitGroup
+					updates atomic.Int32
+				)
+				arrived.Add(workers)
+				db := m.GetDriver().(*DatabaseQueue).db
+				require.NoError(t, db.Callback().Update().Before("gorm:begin_transaction").Register("test:barrier", func(*gorm.DB) {
+					if updates.Add(1) <= workers {
+						arrived.Done()
+						arrived.Wait()
+					}
+				}))
+			}
+
+			var (
+				wg       sync.WaitGroup
+				reserved atomic.Int32
+			)
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					job, err := m.Pop(ctx, "default")
+					if err == ErrQueueEmpty {
+						return
+					}
+					if assert.NoError(t, err) {
+						assert.Equal(t, "once", job.(*registryTestJob).Message)
+						reserved.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+
+			// 同一行只会交给一个工作进程
+			assert.Equal(t, int32(1), reserved.Load())
+		})
+	}
+}
+
+func TestDatabaseQueueReclaimsExpiredReservation(t *testing.T) {
+	for _, skipLocked := range []bool{true, false} {
+		t.Run(fmt.Sprintf("skip_locked=%v", skipLocked), func(t *testing.T) {
+			m := newTestDatabaseQueue(t, skipLocked, time.Second)
+			ctx := context.Background()
+			require.NoError(t, m.Push(ctx, &registryTestJob{}))
+
+			first, err := m.Pop(ctx, "default")
+			require.NoError(t, err)
+			assert.Equal(t, 0, first.GetAttempts())
+
+			// 保留未过期时不会被再次取出
+			_, err = m.Pop(ctx, "default")
+			assert.Equal(t, ErrQueueEmpty, err)
+
+			// 保留过期视为工作进程已崩溃，重新取出并增加重试次数
+			waitForReservationExpiry(first, time.Second)
+			second, err := m.Pop(ctx, "default")
+			require.NoError(t, err)
+			assert.Equal(t, first.GetID(), second.GetID())
+			assert.Equal(t, 1, second.GetAttempts())
+		})
+	}
+}
+
+// waitForReservationExpiry 等待任务本次取出时的保留过期
+func waitForReservationExpiry(job JobInterface, retryAfter time.Duration) {
+	time.Sleep(time.Until(job.GetReservedAt().Add(retryAfter + 50*time.Millisecond)))
+}
+
+func TestDatabaseQueueStaleWorkerCannotTouchReclaimedJob(t *testing.T) {
+	m := newTestDatabaseQueue(t, false, time.Second)
+	ctx := context.Background()
+	require.NoError(t, m.Push(ctx, &registryTestJob{Message: "reclaimed"}))
+
+	stale, err := m.Pop(ctx, "default")
+	require.NoError(t, err)
+
+	// 保留过期后由另一个工作进程重新取出
+	waitForReservationExpiry(stale, time.Second)
+	current, err := m.Pop(ctx, "default")
+	require.NoError(t, err)
+	require.Equal(t, stale.GetID(), current.GetID())
+	require.NotEqual(t, *stale.GetReservedAt(), *current.GetReservedAt())
+
+	// 原工作进程的删除和释放不影响重新保留的行
+	assert.ErrorIs(t, m.Delete(ctx, "default", stale), ErrReservationLost)
+	assert.ErrorIs(t, m.Release(ctx, "default", stale, 0), ErrReservationLost)
+
+	var row QueueJob
+	require.NoError(t, m.GetDriver().(*DatabaseQueue).db.Where("id = ?", current.GetID()).First(&row).Error)
+	require.NotNil(t, row.ReservedAt)
+	assert.True(t, row.ReservedAt.Equal(*current.GetReservedAt()))
+	assert.Equal(t, 1, row.Attempts)
+
+	// 当前持有保留的工作进程可以正常删除
+	require.NoError(t, m.Delete(ctx, "default", current))
+	counts, err := m.GetDriver().(*DatabaseQueue).Counts(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, QueueCounts{}, counts)
+}
+
+func TestDatabaseQueueReleaseAndDelete(t *testing.T) {
+	m := newTestDatabaseQueue(t, false, time.Minute)
+	ctx := context.Background()
+	require.NoError(t, m.Push(ctx, &registryTestJob{Message: "retry"}))
+
+	job, err := m.Pop(ctx, "default")
+	require.NoError(t, err)
+
+	// 延迟释放的任务到时间前不会被取出
+	require.NoError(t, m.Release(ctx, "default", job, time.Hour))
+	_, err = m.Pop(ctx, "default")
+	assert.Equal(t, ErrQueueEmpty, err)
+
+	// 立即释放后可再次取出，重试次数加一
+	require.NoError(t, m.GetDriver().(*DatabaseQueue).db.Model(&QueueJob{}).
+		Where("id = ?", job.GetID()).Update("available_at", time.Now().Add(-time.Second)).Error)
+	job, err = m.Pop(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.GetAttempts())
+	assert.Equal(t, "retry", job.(*registryTestJob).Message)
+
+	require.NoError(t, m.Delete(ctx, "default", job))
+	counts, err := m.GetDriver().(*DatabaseQueue).Counts(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, QueueCounts{}, counts)
+}
+
+func TestDatabaseThrottleStoreCountsPerPeriod(t *testing.T) {
+	m := newTestDatabaseQueue(t, false, time.Minute)
+	store := m.ThrottleStore()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		acquired, err := store.Acquire(ctx, "queues:throttle:welcome", 3, 50*time.Millisecond)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+	}
+	acquired, err := store.Acquire(ctx, "queues:throttle:welcome", 3, 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// 归还的额度可以再次占用
+	require.NoError(t, store.Return(ctx, "queues:throttle:welcome"))
+	acquired, err = store.Acquire(ctx, "queues:throttle:welcome", 3, 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// 周期结束后计数重新开始
+	time.Sleep(100 * time.Millisecond)
+	acquired, err = store.Acquire(ctx, "queues:throttle:welcome", 3, 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
@@ -0,0 +1,87 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QueueThrottle 限流计数表结构
+type QueueThrottle struct {
+	Name      string    `gorm:"primaryKey;type:varchar(191)"`
+	Count     int       `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"type:timestamp;index;not null"`
+}
+
+// TableName 指定表名
+func (QueueThrottle) TableName() string {
+	return "queue_throttles"
+}
+
+// DatabaseThrottleStore 数据库限流存储，每个限流键一行计数
+type DatabaseThrottleStore struct {
+	db *gorm.DB
+}
+
+// NewDatabaseThrottleStore 创建数据库限流存储
+func NewDatabaseThrottleStore(db *gorm.DB) (*DatabaseThrottleStore, error) {
+	// 自动迁移表结构
+	if err := db.AutoMigrate(&QueueThrottle{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate queue_throttles table: %v", err)
+	}
+
+	return &DatabaseThrottleStore{db: db}, nil
+}
+
+// Acquire 占用一个额度
+//
+// 周期内的计数通过带条件的 UPDATE 原子地加一，周期结束后删除旧计数并插入新的计数。
+func (s *DatabaseThrottleStore) Acquire(ctx context.Context, key string, limit int, per time.Duration) (bool, error) {
+	now := time.Now()
+	db := s.db.WithContext(ctx)
+
+	acquired, err := s.increment(db, key, limit, now)
+	if err != nil || acquired {
+		return acquired, err
+	}
+
+	// 周期已结束的计数重新开始
+	if err := db.Where("name = ? AND expires_at <= ?", key, now).Delete(&QueueThrottle{}).Error; err != nil {
+		return false, err
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&QueueThrottle{
+		Name:      key,
+		Count:     1,
+		ExpiresAt: now.Add(per),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	// 其他进程同时开始了新的周期
+	return s.increment(db, key, limit, now)
+}
+
+// increment 周期内计数未满时加一
+func (s *DatabaseThrottleStore) increment(db *gorm.DB, key string, limit int, now time.Time) (bool, error) {
+	result := db.Model(&QueueThrottle{}).
+		Where("name = ? AND expires_at > ? AND count < ?", key, now, limit).
+		UpdateColumn("count", gorm.Expr("count + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Return 归还一个额度
+func (s *DatabaseThrottleStore) Return(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Model(&QueueThrottle{}).
+		Where("name = ? AND expires_at > ? AND count > 0", key, time.Now()).
+		UpdateColumn("count", gorm.Expr("count - 1")).Error
+}
@@ -0,0 +1,187 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrFailedJobNotFound 失败任务不存在
+	ErrFailedJobNotFound = errors.New("failed job not found")
+	// ErrFailedStoreUnavailable 当前驱动未配置失败任务存储
+	ErrFailedStoreUnavailable = errors.New("failed job store unavailable")
+)
+
+// FailedJob 最终失败的任务记录
+type FailedJob struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Queue     string    `json:"queue" gorm:"type:varchar(100);index;not null"`
+	Job       string    `json:"job" gorm:"type:varchar(100)"`
+	Payload   string    `json:"payload" gorm:"type:longtext;not null"`
+	Exception string    `json:"exception" gorm:"type:text"`
+	Attempts  int       `json:"attempts" gorm:"default:0;not null"`
+	FailedAt  time.Time `json:"failed_at" gorm:"type:timestamp;index;not null"`
+}
+
+// TableName 指定表名
+func (FailedJob) TableName() string {
+	return "failed_jobs"
+}
+
+// FailedJobStore 失败任务存储接口
+type FailedJobStore interface {
+	// Log 记录失败任务
+	Log(ctx context.Context, job *FailedJob) error
+	// All 获取所有失败任务，按失败时间倒序
+	All(ctx context.Context) ([]*FailedJob, error)
+	// Find 根据ID获取失败任务
+	Find(ctx context.Context, id string) (*FailedJob, error)
+	// Forget 删除失败任务
+	Forget(ctx context.Context, id string) error
+	// Flush 清空所有失败任务
+	Flush(ctx context.Context) error
+}
+
+// failedJobProvider 由驱动实现，提供与驱动共用连接的失败任务存储
+type failedJobProvider interface {
+	failedJobStore() FailedJobStore
+}
+
+// SetFailedJobStore 设置失败任务存储
+func (m *Manager) SetFailedJobStore(store FailedJobStore) {
+	m.failed = store
+}
+
+// FailedJobStore 获取失败任务存储
+func (m *Manager) FailedJobStore() FailedJobStore {
+	return m.failed
+}
+
+// Fail 记录失败任务并将其从队列中移除
+func (m *Manager) Fail(ctx context.Context, queue string, job JobInterface, jobErr error) error {
+	name, _ := JobName(job)
+	log.Printf("[ERROR] Job %s (%s) failed on queue %s after %d attempt(s): %v", job.GetID(), name, queue, job.GetAttempts()+1, jobErr)
+
+	if m.failed != nil {
+		payload, err := encodeJob(job)
+		if err != nil {
+			// 未注册的任务无法编码，保留原始字段便于排查
+			payload, _ = json.Marshal(job)
+		}
+
+		exception := ""
+		if jobErr != nil {
+			exception = jobErr.Error()
+		}
+
+		if err := m.failed.Log(ctx, &FailedJob{
+			ID:        uuid.New().String(),
+			Queue:     queue,
+			Job:       name,
+			Payload:   string(payload),
+			Exception: exception,
+			Attempts:  job.GetAttempts() + 1,
+			FailedAt:  time.Now(),
+		}); err != nil {
+			// 记录失败时保留队列中的任务，避免丢失
+			return fmt.Errorf("failed to log failed job: %w", err)
+		}
+	}
+
+	m.recordBatchResult(ctx, job, jobErr)
+
+	if err := m.driver.Delete(ctx, queue, job); err != nil {
+		return err
+	}
+	m.releaseUniqueLock(ctx, job)
+	return nil
+}
+
+// FailedJobs 获取所有失败任务
+func (m *Manager) FailedJobs(ctx context.Context) ([]*FailedJob, error) {
+	if m.failed == nil {
+		return nil, ErrFailedStoreUnavailable
+	}
+	return m.failed.All(ctx)
+}
+
+// FindFailed 根据ID获取失败任务
+func (m *Manager) FindFailed(ctx context.Context, id string) (*FailedJob, error) {
+	if m.failed == nil {
+		return nil, ErrFailedStoreUnavailable
+	}
+	return m.failed.Find(ctx, id)
+}
+
+// Retry 将失败任务重新推送到原队列
+func (m *Manager) Retry(ctx context.Context, id string) error {
+	if m.failed == nil {
+		return ErrFailedStoreUnavailable
+	}
+
+	failed, err := m.failed.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// 重置重试次数后重新编码
+	job := decodeJob([]byte(failed.Payload))
+	job.SetAttempts(0)
+	job.SetReservedAt(nil)
+	payload, err := encodeJob(job)
+	if err != nil {
+		return err
+	}
+
+	// 唯一任务的锁已在失败时释放，重新推送前需再次加锁
+	if err := m.acquireUniqueLock(ctx, job); err != nil {
+		return err
+	}
+	if err := m.driver.PushRaw(ctx, failed.Queue, payload, map[string]interface{}{
+		"max_attempts": job.GetMaxAttempts(),
+	}); err != nil {
+		m.releaseUniqueLock(ctx, job)
+		return err
+	}
+
+	return m.failed.Forget(ctx, id)
+}
+
+// RetryAll 重新推送所有失败任务，返回成功推送的数量
+func (m *Manager) RetryAll(ctx context.Context) (int, error) {
+	jobs, err := m.FailedJobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	retried := 0
+	for _, job := range jobs {
+		if err := m.Retry(ctx, job.ID); err != nil {
+			return retried, fmt.Errorf("failed to retry job %s: %w", job.ID, err)
+		}
+		retried++
+	}
+	return retried, nil
+}
+
+// Forget 删除失败任务
+func (m *Manager) Forget(ctx context.Context, id string) error {
+	if m.failed == nil {
+		return ErrFailedStoreUnavailable
+	}
+	return m.failed.Forget(ctx, id)
+}
+
+// Flush 清空所有失败任务
+func (m *Manager) Flush(ctx context.Context) error {
+	if m.failed == nil {
+		return ErrFailedStoreUnavailable
+	}
+	return m.failed.Flush(ctx)
+}
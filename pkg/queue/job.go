@@ -19,6 +19,31 @@ type BaseJob struct {
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
 	ReservedAt  *time.Time      `json:"reserved_at,omitempty"`
+	TraceID     string          `json:"trace_id,omitempty"`
+	BatchID     string          `json:"batch_id,omitempty"`
+	// Chained 当前任务成功后依次推送的任务
+	Chained []json.RawMessage `json:"chained,omitempty"`
+
+	// reserved 取出任务时的原始数据，驱动据此定位保留中的任务
+	reserved []byte
+}
+
+// batchable 可归属于批次的任务，嵌入 BaseJob 的任务均已实现
+type batchable interface {
+	GetBatchID() string
+	SetBatchID(batchID string)
+}
+
+// chainable 可携带后续任务链的任务，嵌入 BaseJob 的任务均已实现
+type chainable interface {
+	chain() []json.RawMessage
+	setChain(chain []json.RawMessage)
+}
+
+// reservable 可记录保留数据的任务，嵌入 BaseJob 的任务均已实现
+type reservable interface {
+	reservation() []byte
+	setReservation(payload []byte)
 }
 
 // NewBaseJob 创建基础任务
@@ -35,7 +60,7 @@ func NewBaseJob(queue string, payload interface{}, options map[string]interface{
 		Attempts:    0,
 		MaxAttempts: 3, // 默认最大重试3次
 		Delay:       0,
-		Timeout:     60 * time.Second,                                                        // 默认超时60秒
+		Timeout:     0,                                                                       // 未设置时使用队列的超时时间
 		RetryAfter:  60 * time.Second,                                                        // 默认重试等待60秒
 		Backoff:     []time.Duration{60 * time.Second, 300 * time.Second, 900 * time.Second}, // 默认退避策略
 		CreatedAt:   time.Now(),
@@ -132,8 +157,53 @@ func (j *BaseJob) SetID(id string) {
 	j.UpdatedAt = time.Now()
 }
 
+// GetReservedAt 获取保留时间
+func (j *BaseJob) GetReservedAt() *time.Time {
+	return j.ReservedAt
+}
+
 // SetReservedAt 设置保留时间
 func (j *BaseJob) SetReservedAt(t *time.Time) {
 	j.ReservedAt = t
 	j.UpdatedAt = time.Now()
 }
+
+// GetTraceID 获取推送任务时请求的追踪ID
+func (j *BaseJob) GetTraceID() string {
+	return j.TraceID
+}
+
+// SetTraceID 设置追踪ID
+func (j *BaseJob) SetTraceID(traceID string) {
+	j.TraceID = traceID
+}
+
+// reservation 获取取出任务时的原始数据
+func (j *BaseJob) reservation() []byte {
+	return j.reserved
+}
+
+// setReservation 记录取出任务时的原始数据
+func (j *BaseJob) setReservation(payload []byte) {
+	j.reserved = payload
+}
+
+// GetBatchID 获取任务所属的批次ID
+func (j *BaseJob) GetBatchID() string {
+	return j.BatchID
+}
+
+// SetBatchID 设置任务所属的批次ID
+func (j *BaseJob) SetBatchID(batchID string) {
+	j.BatchID = batchID
+}
+
+// chain 获取后续任务链
+func (j *BaseJob) chain() []json.RawMessage {
+	return j.Chained
+}
+
+// setChain 设置后续任务链
+func (j *BaseJob) setChain(chain []json.RawMessage) {
+	j.Chained = chain
+}
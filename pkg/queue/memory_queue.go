@@ -0,0 +1,300 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"app/pkg/locker"
+)
+
+func init() {
+	Register("memory", func(config Config) (QueueInterface, error) {
+		return NewMemoryQueue(config), nil
+	})
+}
+
+// memoryEntry 内存队列中的任务
+type memoryEntry struct {
+	id          string
+	payload     []byte
+	attempts    int
+	availableAt time.Time
+	reservedAt  *time.Time
+}
+
+// MemoryQueue 内存队列驱动
+//
+// 任务保存在进程内存中，支持延迟、释放和保留过期重新入队，
+// 适用于测试和本地开发，进程退出后任务丢失。
+type MemoryQueue struct {
+	mu      sync.Mutex
+	config  Config
+	queues  map[string][]*memoryEntry
+	nextID  int64
+	failed  *MemoryFailedJobStore
+	batches *MemoryBatchStore
+	lock    *locker.MemoryLocker
+	monitor *MemoryMonitorStore
+	// throttles 限流计数存储
+	throttles *MemoryThrottleStore
+	// retryAfter 任务被取出后的保留时间
+	retryAfter time.Duration
+}
+
+// NewMemoryQueue 创建内存队列驱动
+func NewMemoryQueue(config Config) *MemoryQueue {
+	return &MemoryQueue{
+		config:     config,
+		queues:     make(map[string][]*memoryEntry),
+		failed:     NewMemoryFailedJobStore(),
+		batches:    NewMemoryBatchStore(),
+		lock:       locker.NewMemoryLocker(),
+		monitor:    NewMemoryMonitorStore(),
+		throttles:  NewMemoryThrottleStore(),
+		retryAfter: visibilityTimeout(config),
+	}
+}
+
+// failedJobStore 返回内存失败任务存储
+func (q *MemoryQueue) failedJobStore() FailedJobStore {
+	return q.failed
+}
+
+// batchStore 返回内存批次存储
+func (q *MemoryQueue) batchStore() BatchStore {
+	return q.batches
+}
+
+// locker 返回内存锁
+func (q *MemoryQueue) locker() locker.Locker {
+	return q.lock
+}
+
+// monitorStore 返回内存监控存储
+func (q *MemoryQueue) monitorStore() MonitorStore {
+	return q.monitor
+}
+
+// throttleStore 返回内存限流存储
+func (q *MemoryQueue) throttleStore() ThrottleStore {
+	return q.throttles
+}
+
+// queueName 未指定队列时使用配置中的默认队列
+func (q *MemoryQueue) queueName(queue string) (string, error) {
+	if queue != "" {
+		return queue, nil
+	}
+	if queueOpt, ok := q.config.Options["queue"].(string); ok {
+		return queueOpt, nil
+	}
+	return "", fmt.Errorf("queue name not found in options")
+}
+
+// add 将任务数据加入队列
+func (q *MemoryQueue) add(queue string, payload []byte, attempts int, delay time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.nextID++
+	q.queues[queue] = append(q.queues[queue], &memoryEntry{
+		id:          strconv.FormatInt(q.nextID, 10),
+		payload:     payload,
+		attempts:    attempts,
+		availableAt: time.Now().Add(delay),
+	})
+}
+
+// Push 推送任务到队列
+func (q *MemoryQueue) Push(ctx context.Context, job JobInterface) error {
+	return q.Later(ctx, job, job.GetDelay())
+}
+
+// PushRaw 推送原始数据到队列
+func (q *MemoryQueue) PushRaw(ctx context.Context, queue string, payload []byte, options map[string]interface{}) error {
+	queue, err := q.queueName(queue)
+	if err != nil {
+		return err
+	}
+
+	delay := time.Duration(0)
+	if v, ok := options["delay"].(time.Duration); ok {
+		delay = v
+	}
+
+	q.add(queue, payload, 0, delay)
+	return nil
+}
+
+// Later 延迟推送任务
+func (q *MemoryQueue) Later(ctx context.Context, job JobInterface, delay time.Duration) error {
+	payload, err := encodeJob(job)
+	if err != nil {
+		return err
+	}
+
+	queue, err := q.queueName(job.GetQueue())
+	if err != nil {
+		return err
+	}
+
+	q.add(queue, payload, job.GetAttempts(), delay)
+	return nil
+}
+
+// Pop 从队列中取出任务，保留过期的任务会被重新取出并增加重试次数
+func (q *MemoryQueue) Pop(ctx context.Context, queue string) (JobInterface, error) {
+	queue, err := q.queueName(queue)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	now := time.Now()
+	var entry *memoryEntry
+	for _, e := range q.queues[queue] {
+		if e.reservedAt == nil && !e.availableAt.After(now) {
+			entry = e
+			break
+		}
+		if e.reservedAt != nil && !e.reservedAt.Add(q.retryAfter).After(now) {
+			e.attempts++
+			entry = e
+			break
+		}
+	}
+	if entry == nil {
+		q.mu.Unlock()
+		return nil, ErrQueueEmpty
+	}
+	entry.reservedAt = &now
+	id, payload, attempts := entry.id, entry.payload, entry.attempts
+	q.mu.Unlock()
+
+	job := decodeJob(payload)
+	job.SetID(id)
+	job.SetAttempts(attempts)
+	reservedAt := now
+	job.SetReservedAt(&reservedAt)
+
+	return job, nil
+}
+
+// Size 获取队列大小，包括延迟和保留中的任务
+func (q *MemoryQueue) Size(ctx context.Context, queue string) (int64, error) {
+	queue, err := q.queueName(queue)
+	if err != nil {
+		return 0, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return int64(len(q.queues[queue])), nil
+}
+
+// Counts 按状态统计队列中的任务
+func (q *MemoryQueue) Counts(ctx context.Context, queue string) (QueueCounts, error) {
+	queue, err := q.queueName(queue)
+	if err != nil {
+		return QueueCounts{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var counts QueueCounts
+	now := time.Now()
+	for _, e := range q.queues[queue] {
+		switch {
+		case e.reservedAt != nil:
+			counts.Reserved++
+		case e.availableAt.After(now):
+			counts.Delayed++
+		default:
+			counts.Pending++
+		}
+	}
+	return counts, nil
+}
+
+// Delete 删除任务
+func (q *MemoryQueue) Delete(ctx context.Context, queue string, job JobInterface) error {
+	queue, err := q.queueName(queue)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := q.queues[queue]
+	for i, e := range entries {
+		if e.id == job.GetID() {
+			q.queues[queue] = append(entries[:i], entries[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// Release 释放任务回队列
+func (q *MemoryQueue) Release(ctx context.Context, queue string, job JobInterface, delay time.Duration) error {
+	queue, err := q.queueName(queue)
+	if err != nil {
+		return err
+	}
+
+	// 增加重试次数
+	attempts := job.GetAttempts() + 1
+
+	// 未指定延迟时使用退避策略
+	if delay <= 0 {
+		backoff := job.GetBackoff()
+		if len(backoff) > 0 {
+			attempt := attempts - 1
+			if attempt < len(backoff) {
+				delay = backoff[attempt]
+			} else {
+				delay = backoff[len(backoff)-1]
+			}
+		} else {
+			delay = job.GetRetryAfter()
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, e := range q.queues[queue] {
+		if e.id == job.GetID() {
+			e.attempts = attempts
+			e.reservedAt = nil
+			e.availableAt = time.Now().Add(delay)
+			return nil
+		}
+	}
+	return ErrJobNotFound
+}
+
+// Clear 清空队列
+func (q *MemoryQueue) Clear(ctx context.Context, queue string) error {
+	queue, err := q.queueName(queue)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.queues, queue)
+	return nil
+}
+
+// Close 关闭队列连接
+func (q *MemoryQueue) Close() error {
+	return nil
+}
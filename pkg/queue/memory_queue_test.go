@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingTestJob 总是失败的任务
+type failingTestJob struct {
+	BaseJob
+}
+
+func (j *failingTestJob) Handle() error {
+	return errors.New("boom")
+}
+
+func init() {
+	RegisterJob("failing_test", func() JobInterface { return &failingTestJob{} })
+}
+
+func TestMemoryQueueDelayReleaseAndSize(t *testing.T) {
+	m, err := NewManager(Config{Driver: "memory", Options: map[string]interface{}{"queue": "default"}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, m.Push(ctx, &registryTestJob{Message: "now"}))
+	require.NoError(t, m.Later(ctx, &registryTestJob{Message: "later"}, time.Hour))
+
+	size, err := m.Size(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), size)
+
+	job, err := m.Pop(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, "now", job.(*registryTestJob).Message)
+
+	// 延迟任务未到时间，保留中的任务不会被再次取出
+	_, err = m.Pop(ctx, "default")
+	assert.Equal(t, ErrQueueEmpty, err)
+
+	// 释放后立即可用，重试次数加一
+	require.NoError(t, m.Release(ctx, "default", job, time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	job, err = m.Pop(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.GetAttempts())
+
+	require.NoError(t, m.Delete(ctx, "default", job))
+	size, err = m.Size(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+
+	require.NoError(t, m.Clear(ctx, "default"))
+	size, err = m.Size(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+}
+
+func TestMemoryQueueRequeuesExpiredReservation(t *testing.T) {
+	m, err := NewManager(Config{Driver: "memory", Options: map[string]interface{}{
+		"queue":       "default",
+		"retry_after": time.Millisecond,
+	}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, m.Push(ctx, &registryTestJob{}))
+	_, err = m.Pop(ctx, "default")
+	require.NoError(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+	job, err := m.Pop(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, 1, job.GetAttempts())
+}
+
+func TestSyncQueueRunsJobOnPush(t *testing.T) {
+	m, err := NewManager(Config{Driver: "sync", Options: map[string]interface{}{"queue": "default"}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	job := &registryTestJob{}
+	require.NoError(t, m.Push(ctx, job))
+	assert.True(t, job.handled)
+
+	// 失败的任务返回错误并记入失败任务存储
+	assert.EqualError(t, m.Push(ctx, &failingTestJob{}), "boom")
+	failed, err := m.FailedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "failing_test", failed[0].Job)
+}
@@ -0,0 +1,350 @@
+package queue
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryFailedJobStore 内存失败任务存储，进程退出后数据丢失
+type MemoryFailedJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]*FailedJob
+}
+
+// NewMemoryFailedJobStore 创建内存失败任务存储
+func NewMemoryFailedJobStore() *MemoryFailedJobStore {
+	return &MemoryFailedJobStore{jobs: make(map[string]*FailedJob)}
+}
+
+// Log 记录失败任务
+func (s *MemoryFailedJobStore) Log(ctx context.Context, job *FailedJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *job
+	s.jobs[job.ID] = &copied
+	return nil
+}
+
+// All 获取所有失败任务，按失败时间倒序
+func (s *MemoryFailedJobStore) All(ctx context.Context) ([]*FailedJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]*FailedJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].FailedAt.After(jobs[j].FailedAt)
+	})
+	return jobs, nil
+}
+
+// Find 根据ID获取失败任务
+func (s *MemoryFailedJobStore) Find(ctx context.Context, id string) (*FailedJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrFailedJobNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+// Forget 删除失败任务
+func (s *MemoryFailedJobStore) Forget(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return ErrFailedJobNotFound
+	}
+	delete(s.jobs, id)
+	return nil
+}
+
+// Flush 清空所有失败任务
+func (s *MemoryFailedJobStore) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = make(map[string]*FailedJob)
+	return nil
+}
+
+// MemoryBatchStore 内存批次存储，进程退出后数据丢失
+type MemoryBatchStore struct {
+	mu      sync.RWMutex
+	batches map[string]*Batch
+}
+
+// NewMemoryBatchStore 创建内存批次存储
+func NewMemoryBatchStore() *MemoryBatchStore {
+	return &MemoryBatchStore{batches: make(map[string]*Batch)}
+}
+
+// Store 保存新批次
+func (s *MemoryBatchStore) Store(ctx context.Context, batch *Batch) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *batch
+	s.batches[batch.ID] = &copied
+	return nil
+}
+
+// Find 根据ID获取批次
+func (s *MemoryBatchStore) Find(ctx context.Context, id string) (*Batch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	batch, ok := s.batches[id]
+	if !ok {
+		return nil, ErrBatchNotFound
+	}
+	copied := *batch
+	return &copied, nil
+}
+
+// All 获取所有批次，按创建时间倒序
+func (s *MemoryBatchStore) All(ctx context.Context) ([]*Batch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	batches := make([]*Batch, 0, len(s.batches))
+	for _, batch := range s.batches {
+		copied := *batch
+		batches = append(batches, &copied)
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		return batches[i].CreatedAt.After(batches[j].CreatedAt)
+	})
+	return batches, nil
+}
+
+// RecordResult 原子地记录一个任务结束
+func (s *MemoryBatchStore) RecordResult(ctx context.Context, id string, failed bool) (*Batch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch, ok := s.batches[id]
+	if !ok {
+		return nil, ErrBatchNotFound
+	}
+	if failed {
+		batch.FailedJobs++
+	}
+	batch.PendingJobs--
+	if batch.PendingJobs <= 0 && batch.FinishedAt == nil {
+		now := time.Now()
+		batch.FinishedAt = &now
+	}
+
+	copied := *batch
+	return &copied, nil
+}
+
+// Delete 删除批次
+func (s *MemoryBatchStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.batches[id]; !ok {
+		return ErrBatchNotFound
+	}
+	delete(s.batches, id)
+	return nil
+}
+
+// MemoryMonitorStore 内存监控存储，只能看到当前进程内的工作进程
+type MemoryMonitorStore struct {
+	mu         sync.RWMutex
+	metrics    map[string]map[int64]*JobMetrics
+	heartbeats map[string]*WorkerHeartbeat
+	paused     map[string]bool
+}
+
+// NewMemoryMonitorStore 创建内存监控存储
+func NewMemoryMonitorStore() *MemoryMonitorStore {
+	return &MemoryMonitorStore{
+		metrics:    make(map[string]map[int64]*JobMetrics),
+		heartbeats: make(map[string]*WorkerHeartbeat),
+		paused:     make(map[string]bool),
+	}
+}
+
+// RecordJob 记录一次任务执行，按分钟汇总
+func (s *MemoryMonitorStore) RecordJob(ctx context.Context, queue string, runtime time.Duration, failed bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets, ok := s.metrics[queue]
+	if !ok {
+		buckets = make(map[int64]*JobMetrics)
+		s.metrics[queue] = buckets
+	}
+
+	now := time.Now()
+	minute := now.Truncate(time.Minute).Unix()
+	bucket, ok := buckets[minute]
+	if !ok {
+		bucket = &JobMetrics{}
+		buckets[minute] = bucket
+
+		// 新的一分钟开始时清理过期的统计
+		expired := now.Add(-MetricsRetention).Unix()
+		for m := range buckets {
+			if m < expired {
+				delete(buckets, m)
+			}
+		}
+	}
+
+	bucket.Processed++
+	bucket.Runtime += runtime
+	if failed {
+		bucket.Failed++
+	}
+	return nil
+}
+
+// Metrics 获取队列自 since 以来的任务处理统计
+func (s *MemoryMonitorStore) Metrics(ctx context.Context, queue string, since time.Time) (JobMetrics, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var metrics JobMetrics
+	from := since.Truncate(time.Minute).Unix()
+	for minute, bucket := range s.metrics[queue] {
+		if minute < from {
+			continue
+		}
+		metrics.Processed += bucket.Processed
+		metrics.Failed += bucket.Failed
+		metrics.Runtime += bucket.Runtime
+	}
+	return metrics, nil
+}
+
+// Heartbeat 上报工作进程心跳
+func (s *MemoryMonitorStore) Heartbeat(ctx context.Context, heartbeat *WorkerHeartbeat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *heartbeat
+	s.heartbeats[heartbeat.ID] = &copied
+	return nil
+}
+
+// Heartbeats 获取未过期的工作进程心跳，按启动时间排列
+func (s *MemoryMonitorStore) Heartbeats(ctx context.Context) ([]*WorkerHeartbeat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := time.Now().Add(-HeartbeatTTL)
+	heartbeats := make([]*WorkerHeartbeat, 0, len(s.heartbeats))
+	for id, heartbeat := range s.heartbeats {
+		if heartbeat.LastSeen.Before(expired) {
+			delete(s.heartbeats, id)
+			continue
+		}
+		copied := *heartbeat
+		heartbeats = append(heartbeats, &copied)
+	}
+	sort.Slice(heartbeats, func(i, j int) bool {
+		return heartbeats[i].StartedAt.Before(heartbeats[j].StartedAt)
+	})
+	return heartbeats, nil
+}
+
+// RemoveHeartbeat 移除工作进程心跳
+func (s *MemoryMonitorStore) RemoveHeartbeat(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.heartbeats, id)
+	return nil
+}
+
+// Pause 暂停队列
+func (s *MemoryMonitorStore) Pause(ctx context.Context, queue string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paused[queue] = true
+	return nil
+}
+
+// Resume 恢复队列
+func (s *MemoryMonitorStore) Resume(ctx context.Context, queue string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.paused, queue)
+	return nil
+}
+
+// PausedQueues 获取已暂停的队列
+func (s *MemoryMonitorStore) PausedQueues(ctx context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	queues := make([]string, 0, len(s.paused))
+	for queue := range s.paused {
+		queues = append(queues, queue)
+	}
+	sort.Strings(queues)
+	return queues, nil
+}
+
+// memoryThrottle 内存限流计数
+type memoryThrottle struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MemoryThrottleStore 内存限流存储，仅适用于单进程
+type MemoryThrottleStore struct {
+	mu        sync.Mutex
+	throttles map[string]*memoryThrottle
+}
+
+// NewMemoryThrottleStore 创建内存限流存储
+func NewMemoryThrottleStore() *MemoryThrottleStore {
+	return &MemoryThrottleStore{throttles: make(map[string]*memoryThrottle)}
+}
+
+// Acquire 占用一个额度
+func (s *MemoryThrottleStore) Acquire(ctx context.Context, key string, limit int, per time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	throttle, ok := s.throttles[key]
+	if !ok || !throttle.expiresAt.After(now) {
+		throttle = &memoryThrottle{expiresAt: now.Add(per)}
+		s.throttles[key] = throttle
+	}
+	if throttle.count >= limit {
+		return false, nil
+	}
+	throttle.count++
+	return true, nil
+}
+
+// Return 归还一个额度
+func (s *MemoryThrottleStore) Return(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if throttle, ok := s.throttles[key]; ok && throttle.expiresAt.After(time.Now()) && throttle.count > 0 {
+		throttle.count--
+	}
+	return nil
+}
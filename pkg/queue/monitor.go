@@ -0,0 +1,185 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+)
+
+// ErrMonitorUnavailable 当前驱动未配置监控存储
+var ErrMonitorUnavailable = errors.New("queue monitor store unavailable")
+
+const (
+	// HeartbeatInterval 工作进程上报心跳的间隔
+	HeartbeatInterval = 10 * time.Second
+	// HeartbeatTTL 超过此时间未上报心跳的工作进程视为已退出
+	HeartbeatTTL = 3 * HeartbeatInterval
+	// MetricsRetention 任务处理统计的保留时间
+	MetricsRetention = 24 * time.Hour
+	// pausedRefreshInterval 工作进程刷新暂停队列的间隔
+	pausedRefreshInterval = 2 * time.Second
+)
+
+// 工作进程状态
+const (
+	WorkerStatusIdle = "idle"
+	WorkerStatusBusy = "busy"
+)
+
+// QueueCounts 队列中各状态的任务数
+type QueueCounts struct {
+	// Pending 可立即取出的任务数
+	Pending int64 `json:"pending"`
+	// Delayed 未到执行时间的任务数
+	Delayed int64 `json:"delayed"`
+	// Reserved 已取出正在执行的任务数
+	Reserved int64 `json:"reserved"`
+}
+
+// countsProvider 由驱动实现，按状态统计队列中的任务
+type countsProvider interface {
+	Counts(ctx context.Context, queue string) (QueueCounts, error)
+}
+
+// JobMetrics 一段时间内的任务处理统计
+type JobMetrics struct {
+	// Processed 执行次数，包括失败的执行
+	Processed int64 `json:"processed"`
+	// Failed 执行失败或超时的次数
+	Failed int64 `json:"failed"`
+	// Runtime 执行总耗时
+	Runtime time.Duration `json:"-"`
+}
+
+// AverageRuntime 平均执行耗时
+func (m JobMetrics) AverageRuntime() time.Duration {
+	if m.Processed == 0 {
+		return 0
+	}
+	return m.Runtime / time.Duration(m.Processed)
+}
+
+// WorkerHeartbeat 工作进程心跳
+type WorkerHeartbeat struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Queues    []string  `json:"queues" gorm:"serializer:json;type:text"`
+	Hostname  string    `json:"hostname" gorm:"type:varchar(255)"`
+	PID       int       `json:"pid"`
+	Status    string    `json:"status" gorm:"type:varchar(20)"`
+	Job       string    `json:"job" gorm:"type:varchar(100)"`
+	Processed int64     `json:"processed"`
+	StartedAt time.Time `json:"started_at" gorm:"type:timestamp;not null"`
+	LastSeen  time.Time `json:"last_seen" gorm:"type:timestamp;index;not null"`
+}
+
+// TableName 指定表名
+func (WorkerHeartbeat) TableName() string {
+	return "queue_workers"
+}
+
+// MonitorStore 队列监控数据存储
+//
+// 工作进程与管理后台可能运行在不同进程中，统计、心跳和暂停状态都通过存储共享。
+type MonitorStore interface {
+	// RecordJob 记录一次任务执行
+	RecordJob(ctx context.Context, queue string, runtime time.Duration, failed bool) error
+	// Metrics 获取队列自 since 以来的任务处理统计
+	Metrics(ctx context.Context, queue string, since time.Time) (JobMetrics, error)
+	// Heartbeat 上报工作进程心跳
+	Heartbeat(ctx context.Context, heartbeat *WorkerHeartbeat) error
+	// Heartbeats 获取未过期的工作进程心跳
+	Heartbeats(ctx context.Context) ([]*WorkerHeartbeat, error)
+	// RemoveHeartbeat 工作进程退出时移除心跳
+	RemoveHeartbeat(ctx context.Context, id string) error
+	// Pause 暂停队列，工作进程不再从中取任务
+	Pause(ctx context.Context, queue string) error
+	// Resume 恢复队列
+	Resume(ctx context.Context, queue string) error
+	// PausedQueues 获取已暂停的队列
+	PausedQueues(ctx context.Context) ([]string, error)
+}
+
+// monitorProvider 由驱动实现，提供与驱动共用连接的监控存储
+type monitorProvider interface {
+	monitorStore() MonitorStore
+}
+
+// SetMonitorStore 设置监控存储
+func (m *Manager) SetMonitorStore(store MonitorStore) {
+	m.monitor = store
+}
+
+// MonitorStore 获取监控存储
+func (m *Manager) MonitorStore() MonitorStore {
+	return m.monitor
+}
+
+// Counts 按状态统计队列中的任务，驱动不支持时全部计为待处理
+func (m *Manager) Counts(ctx context.Context, queue string) (QueueCounts, error) {
+	if provider, ok := m.driver.(countsProvider); ok {
+		return provider.Counts(ctx, queue)
+	}
+	size, err := m.driver.Size(ctx, queue)
+	return QueueCounts{Pending: size}, err
+}
+
+// Metrics 获取队列自 since 以来的任务处理统计
+func (m *Manager) Metrics(ctx context.Context, queue string, since time.Time) (JobMetrics, error) {
+	if m.monitor == nil {
+		return JobMetrics{}, ErrMonitorUnavailable
+	}
+	return m.monitor.Metrics(ctx, queue, since)
+}
+
+// Heartbeats 获取正在运行的工作进程
+func (m *Manager) Heartbeats(ctx context.Context) ([]*WorkerHeartbeat, error) {
+	if m.monitor == nil {
+		return nil, ErrMonitorUnavailable
+	}
+	return m.monitor.Heartbeats(ctx)
+}
+
+// Pause 暂停队列
+func (m *Manager) Pause(ctx context.Context, queue string) error {
+	if m.monitor == nil {
+		return ErrMonitorUnavailable
+	}
+	return m.monitor.Pause(ctx, queue)
+}
+
+// Resume 恢复队列
+func (m *Manager) Resume(ctx context.Context, queue string) error {
+	if m.monitor == nil {
+		return ErrMonitorUnavailable
+	}
+	return m.monitor.Resume(ctx, queue)
+}
+
+// PausedQueues 获取已暂停的队列
+func (m *Manager) PausedQueues(ctx context.Context) ([]string, error) {
+	if m.monitor == nil {
+		return nil, nil
+	}
+	return m.monitor.PausedQueues(ctx)
+}
+
+// recordJob 记录任务执行统计，失败仅记录日志
+func (m *Manager) recordJob(ctx context.Context, queue string, runtime time.Duration, failed bool) {
+	if m.monitor == nil {
+		return
+	}
+	if err := m.monitor.RecordJob(ctx, queue, runtime, failed); err != nil {
+		log.Printf("[ERROR] Failed to record metrics of queue %s: %v", queue, err)
+	}
+}
+
+// hostname 当前主机名
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return name
+}
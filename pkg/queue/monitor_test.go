@@ -0,0 +1,58 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerSkipsPausedQueueAndRecordsMetrics(t *testing.T) {
+	m, err := NewManager(Config{Driver: "memory", Options: map[string]interface{}{"queue": "default"}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, m.Push(ctx, &registryTestJob{}))
+	require.NoError(t, m.Pause(ctx, "default"))
+
+	worker := NewWorker(m, []string{"default"}, WorkerOptions{})
+	_, err = worker.processNextJob()
+	assert.Equal(t, ErrQueueEmpty, err)
+
+	// 恢复后等待工作进程刷新暂停状态
+	require.NoError(t, m.Resume(ctx, "default"))
+	worker.pausedChecked = time.Time{}
+	_, err = worker.processNextJob()
+	require.NoError(t, err)
+
+	counts, err := m.Counts(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, QueueCounts{}, counts)
+
+	metrics, err := m.Metrics(ctx, "default", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), metrics.Processed)
+	assert.Equal(t, int64(0), metrics.Failed)
+}
+
+func TestWorkerHeartbeat(t *testing.T) {
+	m, err := NewManager(Config{Driver: "memory", Options: map[string]interface{}{"queue": "default"}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	worker := NewWorker(m, []string{"default"}, WorkerOptions{})
+	stop := worker.startHeartbeat()
+
+	heartbeats, err := m.Heartbeats(ctx)
+	require.NoError(t, err)
+	require.Len(t, heartbeats, 1)
+	assert.Equal(t, []string{"default"}, heartbeats[0].Queues)
+	assert.Equal(t, WorkerStatusIdle, heartbeats[0].Status)
+
+	stop()
+	heartbeats, err = m.Heartbeats(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, heartbeats)
+}
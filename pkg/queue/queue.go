@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
+
+	"app/pkg/locker"
 )
 
 var (
@@ -16,14 +19,20 @@ var (
 	ErrQueueNotFound = errors.New("queue not found")
 	// ErrJobNotFound 任务不存在
 	ErrJobNotFound = errors.New("job not found")
+	// ErrReservationLost 任务的保留已过期并被其他工作进程重新取出
+	ErrReservationLost = errors.New("job reservation lost")
 	// ErrUnsupportedDriver 不支持的驱动类型
 	ErrUnsupportedDriver = errors.New("unsupported queue driver")
 	// ErrJobTimeout 任务超时
 	ErrJobTimeout = errors.New("job timeout")
+	// ErrWorkerStopped 工作进程停止，任务被中断
+	ErrWorkerStopped = errors.New("worker stopped")
 	// ErrMaxAttemptsExceeded 超过最大重试次数
 	ErrMaxAttemptsExceeded = errors.New("max attempts exceeded")
 	// ErrInvalidPayload 无效的任务数据
 	ErrInvalidPayload = errors.New("invalid job payload")
+	// ErrTimeoutExceedsRetryAfter 任务超时时间不小于保留时间，执行期间会被重新取出
+	ErrTimeoutExceedsRetryAfter = errors.New("job timeout must be below retry_after")
 )
 
 // Job represents a queue job
@@ -67,6 +76,8 @@ type JobInterface interface {
 	GetID() string
 	// SetID 设置任务ID
 	SetID(id string)
+	// GetReservedAt 获取保留时间
+	GetReservedAt() *time.Time
 	// SetReservedAt 设置保留时间
 	SetReservedAt(t *time.Time)
 }
@@ -99,6 +110,27 @@ type Config struct {
 	Options map[string]interface{} `mapstructure:"options"` // driver-specific options
 }
 
+// DefaultVisibilityTimeout 任务被取出后保留的默认时间，超时未处理完的任务会重新入队
+const DefaultVisibilityTimeout = 90 * time.Second
+
+// visibilityTimeout 读取 retry_after 选项，支持 time.Duration 或秒数
+//
+// 保留时间必须大于任务的最长执行时间，否则任务执行期间会被重新取出。
+// Supervisor 启动时检查各队列的超时时间，推送时检查任务自身的超时时间。
+func visibilityTimeout(config Config) time.Duration {
+	switch v := config.Options["retry_after"].(type) {
+	case time.Duration:
+		if v > 0 {
+			return v
+		}
+	case int:
+		if v > 0 {
+			return time.Duration(v) * time.Second
+		}
+	}
+	return DefaultVisibilityTimeout
+}
+
 // JobOption represents job options
 type JobOption func(*jobOptions)
 
@@ -141,37 +173,88 @@ func New(config Config) (QueueInterface, error) {
 
 // Manager 队列管理器
 type Manager struct {
-	config Config
-	driver QueueInterface
+	config  Config
+	driver  QueueInterface
+	failed  FailedJobStore
+	batches BatchStore
+	// lock 唯一任务使用的锁
+	lock locker.Locker
+	// throttles 限流计数存储
+	throttles ThrottleStore
+	// monitor 任务统计、工作进程心跳和队列暂停状态
+	monitor MonitorStore
 }
 
 // NewManager 创建队列管理器
 func NewManager(config Config) (*Manager, error) {
-	var driver QueueInterface
-	var err error
-
-	switch config.Driver {
-	case "redis":
-		driver, err = NewRedisQueue(config)
-	case "database", "mysql":
-		driver, err = NewDatabaseQueue(config)
-	default:
-		return nil, ErrUnsupportedDriver
-	}
-
+	driver, err := New(config)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Manager{
+	manager := &Manager{
 		config: config,
 		driver: driver,
-	}, nil
+	}
+
+	// 驱动自带失败任务存储时默认启用
+	if provider, ok := driver.(failedJobProvider); ok {
+		manager.failed = provider.failedJobStore()
+	}
+	if provider, ok := driver.(batchStoreProvider); ok {
+		manager.batches = provider.batchStore()
+	}
+	if provider, ok := driver.(lockerProvider); ok {
+		manager.lock = provider.locker()
+	}
+	if provider, ok := driver.(throttleStoreProvider); ok {
+		manager.throttles = provider.throttleStore()
+	}
+	if provider, ok := driver.(monitorProvider); ok {
+		manager.monitor = provider.monitorStore()
+	}
+	if aware, ok := driver.(processorAware); ok {
+		aware.setProcessor(manager.processNow)
+	}
+
+	return manager, nil
+}
+
+// VisibilityTimeout 任务被取出后的保留时间，超时时间不小于该值的任务会在执行期间被
+// 重新取出。同步驱动推送时直接执行，不保留任务，返回0
+func (m *Manager) VisibilityTimeout() time.Duration {
+	if _, ok := m.driver.(processorAware); ok {
+		return 0
+	}
+	return visibilityTimeout(m.config)
+}
+
+// checkTimeout 检查任务显式设置的超时时间是否小于保留时间
+func (m *Manager) checkTimeout(job JobInterface) error {
+	visibility := m.VisibilityTimeout()
+	if timeout := job.GetTimeout(); visibility > 0 && timeout >= visibility {
+		return fmt.Errorf("%w: timeout %s, retry_after %s", ErrTimeoutExceedsRetryAfter, timeout, visibility)
+	}
+	return nil
 }
 
 // Push 推送任务
+//
+// 唯一任务已在等待或执行时丢弃本次推送并返回 ErrDuplicateJob，
+// 超时时间不小于保留时间的任务返回 ErrTimeoutExceedsRetryAfter。
 func (m *Manager) Push(ctx context.Context, job JobInterface) error {
-	return m.driver.Push(ctx, job)
+	if err := m.checkTimeout(job); err != nil {
+		return err
+	}
+	stampTraceID(ctx, job)
+	if err := m.acquireUniqueLock(ctx, job); err != nil {
+		return err
+	}
+	if err := m.driver.Push(ctx, job); err != nil {
+		m.releaseUniqueLock(ctx, job)
+		return err
+	}
+	return nil
 }
 
 // PushRaw 推送原始数据
@@ -181,7 +264,39 @@ func (m *Manager) PushRaw(ctx context.Context, queue string, payload []byte, opt
 
 // Later 延迟推送
 func (m *Manager) Later(ctx context.Context, job JobInterface, delay time.Duration) error {
-	return m.driver.Later(ctx, job, delay)
+	if err := m.checkTimeout(job); err != nil {
+		return err
+	}
+	stampTraceID(ctx, job)
+	if err := m.acquireUniqueLock(ctx, job); err != nil {
+		return err
+	}
+	if err := m.driver.Later(ctx, job, delay); err != nil {
+		m.releaseUniqueLock(ctx, job)
+		return err
+	}
+	return nil
+}
+
+// processNow 立即执行任务并按结果完成或记为失败，供同步驱动使用
+func (m *Manager) processNow(ctx context.Context, queue string, job JobInterface) error {
+	jobCtx := jobContext(ctx, job)
+	if timeout := job.GetTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(jobCtx, timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	err := Run(jobCtx, job)
+	m.recordJob(ctx, queue, time.Since(started), err != nil)
+	if err != nil {
+		if failErr := m.Fail(ctx, queue, job, err); failErr != nil {
+			return failErr
+		}
+		return err
+	}
+	return m.Complete(ctx, queue, job)
 }
 
 // Pop 取出任务
@@ -199,8 +314,11 @@ func (m *Manager) Delete(ctx context.Context, queue string, job JobInterface) er
 	return m.driver.Delete(ctx, queue, job)
 }
 
-// Release 释放任务
+// Release 释放任务，本次释放会用完重试次数的任务转入失败任务存储
 func (m *Manager) Release(ctx context.Context, queue string, job JobInterface, delay time.Duration) error {
+	if maxAttempts := job.GetMaxAttempts(); maxAttempts > 0 && job.GetAttempts()+1 >= maxAttempts {
+		return m.Fail(ctx, queue, job, ErrMaxAttemptsExceeded)
+	}
 	return m.driver.Release(ctx, queue, job, delay)
 }
 
@@ -0,0 +1,197 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisBatchKeyPrefix 批次数据 (hash)
+	redisBatchKeyPrefix = "queues:batches:"
+	// redisBatchIndexKey 批次索引 (zset: id -> created_at)
+	redisBatchIndexKey = "queues:batches"
+)
+
+// recordBatchResultScript 原子地更新批次计数，全部任务结束时记录结束时间
+//
+// KEYS[1] 批次数据
+// ARGV[1] 是否失败 (1/0)，ARGV[2] 当前时间
+var recordBatchResultScript = redis.NewScript(`
+if redis.call('exists', KEYS[1]) == 0 then
+	return false
+end
+
+if ARGV[1] == '1' then
+	redis.call('hincrby', KEYS[1], 'failed_jobs', 1)
+end
+
+local pending = redis.call('hincrby', KEYS[1], 'pending_jobs', -1)
+if pending <= 0 then
+	redis.call('hsetnx', KEYS[1], 'finished_at', ARGV[2])
+end
+
+return redis.call('hgetall', KEYS[1])
+`)
+
+// redisBatchData 批次中不会变化的字段
+type redisBatchData struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	TotalJobs int       `json:"total_jobs"`
+	Then      string    `json:"then"`
+	Catch     string    `json:"catch"`
+	Finally   string    `json:"finally"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RedisBatchStore Redis批次存储
+//
+// 批次的固定信息以JSON保存在 data 字段，计数器单独保存以便原子更新。
+type RedisBatchStore struct {
+	client *redis.Client
+}
+
+// NewRedisBatchStore 创建Redis批次存储
+func NewRedisBatchStore(client *redis.Client) *RedisBatchStore {
+	return &RedisBatchStore{client: client}
+}
+
+// batchKey 批次数据的键名
+func batchKey(id string) string {
+	return redisBatchKeyPrefix + id
+}
+
+// Store 保存新批次
+func (s *RedisBatchStore) Store(ctx context.Context, batch *Batch) error {
+	data, err := json.Marshal(redisBatchData{
+		ID:        batch.ID,
+		Name:      batch.Name,
+		TotalJobs: batch.TotalJobs,
+		Then:      batch.Then,
+		Catch:     batch.Catch,
+		Finally:   batch.Finally,
+		CreatedAt: batch.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, batchKey(batch.ID),
+		"data", data,
+		"pending_jobs", batch.PendingJobs,
+		"failed_jobs", batch.FailedJobs,
+	)
+	pipe.ZAdd(ctx, redisBatchIndexKey, redis.Z{
+		Score:  float64(batch.CreatedAt.UnixNano()),
+		Member: batch.ID,
+	})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Find 根据ID获取批次
+func (s *RedisBatchStore) Find(ctx context.Context, id string) (*Batch, error) {
+	fields, err := s.client.HGetAll(ctx, batchKey(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrBatchNotFound
+	}
+	return parseRedisBatch(fields)
+}
+
+// All 获取所有批次，按创建时间倒序
+func (s *RedisBatchStore) All(ctx context.Context) ([]*Batch, error) {
+	ids, err := s.client.ZRevRange(ctx, redisBatchIndexKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]*Batch, 0, len(ids))
+	for _, id := range ids {
+		batch, err := s.Find(ctx, id)
+		if errors.Is(err, ErrBatchNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// RecordResult 原子地记录一个任务结束
+func (s *RedisBatchStore) RecordResult(ctx context.Context, id string, failed bool) (*Batch, error) {
+	flag := "0"
+	if failed {
+		flag = "1"
+	}
+
+	values, err := recordBatchResultScript.Run(ctx, s.client, []string{batchKey(id)},
+		flag, time.Now().UnixNano(),
+	).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrBatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		fields[values[i]] = values[i+1]
+	}
+	return parseRedisBatch(fields)
+}
+
+// Delete 删除批次
+func (s *RedisBatchStore) Delete(ctx context.Context, id string) error {
+	pipe := s.client.TxPipeline()
+	deleted := pipe.Del(ctx, batchKey(id))
+	pipe.ZRem(ctx, redisBatchIndexKey, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if deleted.Val() == 0 {
+		return ErrBatchNotFound
+	}
+	return nil
+}
+
+// parseRedisBatch 将批次的哈希字段还原为批次
+func parseRedisBatch(fields map[string]string) (*Batch, error) {
+	var data redisBatchData
+	if err := json.Unmarshal([]byte(fields["data"]), &data); err != nil {
+		return nil, fmt.Errorf("invalid batch data: %v", err)
+	}
+
+	batch := &Batch{
+		ID:        data.ID,
+		Name:      data.Name,
+		TotalJobs: data.TotalJobs,
+		Then:      data.Then,
+		Catch:     data.Catch,
+		Finally:   data.Finally,
+		CreatedAt: data.CreatedAt,
+	}
+	batch.PendingJobs, _ = strconv.Atoi(fields["pending_jobs"])
+	batch.FailedJobs, _ = strconv.Atoi(fields["failed_jobs"])
+
+	if finished, ok := fields["finished_at"]; ok {
+		if nanos, err := strconv.ParseInt(finished, 10, 64); err == nil {
+			finishedAt := time.Unix(0, nanos)
+			batch.FinishedAt = &finishedAt
+		}
+	}
+
+	return batch, nil
+}
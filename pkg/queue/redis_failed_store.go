@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisFailedJobsKey 失败任务数据 (hash: id -> json)
+	redisFailedJobsKey = "queues:failed"
+	// redisFailedIndexKey 失败任务索引 (zset: id -> failed_at)
+	redisFailedIndexKey = "queues:failed:index"
+)
+
+// RedisFailedJobStore Redis失败任务存储
+type RedisFailedJobStore struct {
+	client *redis.Client
+}
+
+// NewRedisFailedJobStore 创建Redis失败任务存储
+func NewRedisFailedJobStore(client *redis.Client) *RedisFailedJobStore {
+	return &RedisFailedJobStore{client: client}
+}
+
+// Log 记录失败任务
+func (s *RedisFailedJobStore) Log(ctx context.Context, job *FailedJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, redisFailedJobsKey, job.ID, data)
+	pipe.ZAdd(ctx, redisFailedIndexKey, redis.Z{
+		Score:  float64(job.FailedAt.UnixNano()),
+		Member: job.ID,
+	})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// All 获取所有失败任务，按失败时间倒序
+func (s *RedisFailedJobStore) All(ctx context.Context) ([]*FailedJob, error) {
+	ids, err := s.client.ZRevRange(ctx, redisFailedIndexKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []*FailedJob{}, nil
+	}
+
+	values, err := s.client.HMGet(ctx, redisFailedJobsKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*FailedJob, 0, len(values))
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var job FailedJob
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, nil
+}
+
+// Find 根据ID获取失败任务
+func (s *RedisFailedJobStore) Find(ctx context.Context, id string) (*FailedJob, error) {
+	data, err := s.client.HGet(ctx, redisFailedJobsKey, id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrFailedJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job FailedJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Forget 删除失败任务
+func (s *RedisFailedJobStore) Forget(ctx context.Context, id string) error {
+	pipe := s.client.TxPipeline()
+	deleted := pipe.HDel(ctx, redisFailedJobsKey, id)
+	pipe.ZRem(ctx, redisFailedIndexKey, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+	if deleted.Val() == 0 {
+		return ErrFailedJobNotFound
+	}
+	return nil
+}
+
+// Flush 清空所有失败任务
+func (s *RedisFailedJobStore) Flush(ctx context.Context) error {
+	return s.client.Del(ctx, redisFailedJobsKey, redisFailedIndexKey).Err()
+}
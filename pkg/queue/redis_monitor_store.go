@@ -0,0 +1,158 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisMetricsKeyPrefix 每分钟的任务处理统计 (hash: processed, failed, runtime_us)
+	redisMetricsKeyPrefix = "queues:metrics:"
+	// redisWorkersKey 工作进程心跳 (hash: id -> json)
+	redisWorkersKey = "queues:workers"
+	// redisPausedKey 已暂停的队列 (set)
+	redisPausedKey = "queues:paused"
+)
+
+// RedisMonitorStore Redis监控存储
+//
+// 任务统计按分钟分桶保存，过期时间为 MetricsRetention。
+type RedisMonitorStore struct {
+	client *redis.Client
+}
+
+// NewRedisMonitorStore 创建Redis监控存储
+func NewRedisMonitorStore(client *redis.Client) *RedisMonitorStore {
+	return &RedisMonitorStore{client: client}
+}
+
+// metricsKey 队列某一分钟的统计键名
+func metricsKey(queue string, minute int64) string {
+	return fmt.Sprintf("%s%s:%d", redisMetricsKeyPrefix, queue, minute)
+}
+
+// RecordJob 记录一次任务执行
+func (s *RedisMonitorStore) RecordJob(ctx context.Context, queue string, runtime time.Duration, failed bool) error {
+	key := metricsKey(queue, time.Now().Truncate(time.Minute).Unix())
+
+	pipe := s.client.TxPipeline()
+	pipe.HIncrBy(ctx, key, "processed", 1)
+	pipe.HIncrBy(ctx, key, "runtime_us", runtime.Microseconds())
+	if failed {
+		pipe.HIncrBy(ctx, key, "failed", 1)
+	}
+	pipe.Expire(ctx, key, MetricsRetention)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Metrics 获取队列自 since 以来的任务处理统计
+func (s *RedisMonitorStore) Metrics(ctx context.Context, queue string, since time.Time) (JobMetrics, error) {
+	var metrics JobMetrics
+
+	now := time.Now()
+	if since.Before(now.Add(-MetricsRetention)) {
+		since = now.Add(-MetricsRetention)
+	}
+
+	pipe := s.client.Pipeline()
+	var results []*redis.SliceCmd
+	for minute := since.Truncate(time.Minute); !minute.After(now); minute = minute.Add(time.Minute) {
+		results = append(results, pipe.HMGet(ctx, metricsKey(queue, minute.Unix()), "processed", "failed", "runtime_us"))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return metrics, err
+	}
+
+	for _, result := range results {
+		values := result.Val()
+		if len(values) != 3 {
+			continue
+		}
+		metrics.Processed += redisInt(values[0])
+		metrics.Failed += redisInt(values[1])
+		metrics.Runtime += time.Duration(redisInt(values[2])) * time.Microsecond
+	}
+	return metrics, nil
+}
+
+// redisInt 将 HMGET 返回的值转换为整数，不存在的字段为0
+func redisInt(value interface{}) int64 {
+	str, ok := value.(string)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.ParseInt(str, 10, 64)
+	return n
+}
+
+// Heartbeat 上报工作进程心跳
+func (s *RedisMonitorStore) Heartbeat(ctx context.Context, heartbeat *WorkerHeartbeat) error {
+	data, err := json.Marshal(heartbeat)
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, redisWorkersKey, heartbeat.ID, data).Err()
+}
+
+// Heartbeats 获取未过期的工作进程心跳，并清理已过期的心跳
+func (s *RedisMonitorStore) Heartbeats(ctx context.Context) ([]*WorkerHeartbeat, error) {
+	values, err := s.client.HGetAll(ctx, redisWorkersKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	expired := time.Now().Add(-HeartbeatTTL)
+	heartbeats := make([]*WorkerHeartbeat, 0, len(values))
+	var stale []string
+	for id, data := range values {
+		var heartbeat WorkerHeartbeat
+		if err := json.Unmarshal([]byte(data), &heartbeat); err != nil || heartbeat.LastSeen.Before(expired) {
+			stale = append(stale, id)
+			continue
+		}
+		heartbeats = append(heartbeats, &heartbeat)
+	}
+
+	if len(stale) > 0 {
+		if err := s.client.HDel(ctx, redisWorkersKey, stale...).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(heartbeats, func(i, j int) bool {
+		return heartbeats[i].StartedAt.Before(heartbeats[j].StartedAt)
+	})
+	return heartbeats, nil
+}
+
+// RemoveHeartbeat 移除工作进程心跳
+func (s *RedisMonitorStore) RemoveHeartbeat(ctx context.Context, id string) error {
+	return s.client.HDel(ctx, redisWorkersKey, id).Err()
+}
+
+// Pause 暂停队列
+func (s *RedisMonitorStore) Pause(ctx context.Context, queue string) error {
+	return s.client.SAdd(ctx, redisPausedKey, queue).Err()
+}
+
+// Resume 恢复队列
+func (s *RedisMonitorStore) Resume(ctx context.Context, queue string) error {
+	return s.client.SRem(ctx, redisPausedKey, queue).Err()
+}
+
+// PausedQueues 获取已暂停的队列
+func (s *RedisMonitorStore) PausedQueues(ctx context.Context) ([]string, error) {
+	queues, err := s.client.SMembers(ctx, redisPausedKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(queues)
+	return queues, nil
+}
@@ -2,17 +2,97 @@ package queue
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
+	"app/pkg/locker"
+
 	"github.com/redis/go-redis/v9"
 )
 
+func init() {
+	Register("redis", func(config Config) (QueueInterface, error) {
+		return NewRedisQueue(config)
+	})
+}
+
+var (
+	// popScript 将到期的延迟任务移入主队列，取出一个任务并记入保留集合
+	//
+	// KEYS[1] 主队列，KEYS[2] 延迟队列，KEYS[3] 保留集合
+	// ARGV[1] 当前时间，ARGV[2] 保留到期时间
+	popScript = redis.NewScript(`
+local due = redis.call('zrangebyscore', KEYS[2], '-inf', ARGV[1])
+if #due > 0 then
+	redis.call('zremrangebyscore', KEYS[2], '-inf', ARGV[1])
+	for i = #due, 1, -1 do
+		redis.call('rpush', KEYS[1], due[i])
+	end
+end
+
+local job = redis.call('rpop', KEYS[1])
+if not job then
+	return false
+end
+
+redis.call('zadd', KEYS[3], ARGV[2], job)
+return job
+`)
+
+	// deleteScript 从保留集合、延迟队列和主队列中删除任务
+	//
+	// KEYS[1] 保留集合，KEYS[2] 延迟队列，KEYS[3] 主队列
+	// ARGV[1] 任务数据
+	deleteScript = redis.NewScript(`
+local removed = redis.call('zrem', KEYS[1], ARGV[1])
+removed = removed + redis.call('zrem', KEYS[2], ARGV[1])
+removed = removed + redis.call('lrem', KEYS[3], 0, ARGV[1])
+return removed
+`)
+
+	// releaseScript 将保留中的任务移入延迟队列
+	//
+	// 保留已过期并被重新入队的任务不会再次加入，避免重复执行。
+	// KEYS[1] 保留集合，KEYS[2] 延迟队列
+	// ARGV[1] 保留时的任务数据，ARGV[2] 新的任务数据，ARGV[3] 执行时间
+	releaseScript = redis.NewScript(`
+if ARGV[1] ~= '' and redis.call('zrem', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+
+redis.call('zadd', KEYS[2], ARGV[3], ARGV[2])
+return 1
+`)
+
+	// requeueScript 将保留过期的任务放回主队列
+	//
+	// KEYS[1] 保留集合，KEYS[2] 主队列
+	// ARGV[1] 保留时的任务数据，ARGV[2] 新的任务数据
+	requeueScript = redis.NewScript(`
+if redis.call('zrem', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+
+redis.call('rpush', KEYS[2], ARGV[2])
+return 1
+`)
+)
+
 // RedisQueue Redis队列驱动
+//
+// 取出的任务记入 queues:<name>:reserved 保留集合，分数为保留到期时间。
+// 工作进程崩溃等原因导致保留过期的任务会在下次取任务时重新入队，并增加重试次数。
 type RedisQueue struct {
-	client *redis.Client
-	config Config
+	client  *redis.Client
+	config  Config
+	failed  *RedisFailedJobStore
+	batches *RedisBatchStore
+	lock    *locker.RedisLocker
+	monitor *RedisMonitorStore
+	// throttles 限流计数存储
+	throttles *RedisThrottleStore
+	// visibility 任务被取出后的保留时间
+	visibility time.Duration
 }
 
 // NewRedisQueue 创建Redis队列驱动
@@ -39,14 +119,45 @@ func NewRedisQueue(config Config) (*RedisQueue, error) {
 	}
 
 	return &RedisQueue{
-		client: client,
-		config: config,
+		client:     client,
+		config:     config,
+		failed:     NewRedisFailedJobStore(client),
+		batches:    NewRedisBatchStore(client),
+		lock:       locker.NewRedisLocker(client),
+		monitor:    NewRedisMonitorStore(client),
+		throttles:  NewRedisThrottleStore(client),
+		visibility: visibilityTimeout(config),
 	}, nil
 }
 
+// failedJobStore 返回共用连接的失败任务存储
+func (q *RedisQueue) failedJobStore() FailedJobStore {
+	return q.failed
+}
+
+// batchStore 返回共用连接的批次存储
+func (q *RedisQueue) batchStore() BatchStore {
+	return q.batches
+}
+
+// locker 返回共用连接的锁
+func (q *RedisQueue) locker() locker.Locker {
+	return q.lock
+}
+
+// monitorStore 返回共用连接的监控存储
+func (q *RedisQueue) monitorStore() MonitorStore {
+	return q.monitor
+}
+
+// throttleStore 返回共用连接的限流存储
+func (q *RedisQueue) throttleStore() ThrottleStore {
+	return q.throttles
+}
+
 // Push 推送任务到队列
 func (q *RedisQueue) Push(ctx context.Context, job JobInterface) error {
-	payload, err := json.Marshal(job)
+	payload, err := encodeJob(job)
 	if err != nil {
 		return err
 	}
@@ -101,7 +212,7 @@ func (q *RedisQueue) PushRaw(ctx context.Context, queue string, payload []byte,
 
 // Later 延迟推送任务
 func (q *RedisQueue) Later(ctx context.Context, job JobInterface, delay time.Duration) error {
-	payload, err := json.Marshal(job)
+	payload, err := encodeJob(job)
 	if err != nil {
 		return err
 	}
@@ -132,36 +243,20 @@ func (q *RedisQueue) Pop(ctx context.Context, queue string) (JobInterface, error
 		}
 	}
 
-	// 检查延迟队列
-	now := float64(time.Now().Unix())
-	delayedKey := fmt.Sprintf("queues:%s:delayed", queue)
-
-	// 获取到期的延迟任务
-	results, err := q.client.ZRangeByScore(ctx, delayedKey, &redis.ZRangeBy{
-		Min:    "0",
-		Max:    fmt.Sprintf("%f", now),
-		Offset: 0,
-		Count:  1,
-	}).Result()
-
-	if err != nil {
+	// 重新入队保留过期的任务
+	if _, err := q.ReapExpired(ctx, queue); err != nil {
 		return nil, err
 	}
 
-	// 如果有到期的延迟任务，移除并返回
-	if len(results) > 0 {
-		payload := results[0]
-		q.client.ZRem(ctx, delayedKey, payload)
-
-		var job BaseJob
-		if err := json.Unmarshal([]byte(payload), &job); err != nil {
-			return nil, err
-		}
-		return &job, nil
-	}
-
-	// 从主队列获取任务
-	payload, err := q.client.RPop(ctx, fmt.Sprintf("queues:%s", queue)).Bytes()
+	now := time.Now()
+	payload, err := popScript.Run(ctx, q.client,
+		[]string{
+			fmt.Sprintf("queues:%s", queue),
+			fmt.Sprintf("queues:%s:delayed", queue),
+			fmt.Sprintf("queues:%s:reserved", queue),
+		},
+		now.Unix(), now.Add(q.visibility).Unix(),
+	).Text()
 	if err == redis.Nil {
 		return nil, ErrQueueEmpty
 	}
@@ -169,12 +264,51 @@ func (q *RedisQueue) Pop(ctx context.Context, queue string) (JobInterface, error
 		return nil, err
 	}
 
-	var job BaseJob
-	if err := json.Unmarshal(payload, &job); err != nil {
-		return nil, err
+	job := decodeJob([]byte(payload))
+	if r, ok := job.(reservable); ok {
+		r.setReservation([]byte(payload))
 	}
+	reservedAt := now
+	job.SetReservedAt(&reservedAt)
 
-	return &job, nil
+	return job, nil
+}
+
+// ReapExpired 将保留过期的任务放回主队列并增加重试次数，返回重新入队的数量
+func (q *RedisQueue) ReapExpired(ctx context.Context, queue string) (int, error) {
+	reservedKey := fmt.Sprintf("queues:%s:reserved", queue)
+
+	expired, err := q.client.ZRangeByScore(ctx, reservedKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", time.Now().Unix()),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, payload := range expired {
+		// 增加重试次数，无法识别的任务保持原始数据
+		job := decodeJob([]byte(payload))
+		job.SetAttempts(job.GetAttempts() + 1)
+		job.SetReservedAt(nil)
+		updated, err := encodeJob(job)
+		if err != nil {
+			updated = []byte(payload)
+		}
+
+		// 任务可能已被处理完成或被其他进程重新入队
+		n, err := requeueScript.Run(ctx, q.client,
+			[]string{reservedKey, fmt.Sprintf("queues:%s", queue)},
+			payload, updated,
+		).Int()
+		if err != nil {
+			return requeued, err
+		}
+		requeued += n
+	}
+
+	return requeued, nil
 }
 
 // Size 获取队列大小
@@ -199,7 +333,38 @@ func (q *RedisQueue) Size(ctx context.Context, queue string) (int64, error) {
 		return 0, err
 	}
 
-	return size + delayedSize, nil
+	// 获取保留中的任务数量
+	reservedSize, err := q.client.ZCard(ctx, fmt.Sprintf("queues:%s:reserved", queue)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return size + delayedSize + reservedSize, nil
+}
+
+// Counts 按状态统计队列中的任务
+func (q *RedisQueue) Counts(ctx context.Context, queue string) (QueueCounts, error) {
+	if queue == "" {
+		if queueOpt, ok := q.config.Options["queue"].(string); ok {
+			queue = queueOpt
+		} else {
+			return QueueCounts{}, fmt.Errorf("queue name not found in options")
+		}
+	}
+
+	pipe := q.client.Pipeline()
+	pending := pipe.LLen(ctx, fmt.Sprintf("queues:%s", queue))
+	delayed := pipe.ZCard(ctx, fmt.Sprintf("queues:%s:delayed", queue))
+	reserved := pipe.ZCard(ctx, fmt.Sprintf("queues:%s:reserved", queue))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return QueueCounts{}, err
+	}
+
+	return QueueCounts{
+		Pending:  pending.Val(),
+		Delayed:  delayed.Val(),
+		Reserved: reserved.Val(),
+	}, nil
 }
 
 // Delete 删除任务
@@ -212,32 +377,27 @@ func (q *RedisQueue) Delete(ctx context.Context, queue string, job JobInterface)
 		}
 	}
 
-	payload, err := json.Marshal(job)
+	payload, err := q.payloadOf(job)
 	if err != nil {
 		return err
 	}
 
-	// 从延迟队列中删除
-	delayedKey := fmt.Sprintf("queues:%s:delayed", queue)
-	q.client.ZRem(ctx, delayedKey, payload)
-
-	// 从主队列中删除
-	// 注意：Redis的List不支持直接删除指定元素，这里需要遍历
-	// 实际应用中可能需要更复杂的实现
-	key := fmt.Sprintf("queues:%s", queue)
-	items, err := q.client.LRange(ctx, key, 0, -1).Result()
-	if err != nil {
-		return err
-	}
+	return deleteScript.Run(ctx, q.client,
+		[]string{
+			fmt.Sprintf("queues:%s:reserved", queue),
+			fmt.Sprintf("queues:%s:delayed", queue),
+			fmt.Sprintf("queues:%s", queue),
+		},
+		payload,
+	).Err()
+}
 
-	pipe := q.client.Pipeline()
-	for _, item := range items {
-		if item == string(payload) {
-			pipe.LRem(ctx, key, 1, item)
-		}
+// payloadOf 返回任务在队列中的数据，取出的任务使用保留时的原始数据
+func (q *RedisQueue) payloadOf(job JobInterface) ([]byte, error) {
+	if r, ok := job.(reservable); ok && len(r.reservation()) > 0 {
+		return r.reservation(), nil
 	}
-	_, err = pipe.Exec(ctx)
-	return err
+	return encodeJob(job)
 }
 
 // Release 释放任务回队列
@@ -250,14 +410,15 @@ func (q *RedisQueue) Release(ctx context.Context, queue string, job JobInterface
 		}
 	}
 
+	// 保留时的原始数据，需在修改任务前获取
+	var reserved []byte
+	if r, ok := job.(reservable); ok {
+		reserved = r.reservation()
+	}
+
 	// 增加重试次数
 	job.SetAttempts(job.GetAttempts() + 1)
 
-	// 如果超过最大重试次数，直接删除
-	if job.GetAttempts() >= job.GetMaxAttempts() {
-		return q.Delete(ctx, queue, job)
-	}
-
 	// 计算新的延迟时间
 	newDelay := delay
 	if newDelay == 0 {
@@ -275,8 +436,28 @@ func (q *RedisQueue) Release(ctx context.Context, queue string, job JobInterface
 		}
 	}
 
-	// 重新入队
-	return q.Later(ctx, job, newDelay)
+	job.SetReservedAt(nil)
+	payload, err := encodeJob(job)
+	if err != nil {
+		return err
+	}
+
+	// 从保留集合移入延迟队列
+	err = releaseScript.Run(ctx, q.client,
+		[]string{
+			fmt.Sprintf("queues:%s:reserved", queue),
+			fmt.Sprintf("queues:%s:delayed", queue),
+		},
+		reserved, payload, time.Now().Add(newDelay).Unix(),
+	).Err()
+	if err != nil {
+		return err
+	}
+
+	if r, ok := job.(reservable); ok {
+		r.setReservation(nil)
+	}
+	return nil
 }
 
 // Clear 清空队列
@@ -292,6 +473,7 @@ func (q *RedisQueue) Clear(ctx context.Context, queue string) error {
 	pipe := q.client.Pipeline()
 	pipe.Del(ctx, fmt.Sprintf("queues:%s", queue))
 	pipe.Del(ctx, fmt.Sprintf("queues:%s:delayed", queue))
+	pipe.Del(ctx, fmt.Sprintf("queues:%s:reserved", queue))
 	_, err := pipe.Exec(ctx)
 	return err
 }
@@ -0,0 +1,62 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// acquireThrottleScript 计数加一，周期内第一个任务设置过期时间，超过上限时撤销
+	//
+	// KEYS[1] 计数器
+	// ARGV[1] 上限，ARGV[2] 周期(毫秒)
+	acquireThrottleScript = redis.NewScript(`
+local count = redis.call('incr', KEYS[1])
+if count == 1 then
+	redis.call('pexpire', KEYS[1], ARGV[2])
+end
+
+if count > tonumber(ARGV[1]) then
+	redis.call('decr', KEYS[1])
+	return 0
+end
+return 1
+`)
+
+	// returnThrottleScript 计数减一，计数器已过期时不做处理
+	//
+	// KEYS[1] 计数器
+	returnThrottleScript = redis.NewScript(`
+local count = tonumber(redis.call('get', KEYS[1]) or '0')
+if count > 0 then
+	redis.call('decr', KEYS[1])
+end
+return count
+`)
+)
+
+// RedisThrottleStore Redis限流存储，每个限流键一个带过期时间的计数器
+type RedisThrottleStore struct {
+	client *redis.Client
+}
+
+// NewRedisThrottleStore 创建Redis限流存储
+func NewRedisThrottleStore(client *redis.Client) *RedisThrottleStore {
+	return &RedisThrottleStore{client: client}
+}
+
+// Acquire 占用一个额度
+func (s *RedisThrottleStore) Acquire(ctx context.Context, key string, limit int, per time.Duration) (bool, error) {
+	acquired, err := acquireThrottleScript.Run(ctx, s.client, []string{key}, limit, per.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return acquired == 1, nil
+}
+
+// Return 归还一个额度
+func (s *RedisThrottleStore) Return(ctx context.Context, key string) error {
+	return returnThrottleScript.Run(ctx, s.client, []string{key}).Err()
+}
@@ -0,0 +1,152 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// ErrUnknownJob 未注册的任务类型
+	ErrUnknownJob = errors.New("unknown job type")
+	// ErrJobNotRegistered 推送的任务类型未注册
+	ErrJobNotRegistered = errors.New("job type not registered")
+)
+
+// JobFactory 创建一个空的任务实例，用于反序列化
+type JobFactory func() JobInterface
+
+var (
+	jobsMu    sync.RWMutex
+	factories = make(map[string]JobFactory)
+	jobNames  = make(map[reflect.Type]string)
+)
+
+// RegisterJob 以稳定的名称注册任务类型
+//
+// 名称会写入队列中的任务数据，Pop 时据此还原具体的任务类型，
+// 因此注册后不应再修改。
+func RegisterJob(name string, factory JobFactory) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	if name == "" {
+		panic("queue: job name cannot be empty")
+	}
+	if _, exists := factories[name]; exists {
+		panic(fmt.Sprintf("queue: job %s already registered", name))
+	}
+
+	factories[name] = factory
+	jobNames[reflect.TypeOf(factory())] = name
+}
+
+// JobName 返回任务类型注册时使用的名称
+func JobName(job JobInterface) (string, bool) {
+	if unknown, ok := job.(*UnknownJob); ok {
+		return unknown.Name, unknown.Name != ""
+	}
+
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+
+	name, ok := jobNames[reflect.TypeOf(job)]
+	return name, ok
+}
+
+// RegisteredJobs 返回所有已注册的任务名称
+func RegisteredJobs() []string {
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	return names
+}
+
+// envelope 队列中保存的任务数据结构
+type envelope struct {
+	Job  string          `json:"job"`
+	Data json.RawMessage `json:"data"`
+}
+
+// encodeJob 将任务及其注册名称编码为队列数据
+func encodeJob(job JobInterface) ([]byte, error) {
+	if unknown, ok := job.(*UnknownJob); ok && len(unknown.Raw) > 0 {
+		return unknown.Raw, nil
+	}
+
+	name, ok := JobName(job)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrJobNotRegistered, job)
+	}
+
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(envelope{Job: name, Data: data})
+}
+
+// decodeJob 根据队列数据还原具体的任务类型
+//
+// 无法识别的数据不会返回错误，而是返回 UnknownJob，
+// 由 Worker 将其转入失败处理流程，避免任务被静默丢弃。
+func decodeJob(payload []byte) JobInterface {
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil || env.Job == "" {
+		return newUnknownJob("", payload, payload)
+	}
+
+	jobsMu.RLock()
+	factory, ok := factories[env.Job]
+	jobsMu.RUnlock()
+	if !ok {
+		return newUnknownJob(env.Job, env.Data, payload)
+	}
+
+	job := factory()
+	if err := json.Unmarshal(env.Data, job); err != nil {
+		unknown := newUnknownJob(env.Job, env.Data, payload)
+		unknown.Reason = fmt.Errorf("%w: %s: %v", ErrInvalidPayload, env.Job, err)
+		return unknown
+	}
+
+	return job
+}
+
+// UnknownJob 无法还原为已注册类型的任务
+//
+// 包括未注册的任务名称、缺少名称的旧数据以及无法反序列化的数据。
+type UnknownJob struct {
+	BaseJob
+	Name   string          `json:"-"`
+	Raw    json.RawMessage `json:"-"`
+	Reason error           `json:"-"`
+}
+
+// newUnknownJob 创建未知任务，尽量保留原始的队列信息
+func newUnknownJob(name string, data, raw []byte) *UnknownJob {
+	job := &UnknownJob{
+		Name: name,
+		Raw:  append(json.RawMessage(nil), raw...),
+	}
+	_ = json.Unmarshal(data, &job.BaseJob)
+	return job
+}
+
+// Handle 未知任务无法执行，始终返回错误
+func (j *UnknownJob) Handle() error {
+	if j.Reason != nil {
+		return j.Reason
+	}
+	if j.Name == "" {
+		return fmt.Errorf("%w: payload has no job name", ErrUnknownJob)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownJob, j.Name)
+}
@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type registryTestJob struct {
+	BaseJob
+	Message string `json:"message"`
+	handled bool
+}
+
+func (j *registryTestJob) Handle() error {
+	j.handled = true
+	return nil
+}
+
+func init() {
+	RegisterJob("registry_test", func() JobInterface { return &registryTestJob{} })
+}
+
+func TestEncodeDecodeRegisteredJob(t *testing.T) {
+	job := &registryTestJob{BaseJob: BaseJob{Queue: "default", MaxAttempts: 3}, Message: "hello"}
+
+	payload, err := encodeJob(job)
+	require.NoError(t, err)
+
+	decoded := decodeJob(payload)
+	concrete, ok := decoded.(*registryTestJob)
+	require.True(t, ok, "expected *registryTestJob, got %T", decoded)
+	assert.Equal(t, "hello", concrete.Message)
+	assert.Equal(t, "default", concrete.GetQueue())
+	assert.Equal(t, 3, concrete.GetMaxAttempts())
+
+	require.NoError(t, concrete.Handle())
+	assert.True(t, concrete.handled)
+}
+
+func TestEncodeUnregisteredJob(t *testing.T) {
+	_, err := encodeJob(&BaseJob{Queue: "default"})
+	assert.True(t, errors.Is(err, ErrJobNotRegistered))
+}
+
+func TestDecodeUnknownJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr error
+	}{
+		{"unregistered name", `{"job":"missing","data":{"queue":"low"}}`, ErrUnknownJob},
+		{"legacy payload", `{"queue":"low","attempts":1}`, ErrUnknownJob},
+		{"not json", `garbage`, ErrUnknownJob},
+		{"bad data", `{"job":"registry_test","data":{"message":1}}`, ErrInvalidPayload},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := decodeJob([]byte(tt.payload))
+			unknown, ok := job.(*UnknownJob)
+			require.True(t, ok, "expected *UnknownJob, got %T", job)
+			assert.True(t, errors.Is(unknown.Handle(), tt.wantErr))
+
+			// 原始数据必须原样保留，便于失败后排查或重试
+			raw, err := encodeJob(unknown)
+			require.NoError(t, err)
+			assert.Equal(t, tt.payload, string(raw))
+		})
+	}
+}
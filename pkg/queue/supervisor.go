@@ -0,0 +1,232 @@
+package queue
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// QueueOptions 单个队列的配置
+type QueueOptions struct {
+	// Name 队列名称
+	Name string
+	// Priority 优先级，数字越大优先级越高，按权重轮询时作为权重
+	Priority int
+	// Processes 工作进程数
+	Processes int
+	// Timeout 任务超时时间
+	Timeout time.Duration
+	// Tries 任务最大重试次数
+	Tries int
+	// RetryAfter 任务未设置退避策略时的重试等待时间
+	RetryAfter time.Duration
+	// Backoff 任务未设置退避策略时使用的退避策略
+	Backoff []time.Duration
+	// Throttle 队列限流，Limit 为0时不限流
+	Throttle Throttle
+}
+
+// QueueOptions 返回队列生效的配置，未单独配置的项使用工作进程的默认值
+func (w *Worker) QueueOptions(queue string) QueueOptions {
+	return w.options.forQueue(queue)
+}
+
+// forQueue 合并队列配置与默认值
+func (o WorkerOptions) forQueue(queue string) QueueOptions {
+	options := o.Queues[queue]
+	options.Name = queue
+	if options.Processes <= 0 {
+		options.Processes = 1
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = o.Timeout
+	}
+	if options.Tries <= 0 {
+		options.Tries = o.Tries
+	}
+	return options
+}
+
+// sortByPriority 按优先级从高到低排序，优先级相同时保持原有顺序
+func sortByPriority(queues []string, options map[string]QueueOptions) []string {
+	sorted := append([]string(nil), queues...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return options[sorted[i]].Priority > options[sorted[j]].Priority
+	})
+	return sorted
+}
+
+// pollOrder 返回本轮取任务时的队列顺序
+func (w *Worker) pollOrder() []string {
+	if w.options.Balance != BalanceWeight || len(w.queues) < 2 {
+		return w.queues
+	}
+	return weightedOrder(w.queues, w.options.Queues)
+}
+
+// weightedOrder 按权重随机排列队列，权重越大越可能排在前面
+func weightedOrder(queues []string, options map[string]QueueOptions) []string {
+	remaining := append([]string(nil), queues...)
+	order := make([]string, 0, len(queues))
+
+	for len(remaining) > 0 {
+		total := 0
+		for _, queue := range remaining {
+			total += queueWeight(options[queue])
+		}
+
+		pick := rand.Intn(total)
+		for i, queue := range remaining {
+			pick -= queueWeight(options[queue])
+			if pick < 0 {
+				order = append(order, queue)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return order
+}
+
+// queueWeight 未设置优先级的队列权重为1
+func queueWeight(options QueueOptions) int {
+	if options.Priority > 0 {
+		return options.Priority
+	}
+	return 1
+}
+
+// Supervisor 按队列配置管理工作进程
+//
+// 每个队列按配置的数量启动工作进程，所有工作进程按轮询策略（优先级或权重）
+// 监听全部队列，空闲的工作进程会处理其他队列的任务。任务使用其所在队列的
+// 超时、重试和退避设置。
+type Supervisor struct {
+	manager *Manager
+	options WorkerOptions
+	mu      sync.RWMutex
+	workers map[string]*Worker
+	wg      sync.WaitGroup
+}
+
+// NewSupervisor 创建工作进程管理器，options.Queues 中的每个队列都会启动工作进程
+func NewSupervisor(manager *Manager, options WorkerOptions) *Supervisor {
+	return &Supervisor{
+		manager: manager,
+		options: options.withDefaults(),
+		workers: make(map[string]*Worker),
+	}
+}
+
+// Start 为每个队列启动工作进程，不阻塞
+func (s *Supervisor) Start() error {
+	if len(s.options.Queues) == 0 {
+		return fmt.Errorf("no queues configured")
+	}
+
+	settings := s.Settings()
+	if err := s.checkTimeouts(settings); err != nil {
+		return err
+	}
+	queues := make([]string, 0, len(settings))
+	for _, options := range settings {
+		queues = append(queues, options.Name)
+	}
+
+	for _, settings := range settings {
+		for i := 0; i < settings.Processes; i++ {
+			worker := NewWorker(s.manager, queues, s.options)
+			name := fmt.Sprintf("%s-%d", settings.Name, i+1)
+
+			s.mu.Lock()
+			s.workers[name] = worker
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func(w *Worker, name string) {
+				defer s.wg.Done()
+
+				log.Printf("Starting queue worker: %s", name)
+				w.Run()
+				log.Printf("Queue worker stopped: %s", name)
+
+				s.mu.Lock()
+				delete(s.workers, name)
+				s.mu.Unlock()
+			}(worker, name)
+		}
+	}
+
+	return nil
+}
+
+// checkTimeouts 队列超时时间加上取消等待时间必须小于保留时间，否则任务执行期间
+// 保留过期，会被重新取出重复执行
+func (s *Supervisor) checkTimeouts(settings []QueueOptions) error {
+	visibility := s.manager.VisibilityTimeout()
+	if visibility <= 0 {
+		return nil
+	}
+	for _, options := range settings {
+		if longest := options.Timeout + s.options.CancelGrace; longest >= visibility {
+			return fmt.Errorf("%w: queue %s timeout %s plus cancel grace %s, retry_after %s",
+				ErrTimeoutExceedsRetryAfter, options.Name, options.Timeout, s.options.CancelGrace, visibility)
+		}
+	}
+	return nil
+}
+
+// Stop 停止所有工作进程并等待其退出
+func (s *Supervisor) Stop() {
+	s.mu.RLock()
+	workers := make([]*Worker, 0, len(s.workers))
+	for _, worker := range s.workers {
+		workers = append(workers, worker)
+	}
+	s.mu.RUnlock()
+
+	for _, worker := range workers {
+		worker.Stop()
+	}
+	s.wg.Wait()
+}
+
+// Settings 返回各队列生效的配置，按优先级从高到低排列
+func (s *Supervisor) Settings() []QueueOptions {
+	names := make([]string, 0, len(s.options.Queues))
+	for name := range s.options.Queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	settings := make([]QueueOptions, 0, len(names))
+	for _, name := range sortByPriority(names, s.options.Queues) {
+		settings = append(settings, s.options.forQueue(name))
+	}
+	return settings
+}
+
+// Workers 返回监听各队列的正在运行的工作进程数
+func (s *Supervisor) Workers() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, worker := range s.workers {
+		for _, queue := range worker.queues {
+			counts[queue]++
+		}
+	}
+	return counts
+}
+
+// WorkerCount 返回正在运行的工作进程总数
+func (s *Supervisor) WorkerCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.workers)
+}
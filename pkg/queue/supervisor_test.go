@@ -0,0 +1,109 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisorSettings(t *testing.T) {
+	supervisor := NewSupervisor(nil, WorkerOptions{
+		Tries:   3,
+		Timeout: time.Minute,
+		Queues: map[string]QueueOptions{
+			"low":     {Priority: 1},
+			"high":    {Priority: 5, Processes: 2, Timeout: 10 * time.Second, Tries: 5},
+			"default": {Priority: 3, Backoff: []time.Duration{time.Second}},
+		},
+	})
+
+	settings := supervisor.Settings()
+	names := make([]string, 0, len(settings))
+	for _, s := range settings {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"high", "default", "low"}, names)
+
+	// 队列未配置的项回退到工作进程的默认值
+	assert.Equal(t, QueueOptions{Name: "high", Priority: 5, Processes: 2, Timeout: 10 * time.Second, Tries: 5}, settings[0])
+	assert.Equal(t, time.Minute, settings[2].Timeout)
+	assert.Equal(t, 3, settings[2].Tries)
+	assert.Equal(t, 1, settings[2].Processes)
+}
+
+func TestWorkerBackoffPrecedence(t *testing.T) {
+	worker := NewWorker(nil, []string{"default"}, WorkerOptions{
+		Queues: map[string]QueueOptions{
+			"default": {Backoff: []time.Duration{time.Second, 2 * time.Second}, RetryAfter: 5 * time.Second},
+		},
+	})
+	options := worker.QueueOptions("default")
+
+	// 任务自身的退避策略优先
+	job := &BaseJob{Backoff: []time.Duration{time.Minute}}
+	assert.Equal(t, time.Minute, worker.calculateBackoff(job, options))
+
+	// 未设置时使用队列配置
+	job = &BaseJob{Attempts: 1}
+	assert.Equal(t, 2*time.Second, worker.calculateBackoff(job, options))
+}
+
+func TestWeightedOrderContainsEveryQueue(t *testing.T) {
+	options := map[string]QueueOptions{"high": {Priority: 10}, "low": {Priority: 1}}
+
+	first := map[string]int{}
+	for i := 0; i < 200; i++ {
+		order := weightedOrder([]string{"low", "high"}, options)
+		assert.ElementsMatch(t, []string{"low", "high"}, order)
+		first[order[0]]++
+	}
+	assert.Greater(t, first["high"], first["low"])
+}
+
+func TestSupervisorWorkersListenOnEveryQueue(t *testing.T) {
+	m, err := NewManager(Config{Driver: "memory", Options: map[string]interface{}{"queue": "default"}})
+	require.NoError(t, err)
+
+	supervisor := NewSupervisor(m, WorkerOptions{
+		Sleep: 10 * time.Millisecond,
+		Queues: map[string]QueueOptions{
+			"high": {Priority: 5, Processes: 2},
+			"low":  {Priority: 1},
+		},
+	})
+	require.NoError(t, supervisor.Start())
+	defer supervisor.Stop()
+
+	// 各队列配置的工作进程数相加，每个工作进程都按优先级监听全部队列
+	assert.Equal(t, 3, supervisor.WorkerCount())
+	assert.Equal(t, map[string]int{"high": 3, "low": 3}, supervisor.Workers())
+
+	supervisor.mu.RLock()
+	defer supervisor.mu.RUnlock()
+	for _, worker := range supervisor.workers {
+		assert.Equal(t, []string{"high", "low"}, worker.queues)
+	}
+}
+
+func TestSupervisorRejectsTimeoutAboveRetryAfter(t *testing.T) {
+	m, err := NewManager(Config{Driver: "memory", Options: map[string]interface{}{
+		"queue":       "default",
+		"retry_after": 90 * time.Second,
+	}})
+	require.NoError(t, err)
+
+	// 120秒的队列超时在保留过期后仍在执行，会被重新取出
+	supervisor := NewSupervisor(m, WorkerOptions{
+		Queues: map[string]QueueOptions{"low": {Timeout: 120 * time.Second}},
+	})
+	assert.ErrorIs(t, supervisor.Start(), ErrTimeoutExceedsRetryAfter)
+	assert.Equal(t, 0, supervisor.WorkerCount())
+
+	// 任务显式设置的超时时间在推送时检查
+	job, err := NewBaseJob("default", nil, map[string]interface{}{"timeout": 90 * time.Second})
+	require.NoError(t, err)
+	assert.ErrorIs(t, m.Push(context.Background(), job), ErrTimeoutExceedsRetryAfter)
+}
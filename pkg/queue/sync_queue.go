@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"app/pkg/locker"
+)
+
+func init() {
+	Register("sync", func(config Config) (QueueInterface, error) {
+		return NewSyncQueue(config), nil
+	})
+}
+
+// processor 同步执行任务的处理函数
+type processor func(ctx context.Context, queue string, job JobInterface) error
+
+// processorAware 由同步驱动实现，推送的任务交由管理器立即执行
+type processorAware interface {
+	setProcessor(process processor)
+}
+
+// SyncQueue 同步队列驱动
+//
+// 推送时在当前协程中立即执行任务，任务的错误直接返回给调用方，不做重试和延迟。
+// 适用于测试和本地开发，无需启动工作进程。
+type SyncQueue struct {
+	config  Config
+	process processor
+	failed  *MemoryFailedJobStore
+	batches *MemoryBatchStore
+	lock    *locker.MemoryLocker
+	monitor *MemoryMonitorStore
+	// throttles 限流计数存储
+	throttles *MemoryThrottleStore
+}
+
+// NewSyncQueue 创建同步队列驱动
+func NewSyncQueue(config Config) *SyncQueue {
+	return &SyncQueue{
+		config:    config,
+		failed:    NewMemoryFailedJobStore(),
+		batches:   NewMemoryBatchStore(),
+		lock:      locker.NewMemoryLocker(),
+		monitor:   NewMemoryMonitorStore(),
+		throttles: NewMemoryThrottleStore(),
+	}
+}
+
+// setProcessor 设置执行任务的处理函数
+func (q *SyncQueue) setProcessor(process processor) {
+	q.process = process
+}
+
+// failedJobStore 返回内存失败任务存储
+func (q *SyncQueue) failedJobStore() FailedJobStore {
+	return q.failed
+}
+
+// batchStore 返回内存批次存储
+func (q *SyncQueue) batchStore() BatchStore {
+	return q.batches
+}
+
+// locker 返回内存锁
+func (q *SyncQueue) locker() locker.Locker {
+	return q.lock
+}
+
+// monitorStore 返回内存监控存储
+func (q *SyncQueue) monitorStore() MonitorStore {
+	return q.monitor
+}
+
+// throttleStore 返回内存限流存储
+func (q *SyncQueue) throttleStore() ThrottleStore {
+	return q.throttles
+}
+
+// Push 立即执行任务
+func (q *SyncQueue) Push(ctx context.Context, job JobInterface) error {
+	queue := job.GetQueue()
+	if queue == "" {
+		queue, _ = q.config.Options["queue"].(string)
+	}
+
+	if q.process == nil {
+		return Run(jobContext(ctx, job), job)
+	}
+	return q.process(ctx, queue, job)
+}
+
+// PushRaw 还原任务后立即执行
+func (q *SyncQueue) PushRaw(ctx context.Context, queue string, payload []byte, options map[string]interface{}) error {
+	job := decodeJob(payload)
+	if q.process == nil {
+		return Run(jobContext(ctx, job), job)
+	}
+	return q.process(ctx, queue, job)
+}
+
+// Later 忽略延迟，立即执行任务
+func (q *SyncQueue) Later(ctx context.Context, job JobInterface, delay time.Duration) error {
+	return q.Push(ctx, job)
+}
+
+// Pop 同步驱动没有待处理的任务
+func (q *SyncQueue) Pop(ctx context.Context, queue string) (JobInterface, error) {
+	return nil, ErrQueueEmpty
+}
+
+// Size 同步驱动没有待处理的任务
+func (q *SyncQueue) Size(ctx context.Context, queue string) (int64, error) {
+	return 0, nil
+}
+
+// Delete 任务执行后即结束，无需删除
+func (q *SyncQueue) Delete(ctx context.Context, queue string, job JobInterface) error {
+	return nil
+}
+
+// Release 同步驱动不做重试
+func (q *SyncQueue) Release(ctx context.Context, queue string, job JobInterface, delay time.Duration) error {
+	return nil
+}
+
+// Clear 同步驱动没有待处理的任务
+func (q *SyncQueue) Clear(ctx context.Context, queue string) error {
+	return nil
+}
+
+// Close 关闭队列连接
+func (q *SyncQueue) Close() error {
+	return nil
+}
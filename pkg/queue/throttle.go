@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"time"
+)
+
+// Throttle 限流配置，每个周期内最多开始执行 Limit 个任务
+//
+// 每个限流键对应限流存储中的一个原子计数器，周期内第一个任务开始执行时开始计时，
+// 周期结束后计数清零。被限流的任务延迟放回队列，不计入重试次数。
+type Throttle struct {
+	// Key 限流键，相同键的任务共享额度，任务限流未设置时使用任务类型名
+	Key string
+	// Limit 每个周期内允许开始执行的任务数
+	Limit int
+	// Per 限流周期
+	Per time.Duration
+	// ReleaseAfter 被限流的任务再次尝试前的等待时间，默认为一个周期
+	ReleaseAfter time.Duration
+}
+
+// enabled 是否启用限流
+func (t Throttle) enabled() bool {
+	return t.Limit > 0 && t.Per > 0
+}
+
+// releaseAfter 被限流的任务再次尝试前的等待时间
+func (t Throttle) releaseAfter() time.Duration {
+	if t.ReleaseAfter > 0 {
+		return t.ReleaseAfter
+	}
+	return t.Per
+}
+
+// ThrottledJob 需要限流的任务
+type ThrottledJob interface {
+	Throttle() Throttle
+}
+
+// ThrottleStore 限流计数存储，每次占用或归还额度只需一次原子操作
+type ThrottleStore interface {
+	// Acquire 占用一个额度，周期内已占用 limit 个时返回 false
+	Acquire(ctx context.Context, key string, limit int, per time.Duration) (bool, error)
+	// Return 归还一个已占用的额度，周期已结束时不做处理
+	Return(ctx context.Context, key string) error
+}
+
+// throttleStoreProvider 由驱动实现，提供与驱动共用连接的限流存储
+type throttleStoreProvider interface {
+	throttleStore() ThrottleStore
+}
+
+// SetThrottleStore 设置限流存储
+func (m *Manager) SetThrottleStore(store ThrottleStore) {
+	m.throttles = store
+}
+
+// ThrottleStore 获取限流存储
+func (m *Manager) ThrottleStore() ThrottleStore {
+	return m.throttles
+}
+
+// throttleKey 限流计数器的键
+func throttleKey(key string) string {
+	return "queues:throttle:" + key
+}
+
+// throttle 依次检查队列和任务的限流，被限流时返回再次尝试前的等待时间
+//
+// 限流存储不可用或出错时不限流，避免因限流故障阻塞队列。
+func (m *Manager) throttle(ctx context.Context, queue string, job JobInterface, queueThrottle Throttle) (time.Duration, bool) {
+	var jobThrottle Throttle
+	if tj, ok := job.(ThrottledJob); ok {
+		jobThrottle = tj.Throttle()
+		if jobThrottle.Key == "" {
+			name, ok := JobName(job)
+			if !ok {
+				name = reflect.TypeOf(job).String()
+			}
+			jobThrottle.Key = name
+		}
+	}
+	if !queueThrottle.enabled() && !jobThrottle.enabled() {
+		return 0, false
+	}
+	if m.throttles == nil {
+		log.Printf("[WARN] Job %s on queue %s is throttled but no throttle store is configured", job.GetID(), queue)
+		return 0, false
+	}
+
+	var queueKey string
+	if queueThrottle.enabled() {
+		key := queueThrottle.Key
+		if key == "" {
+			key = "queue:" + queue
+		}
+		acquired, err := m.throttles.Acquire(ctx, throttleKey(key), queueThrottle.Limit, queueThrottle.Per)
+		if err != nil {
+			log.Printf("[ERROR] Failed to check throttle of queue %s: %v", queue, err)
+			return 0, false
+		}
+		if !acquired {
+			return queueThrottle.releaseAfter(), true
+		}
+		queueKey = throttleKey(key)
+	}
+
+	if jobThrottle.enabled() {
+		acquired, err := m.throttles.Acquire(ctx, throttleKey(jobThrottle.Key), jobThrottle.Limit, jobThrottle.Per)
+		if err != nil {
+			log.Printf("[ERROR] Failed to check throttle of job %s: %v", job.GetID(), err)
+			return 0, false
+		}
+		if !acquired {
+			// 任务未执行，归还已占用的队列额度
+			if queueKey != "" {
+				if err := m.throttles.Return(ctx, queueKey); err != nil {
+					log.Printf("[ERROR] Failed to return throttle slot %s: %v", queueKey, err)
+				}
+			}
+			return jobThrottle.releaseAfter(), true
+		}
+	}
+
+	return 0, false
+}
+
+// releaseThrottled 将被限流的任务延迟放回队列，不计入重试次数
+func (m *Manager) releaseThrottled(ctx context.Context, queue string, job JobInterface, delay time.Duration) error {
+	// 驱动释放任务时会增加重试次数，此处预先抵消
+	job.SetAttempts(job.GetAttempts() - 1)
+	return m.Release(ctx, queue, job, delay)
+}
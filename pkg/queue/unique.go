@@ -0,0 +1,100 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"app/pkg/locker"
+)
+
+var (
+	// ErrDuplicateJob 相同的唯一任务正在等待或执行，本次推送被丢弃
+	ErrDuplicateJob = errors.New("duplicate unique job")
+	// ErrLockerUnavailable 当前驱动未配置锁
+	ErrLockerUnavailable = errors.New("queue locker unavailable")
+)
+
+// DefaultUniqueFor 唯一任务锁的默认有效期，避免进程崩溃后锁无法释放
+const DefaultUniqueFor = time.Hour
+
+// UniqueJob 唯一任务
+//
+// 推送时以任务类型和 UniqueID 加锁，任务完成或最终失败后释放。
+// 锁未释放前再次推送相同的任务会被丢弃并返回 ErrDuplicateJob。
+type UniqueJob interface {
+	UniqueID() string
+}
+
+// UniqueForJob 唯一任务可实现此接口自定义锁的有效期
+type UniqueForJob interface {
+	UniqueFor() time.Duration
+}
+
+// lockerProvider 由驱动实现，提供与驱动共用连接的锁
+type lockerProvider interface {
+	locker() locker.Locker
+}
+
+// SetLocker 设置唯一任务使用的锁
+func (m *Manager) SetLocker(l locker.Locker) {
+	m.lock = l
+}
+
+// Locker 获取唯一任务使用的锁
+func (m *Manager) Locker() locker.Locker {
+	return m.lock
+}
+
+// uniqueLockKey 唯一任务的锁名，不是唯一任务时返回空字符串
+func uniqueLockKey(job JobInterface) string {
+	unique, ok := job.(UniqueJob)
+	if !ok || unique.UniqueID() == "" {
+		return ""
+	}
+
+	name, ok := JobName(job)
+	if !ok {
+		name = reflect.TypeOf(job).String()
+	}
+	return fmt.Sprintf("queues:unique:%s:%s", name, unique.UniqueID())
+}
+
+// acquireUniqueLock 推送唯一任务前加锁
+func (m *Manager) acquireUniqueLock(ctx context.Context, job JobInterface) error {
+	key := uniqueLockKey(job)
+	if key == "" {
+		return nil
+	}
+	if m.lock == nil {
+		return ErrLockerUnavailable
+	}
+
+	ttl := DefaultUniqueFor
+	if u, ok := job.(UniqueForJob); ok && u.UniqueFor() > 0 {
+		ttl = u.UniqueFor()
+	}
+
+	acquired, err := m.lock.TryLock(ctx, key, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to acquire unique lock: %w", err)
+	}
+	if !acquired {
+		return ErrDuplicateJob
+	}
+	return nil
+}
+
+// releaseUniqueLock 唯一任务完成或最终失败后释放锁
+func (m *Manager) releaseUniqueLock(ctx context.Context, job JobInterface) {
+	key := uniqueLockKey(job)
+	if key == "" || m.lock == nil {
+		return
+	}
+	if err := m.lock.Unlock(ctx, key); err != nil {
+		log.Printf("[ERROR] Failed to release unique lock of job %s: %v", job.GetID(), err)
+	}
+}
@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"app/pkg/locker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type uniqueTestJob struct {
+	BaseJob
+	Key      string `json:"key"`
+	throttle Throttle
+	handled  int
+}
+
+func (j *uniqueTestJob) Handle() error {
+	j.handled++
+	return nil
+}
+
+func (j *uniqueTestJob) UniqueID() string {
+	return j.Key
+}
+
+func (j *uniqueTestJob) Throttle() Throttle {
+	return j.throttle
+}
+
+func TestUniqueJobDroppedWhilePending(t *testing.T) {
+	m, driver := newTestManager()
+	m.SetLocker(locker.NewMemoryLocker())
+	ctx := context.Background()
+
+	require.NoError(t, m.Push(ctx, &uniqueTestJob{Key: "user-1"}))
+	assert.True(t, errors.Is(m.Push(ctx, &uniqueTestJob{Key: "user-1"}), ErrDuplicateJob))
+	require.NoError(t, m.Push(ctx, &uniqueTestJob{Key: "user-2"}))
+	require.Len(t, driver.pushed, 2)
+
+	// 任务完成后释放锁，可以再次推送
+	require.NoError(t, m.Complete(ctx, "default", driver.pushed[0]))
+	require.NoError(t, m.Push(ctx, &uniqueTestJob{Key: "user-1"}))
+	assert.Len(t, driver.pushed, 3)
+}
+
+func TestUniqueJobRequiresLocker(t *testing.T) {
+	m, _ := newTestManager()
+	assert.True(t, errors.Is(m.Push(context.Background(), &uniqueTestJob{Key: "user-1"}), ErrLockerUnavailable))
+}
+
+func TestThrottleLimitsJobsPerPeriod(t *testing.T) {
+	m, _ := newTestManager()
+	m.SetThrottleStore(NewMemoryThrottleStore())
+	ctx := context.Background()
+
+	jobThrottle := Throttle{Limit: 2, Per: time.Minute}
+	for i := 0; i < 2; i++ {
+		_, throttled := m.throttle(ctx, "default", &uniqueTestJob{throttle: jobThrottle}, Throttle{})
+		assert.False(t, throttled)
+	}
+
+	delay, throttled := m.throttle(ctx, "default", &uniqueTestJob{throttle: jobThrottle}, Throttle{})
+	assert.True(t, throttled)
+	assert.Equal(t, time.Minute, delay)
+
+	// 其他队列不受影响，队列自身的限流同样生效
+	queueThrottle := Throttle{Limit: 1, Per: time.Minute, ReleaseAfter: time.Second}
+	_, throttled = m.throttle(ctx, "low", &BaseJob{}, queueThrottle)
+	assert.False(t, throttled)
+	delay, throttled = m.throttle(ctx, "low", &BaseJob{}, queueThrottle)
+	assert.True(t, throttled)
+	assert.Equal(t, time.Second, delay)
+}
+
+func TestWorkerReleasesThrottledJobWithoutAttempt(t *testing.T) {
+	driver := &recordingQueue{}
+	manager := &Manager{driver: driver, throttles: NewMemoryThrottleStore()}
+	worker := NewWorker(manager, []string{"default"}, WorkerOptions{Timeout: time.Minute})
+
+	jobThrottle := Throttle{Limit: 1, Per: time.Minute}
+	first := &uniqueTestJob{BaseJob: BaseJob{MaxAttempts: 3}, throttle: jobThrottle}
+	second := &uniqueTestJob{BaseJob: BaseJob{MaxAttempts: 3, Attempts: 1}, throttle: jobThrottle}
+
+	require.NoError(t, worker.processJob(worker.ctx, "default", first))
+	require.NoError(t, worker.processJob(worker.ctx, "default", second))
+
+	assert.Equal(t, 1, first.handled)
+	assert.Equal(t, 0, second.handled)
+	assert.Equal(t, 1, driver.deleted)
+	assert.Equal(t, 1, driver.released)
+	// 驱动释放时会加一，限流释放预先抵消
+	assert.Equal(t, 0, second.GetAttempts())
+}
+
+func TestThrottleReturnsQueueSlotWhenJobIsThrottled(t *testing.T) {
+	m, _ := newTestManager()
+	m.SetThrottleStore(NewMemoryThrottleStore())
+	ctx := context.Background()
+
+	queueThrottle := Throttle{Limit: 2, Per: time.Minute}
+	jobThrottle := Throttle{Key: "welcome", Limit: 1, Per: time.Minute}
+	_, throttled := m.throttle(ctx, "default", &uniqueTestJob{throttle: jobThrottle}, queueThrottle)
+	assert.False(t, throttled)
+
+	// 被任务限流拦下的任务不占用队列额度
+	_, throttled = m.throttle(ctx, "default", &uniqueTestJob{throttle: jobThrottle}, queueThrottle)
+	assert.True(t, throttled)
+	_, throttled = m.throttle(ctx, "default", &BaseJob{}, queueThrottle)
+	assert.False(t, throttled)
+	_, throttled = m.throttle(ctx, "default", &BaseJob{}, queueThrottle)
+	assert.True(t, throttled)
+}
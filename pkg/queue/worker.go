@@ -8,17 +8,36 @@ import (
 	"os/signal"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Worker 队列工作进程
 type Worker struct {
-	manager *Manager
-	queues  []string
-	options WorkerOptions
-	wg      sync.WaitGroup
-	stop    chan struct{}
+	manager  *Manager
+	queues   []string
+	options  WorkerOptions
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopOnce sync.Once
+	// ctx 在工作进程停止时取消，正在执行的任务随之收到取消信号
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// id 工作进程标识，用于上报心跳
+	id        string
+	startedAt time.Time
+	processed atomic.Int64
+	// stateMu 保护当前状态
+	stateMu    sync.Mutex
+	status     string
+	currentJob string
+	// paused 暂停队列缓存，仅在工作协程中访问
+	paused        map[string]bool
+	pausedChecked time.Time
 }
 
 // WorkerOptions 工作进程选项
@@ -37,42 +56,75 @@ type WorkerOptions struct {
 	Tries int
 	// Timeout 任务超时时间
 	Timeout time.Duration
+	// CancelGrace 任务被取消后等待其退出的时间
+	CancelGrace time.Duration
+	// Balance 监听多个队列时的轮询策略，默认按优先级
+	Balance string
+	// Queues 各队列的独立配置，未配置的项使用上面的默认值
+	Queues map[string]QueueOptions
 }
 
+const (
+	// BalancePriority 按优先级从高到低依次轮询
+	BalancePriority = "priority"
+	// BalanceWeight 按优先级作为权重随机决定轮询顺序
+	BalanceWeight = "weight"
+)
+
 // DefaultWorkerOptions 默认工作进程选项
 var DefaultWorkerOptions = WorkerOptions{
-	Sleep:   3 * time.Second,
-	MaxJobs: 0,
-	MaxTime: 0,
-	Rest:    0,
-	Memory:  128,
-	Tries:   3,
-	Timeout: 60 * time.Second,
+	Sleep:       3 * time.Second,
+	MaxJobs:     0,
+	MaxTime:     0,
+	Rest:        0,
+	Memory:      128,
+	Tries:       3,
+	Timeout:     60 * time.Second,
+	CancelGrace: 10 * time.Second,
+	Balance:     BalancePriority,
 }
 
 // NewWorker 创建队列工作进程
 func NewWorker(manager *Manager, queues []string, options WorkerOptions) *Worker {
-	if options.Sleep == 0 {
-		options.Sleep = DefaultWorkerOptions.Sleep
-	}
-	if options.Memory == 0 {
-		options.Memory = DefaultWorkerOptions.Memory
-	}
-	if options.Tries == 0 {
-		options.Tries = DefaultWorkerOptions.Tries
-	}
-	if options.Timeout == 0 {
-		options.Timeout = DefaultWorkerOptions.Timeout
-	}
+	options = options.withDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Worker{
 		manager: manager,
-		queues:  queues,
+		queues:  sortByPriority(queues, options.Queues),
 		options: options,
 		stop:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		id:      uuid.New().String(),
+		status:  WorkerStatusIdle,
 	}
 }
 
+// withDefaults 为未设置的选项填充默认值
+func (o WorkerOptions) withDefaults() WorkerOptions {
+	if o.Sleep == 0 {
+		o.Sleep = DefaultWorkerOptions.Sleep
+	}
+	if o.Memory == 0 {
+		o.Memory = DefaultWorkerOptions.Memory
+	}
+	if o.Tries == 0 {
+		o.Tries = DefaultWorkerOptions.Tries
+	}
+	if o.Timeout == 0 {
+		o.Timeout = DefaultWorkerOptions.Timeout
+	}
+	if o.CancelGrace == 0 {
+		o.CancelGrace = DefaultWorkerOptions.CancelGrace
+	}
+	if o.Balance == "" {
+		o.Balance = DefaultWorkerOptions.Balance
+	}
+	return o
+}
+
 // Start 启动工作进程
 func (w *Worker) Start() {
 	// 设置信号处理
@@ -83,17 +135,30 @@ func (w *Worker) Start() {
 	w.wg.Add(1)
 	go w.run()
 
-	// 等待信号
-	<-sigChan
-	log.Println("Received stop signal, shutting down...")
+	defer signal.Stop(sigChan)
+
+	// 等待信号或 Stop 调用
+	select {
+	case <-sigChan:
+		log.Println("Received stop signal, shutting down...")
+	case <-w.stop:
+	}
 
 	// 停止工作进程
-	close(w.stop)
+	w.shutdown()
 	w.wg.Wait()
 
 	log.Println("Worker stopped")
 }
 
+// Run 在当前协程中运行工作进程，直到调用 Stop 或达到运行限制
+//
+// 与 Start 不同，Run 不处理系统信号，由调用方负责停止。
+func (w *Worker) Run() {
+	w.wg.Add(1)
+	w.run()
+}
+
 // run 运行工作进程
 func (w *Worker) run() {
 	defer w.wg.Done()
@@ -101,6 +166,10 @@ func (w *Worker) run() {
 	startTime := time.Now()
 	var jobsProcessed int64
 
+	w.startedAt = startTime
+	stopHeartbeat := w.startHeartbeat()
+	defer stopHeartbeat()
+
 	for {
 		select {
 		case <-w.stop:
@@ -152,10 +221,15 @@ func (w *Worker) run() {
 
 // processNextJob 处理下一个任务
 func (w *Worker) processNextJob() (JobInterface, error) {
-	ctx := context.Background()
+	ctx := w.ctx
+
+	// 按轮询策略遍历所有队列
+	for _, queue := range w.pollOrder() {
+		// 跳过已暂停的队列
+		if w.isPaused(queue) {
+			continue
+		}
 
-	// 遍历所有队列
-	for _, queue := range w.queues {
 		// 获取任务
 		job, err := w.manager.Pop(ctx, queue)
 		if err == ErrQueueEmpty {
@@ -178,46 +252,111 @@ func (w *Worker) processNextJob() (JobInterface, error) {
 
 // processJob 处理任务
 func (w *Worker) processJob(ctx context.Context, queue string, job JobInterface) error {
-	// 创建带超时的上下文
-	ctx, cancel := context.WithTimeout(ctx, w.options.Timeout)
-	defer cancel()
+	// 任务状态的更新不受任务上下文取消的影响
+	opCtx := context.WithoutCancel(ctx)
 
-	// 创建错误通道
-	errChan := make(chan error, 1)
+	// 无法识别的任务不做重试，直接进入失败处理
+	if unknown, ok := job.(*UnknownJob); ok {
+		return w.failJob(opCtx, queue, job, unknown.Handle())
+	}
+
+	// 保留过期后重新入队的任务可能已用完重试次数
+	if maxAttempts := job.GetMaxAttempts(); maxAttempts > 0 && job.GetAttempts() >= maxAttempts {
+		return w.failJob(opCtx, queue, job, ErrMaxAttemptsExceeded)
+	}
+
+	options := w.QueueOptions(queue)
+
+	// 被限流的任务延迟放回队列，不计入重试次数
+	if delay, throttled := w.manager.throttle(opCtx, queue, job, options.Throttle); throttled {
+		return w.manager.releaseThrottled(opCtx, queue, job, delay)
+	}
+
+	w.setState(WorkerStatusBusy, job)
+	defer w.setState(WorkerStatusIdle, nil)
+	started := time.Now()
+
+	// 创建带超时的任务上下文，工作进程停止时同样会被取消
+	jobCtx, cancel := context.WithTimeout(jobContext(ctx, job), w.jobTimeout(job, options))
+	defer cancel()
 
 	// 在协程中执行任务
+	errChan := make(chan error, 1)
 	go func() {
-		errChan <- job.Handle()
+		errChan <- Run(jobCtx, job)
 	}()
 
-	// 等待任务完成或超时
+	// 等待任务完成、超时或工作进程停止
 	select {
 	case err := <-errChan:
-		if err != nil {
+		w.manager.recordJob(opCtx, queue, time.Since(started), err != nil)
+		if err == nil {
+			// 任务成功，推送任务链中的下一个任务并删除任务
+			return w.manager.Complete(opCtx, queue, job)
+		}
+		if jobCtx.Err() == nil {
 			// 任务失败，尝试重试
-			if job.GetAttempts() < w.options.Tries {
-				delay := w.calculateBackoff(job)
-				return w.manager.Release(ctx, queue, job, delay)
-			}
-			// 超过重试次数，删除任务
-			return w.manager.Delete(ctx, queue, job)
+			return w.retryOrFail(opCtx, queue, job, options, err)
 		}
-		// 任务成功，删除任务
-		return w.manager.Delete(ctx, queue, job)
-	case <-ctx.Done():
-		// 任务超时，尝试重试
-		if job.GetAttempts() < w.options.Tries {
-			delay := w.calculateBackoff(job)
-			return w.manager.Release(ctx, queue, job, delay)
+		// 任务因取消而返回，按超时或停止处理
+	case <-jobCtx.Done():
+		w.manager.recordJob(opCtx, queue, time.Since(started), true)
+		// 等待任务退出后再释放，避免与仍在运行的任务并发执行
+		select {
+		case <-errChan:
+		case <-time.After(w.options.CancelGrace):
+			// 任务未响应取消，不再重试以免重复执行
+			log.Printf("[WARN] Job %s on queue %s did not stop within %v after cancellation", job.GetID(), queue, w.options.CancelGrace)
+			return w.failJob(opCtx, queue, job, fmt.Errorf("%w: job did not stop after cancellation", ErrJobTimeout))
 		}
-		// 超过重试次数，删除任务
-		return w.manager.Delete(ctx, queue, job)
 	}
+
+	if ctx.Err() != nil {
+		// 工作进程停止，任务被中断
+		return w.retryOrFail(opCtx, queue, job, options, ErrWorkerStopped)
+	}
+	// 任务超时，尝试重试
+	return w.retryOrFail(opCtx, queue, job, options, ErrJobTimeout)
 }
 
-// calculateBackoff 计算退避时间
-func (w *Worker) calculateBackoff(job JobInterface) time.Duration {
+// retryOrFail 任务仍有重试机会时释放回队列，否则进入失败处理
+func (w *Worker) retryOrFail(ctx context.Context, queue string, job JobInterface, options QueueOptions, jobErr error) error {
+	if w.shouldRetry(job, options) {
+		delay := w.calculateBackoff(job, options)
+		return w.manager.Release(ctx, queue, job, delay)
+	}
+	// 超过重试次数，进入失败处理
+	return w.failJob(ctx, queue, job, jobErr)
+}
+
+// failJob 任务最终失败，转入失败任务存储并从队列中移除
+func (w *Worker) failJob(ctx context.Context, queue string, job JobInterface, jobErr error) error {
+	return w.manager.Fail(ctx, queue, job, jobErr)
+}
+
+// shouldRetry 判断任务是否还有重试机会，任务与队列的重试次数限制同时生效
+func (w *Worker) shouldRetry(job JobInterface, options QueueOptions) bool {
+	attempts := job.GetAttempts() + 1
+	if maxAttempts := job.GetMaxAttempts(); maxAttempts > 0 && attempts >= maxAttempts {
+		return false
+	}
+	return attempts < options.Tries
+}
+
+// jobTimeout 任务显式设置的超时时间优先于队列配置，未设置时使用队列的超时时间
+func (w *Worker) jobTimeout(job JobInterface, options QueueOptions) time.Duration {
+	if timeout := job.GetTimeout(); timeout > 0 {
+		return timeout
+	}
+	return options.Timeout
+}
+
+// calculateBackoff 计算退避时间，任务自身的退避策略优先于队列配置
+func (w *Worker) calculateBackoff(job JobInterface, options QueueOptions) time.Duration {
 	backoff := job.GetBackoff()
+	if len(backoff) == 0 {
+		backoff = options.Backoff
+	}
 	if len(backoff) > 0 {
 		attempt := job.GetAttempts()
 		if attempt < len(backoff) {
@@ -225,11 +364,116 @@ func (w *Worker) calculateBackoff(job JobInterface) time.Duration {
 		}
 		return backoff[len(backoff)-1]
 	}
-	return job.GetRetryAfter()
+	if retryAfter := job.GetRetryAfter(); retryAfter > 0 {
+		return retryAfter
+	}
+	return options.RetryAfter
 }
 
-// Stop 停止工作进程
+// Stop 停止工作进程，并取消正在执行的任务
 func (w *Worker) Stop() {
-	close(w.stop)
+	w.shutdown()
 	w.wg.Wait()
 }
+
+// shutdown 通知工作协程退出并取消任务上下文，可重复调用
+func (w *Worker) shutdown() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+		w.cancel()
+	})
+}
+
+// setState 更新工作进程当前状态，任务结束时增加处理计数
+func (w *Worker) setState(status string, job JobInterface) {
+	name := ""
+	if job != nil {
+		name, _ = JobName(job)
+	} else {
+		w.processed.Add(1)
+	}
+
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+	w.status = status
+	w.currentJob = name
+}
+
+// heartbeat 当前状态的心跳
+func (w *Worker) heartbeat() *WorkerHeartbeat {
+	w.stateMu.Lock()
+	defer w.stateMu.Unlock()
+
+	return &WorkerHeartbeat{
+		ID:        w.id,
+		Queues:    w.queues,
+		Hostname:  hostname(),
+		PID:       os.Getpid(),
+		Status:    w.status,
+		Job:       w.currentJob,
+		Processed: w.processed.Load(),
+		StartedAt: w.startedAt,
+		LastSeen:  time.Now(),
+	}
+}
+
+// startHeartbeat 定时上报心跳，返回的函数停止上报并移除心跳
+func (w *Worker) startHeartbeat() func() {
+	monitor := w.manager.monitor
+	if monitor == nil {
+		return func() {}
+	}
+
+	// 心跳不受工作进程停止的影响，以便退出时移除
+	ctx := context.WithoutCancel(w.ctx)
+	beat := func() {
+		if err := monitor.Heartbeat(ctx, w.heartbeat()); err != nil {
+			log.Printf("[ERROR] Failed to report heartbeat of worker %s: %v", w.id, err)
+		}
+	}
+
+	beat()
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		ticker := time.NewTicker(HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				beat()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+		if err := monitor.RemoveHeartbeat(ctx, w.id); err != nil {
+			log.Printf("[ERROR] Failed to remove heartbeat of worker %s: %v", w.id, err)
+		}
+	}
+}
+
+// isPaused 判断队列是否已暂停，暂停状态定期从监控存储刷新
+func (w *Worker) isPaused(queue string) bool {
+	if time.Since(w.pausedChecked) >= pausedRefreshInterval {
+		w.pausedChecked = time.Now()
+		queues, err := w.manager.PausedQueues(w.ctx)
+		if err != nil {
+			// 读取失败时沿用上次的状态
+			log.Printf("[ERROR] Failed to load paused queues: %v", err)
+		} else {
+			w.paused = make(map[string]bool, len(queues))
+			for _, name := range queues {
+				w.paused[name] = true
+			}
+		}
+	}
+	return w.paused[queue]
+}
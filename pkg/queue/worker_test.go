@@ -0,0 +1,148 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"app/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingQueue 记录 Worker 对任务的处理结果
+type recordingQueue struct {
+	QueueInterface
+	mu       sync.Mutex
+	deleted  int
+	released int
+}
+
+func (q *recordingQueue) Delete(ctx context.Context, queue string, job JobInterface) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.deleted++
+	return nil
+}
+
+func (q *recordingQueue) Release(ctx context.Context, queue string, job JobInterface, delay time.Duration) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.released++
+	return nil
+}
+
+type blockingJob struct {
+	BaseJob
+	started chan struct{}
+	stopped chan struct{}
+	traceID string
+}
+
+func (j *blockingJob) HandleContext(ctx context.Context) error {
+	j.traceID = logger.TraceID(ctx)
+	close(j.started)
+	<-ctx.Done()
+	close(j.stopped)
+	return ctx.Err()
+}
+
+func newBlockingJob() *blockingJob {
+	return &blockingJob{
+		BaseJob: BaseJob{Queue: "default", MaxAttempts: 3, TraceID: "trace-1"},
+		started: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func TestWorkerCancelsJobOnTimeout(t *testing.T) {
+	driver := &recordingQueue{}
+	worker := NewWorker(&Manager{driver: driver}, []string{"default"}, WorkerOptions{Timeout: 50 * time.Millisecond})
+	job := newBlockingJob()
+
+	require.NoError(t, worker.processJob(worker.ctx, "default", job))
+
+	// 任务在释放前必须已经退出
+	select {
+	case <-job.stopped:
+	default:
+		t.Fatal("job still running after timeout")
+	}
+	assert.Equal(t, 1, driver.released)
+	assert.Equal(t, 0, driver.deleted)
+	assert.Equal(t, "trace-1", job.traceID)
+}
+
+func TestWorkerCancelsJobOnStop(t *testing.T) {
+	driver := &recordingQueue{}
+	worker := NewWorker(&Manager{driver: driver}, []string{"default"}, WorkerOptions{Timeout: time.Minute})
+	job := newBlockingJob()
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- worker.processJob(worker.ctx, "default", job)
+	}()
+
+	<-job.started
+	worker.shutdown()
+
+	select {
+	case err := <-errChan:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("job was not cancelled on shutdown")
+	}
+	assert.Equal(t, 1, driver.released)
+}
+
+func TestRunLegacyJob(t *testing.T) {
+	job := &UnknownJob{Name: "legacy"}
+	assert.True(t, errors.Is(Run(context.Background(), job), ErrUnknownJob))
+}
+
+func TestWorkerJobTimeoutPrecedence(t *testing.T) {
+	worker := NewWorker(nil, []string{"low"}, WorkerOptions{
+		Queues: map[string]QueueOptions{"low": {Timeout: 2 * time.Minute}},
+	})
+	options := worker.QueueOptions("low")
+
+	// 未显式设置超时时间的任务使用队列配置
+	job, err := NewBaseJob("low", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, worker.jobTimeout(job, options))
+
+	job, err = NewBaseJob("low", nil, map[string]interface{}{"timeout": 10 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, worker.jobTimeout(job, options))
+}
+
+func TestReleaseFailsExhaustedJob(t *testing.T) {
+	m, err := NewManager(Config{Driver: "memory", Options: map[string]interface{}{"queue": "default"}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, m.Push(ctx, &registryTestJob{BaseJob: BaseJob{MaxAttempts: 2}}))
+	job, err := m.Pop(ctx, "default")
+	require.NoError(t, err)
+	require.NoError(t, m.Release(ctx, "default", job, time.Nanosecond))
+
+	time.Sleep(time.Millisecond)
+	job, err = m.Pop(ctx, "default")
+	require.NoError(t, err)
+	require.NoError(t, m.Release(ctx, "default", job, time.Nanosecond))
+
+	// 用完重试次数的任务进入失败任务存储，不再留在队列中
+	size, err := m.Size(ctx, "default")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+	failed, err := m.FailedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Contains(t, failed[0].Exception, ErrMaxAttemptsExceeded.Error())
+}